@@ -15,13 +15,20 @@ import (
 	"gorm.io/gorm/logger"
 
 	"github.com/Helltale/take-your-pills-on-time/internal/config"
+	"github.com/Helltale/take-your-pills-on-time/internal/dispatcher"
 	"github.com/Helltale/take-your-pills-on-time/internal/handlers"
+	"github.com/Helltale/take-your-pills-on-time/internal/lifecycle"
 	"github.com/Helltale/take-your-pills-on-time/internal/migrations"
 	"github.com/Helltale/take-your-pills-on-time/internal/repository"
 	"github.com/Helltale/take-your-pills-on-time/internal/scheduler"
 	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+	"github.com/Helltale/take-your-pills-on-time/internal/webhook"
 )
 
+// shutdownTimeout bounds how long stopping may take before the work still
+// in progress is cancelled.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -43,7 +50,6 @@ func main() {
 	if err != nil {
 		appLogger.Fatal("Failed to get sql.DB", zap.Error(err))
 	}
-	defer sqlDB.Close()
 
 	migrator := migrations.NewMigrator(db, appLogger)
 	if err := migrator.Run(); err != nil {
@@ -63,38 +69,57 @@ func main() {
 
 	handler := handlers.NewBotHandler(bot, usecases, appLogger)
 
-	sched := scheduler.NewScheduler(repo.Reminder, usecases.ReminderExecution, usecases.Reminder, handler, appLogger)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	sched := scheduler.NewScheduler(repo.Reminder, usecases.ReminderExecution, usecases.Reminder, usecases.Caregiver, usecases.Stock, usecases.Digest, usecases.Delivery, handler, appLogger)
 
-	sched.Start(ctx)
-	defer sched.Stop()
+	disp := dispatcher.NewDispatcher(handler.HandleUpdate, cfg.Updates, appLogger)
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := bot.GetUpdatesChan(u)
+	var source updateSource = &pollingSource{bot: bot, logger: appLogger}
+	if cfg.Webhook.Enabled {
+		source = &webhookSource{server: webhook.NewServer(bot, cfg.Webhook, appLogger)}
+	}
+	consumer := newUpdateConsumer(source, disp, appLogger)
+
+	// Hooks stop in reverse order: first no new updates are taken, then the
+	// queued ones are handled, the scheduler finishes its sends and only
+	// then the database is closed.
+	app := lifecycle.NewLifecycle(shutdownTimeout, appLogger)
+	app.Append(lifecycle.Hook{
+		Name:   "database",
+		OnStop: func(context.Context) error { return sqlDB.Close() },
+	})
+	app.Append(lifecycle.Hook{
+		Name: "scheduler",
+		OnStart: func(ctx context.Context) error {
+			sched.Start(ctx)
+			return nil
+		},
+		OnStop: sched.Stop,
+	})
+	app.Append(lifecycle.Hook{
+		Name: "dispatcher",
+		OnStart: func(ctx context.Context) error {
+			disp.Start(ctx)
+			return nil
+		},
+		OnStop: disp.Shutdown,
+	})
+	app.Append(lifecycle.Hook{
+		Name:    "updates",
+		OnStart: consumer.Start,
+		OnStop:  consumer.Stop,
+	})
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	appLogger.Info("Bot is running. Press Ctrl+C to stop.")
 
-	for {
-		select {
-		case update := <-updates:
-			if update.CallbackQuery != nil {
-				handler.HandleUpdate(ctx, update)
-			} else if update.Message != nil {
-				handler.HandleUpdate(ctx, update)
-			}
-		case <-sigChan:
-			appLogger.Info("Shutting down...")
-			cancel()
-			time.Sleep(2 * time.Second)
-			return
-		}
+	if err := app.Run(ctx); err != nil {
+		appLogger.Error("Application stopped with errors", zap.Error(err))
+		appLogger.Sync()
+		os.Exit(1)
 	}
+	appLogger.Info("Application stopped")
 }
 
 func initLogger(level string) *zap.Logger {
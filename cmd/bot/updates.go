@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/dispatcher"
+	"github.com/Helltale/take-your-pills-on-time/internal/webhook"
+)
+
+// updateSource is where updates come from: long polling or the webhook.
+type updateSource interface {
+	Start() (tgbotapi.UpdatesChannel, error)
+	Stop(ctx context.Context) error
+}
+
+type pollingSource struct {
+	bot    *tgbotapi.BotAPI
+	logger *zap.Logger
+}
+
+func (s *pollingSource) Start() (tgbotapi.UpdatesChannel, error) {
+	// getUpdates is refused while a webhook from an earlier run is set.
+	if _, err := s.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		s.logger.Error("Failed to delete webhook", zap.Error(err))
+	}
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	return s.bot.GetUpdatesChan(u), nil
+}
+
+// Stop does not wait for the long poll in progress: updates it returns are
+// not confirmed and Telegram sends them again after a restart.
+func (s *pollingSource) Stop(ctx context.Context) error {
+	s.bot.StopReceivingUpdates()
+	return nil
+}
+
+type webhookSource struct {
+	server *webhook.Server
+}
+
+func (s *webhookSource) Start() (tgbotapi.UpdatesChannel, error) {
+	if err := s.server.Start(); err != nil {
+		return nil, err
+	}
+	return s.server.Updates(), nil
+}
+
+func (s *webhookSource) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// updateConsumer moves updates from the source to the dispatcher.
+type updateConsumer struct {
+	source updateSource
+	disp   *dispatcher.Dispatcher
+	logger *zap.Logger
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func newUpdateConsumer(source updateSource, disp *dispatcher.Dispatcher, logger *zap.Logger) *updateConsumer {
+	return &updateConsumer{
+		source: source,
+		disp:   disp,
+		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (c *updateConsumer) Start(ctx context.Context) error {
+	updates, err := c.source.Start()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer close(c.done)
+		for {
+			select {
+			case update, ok := <-updates:
+				if !ok {
+					// Polling closes the channel once stopped.
+					updates = nil
+					continue
+				}
+				c.dispatch(ctx, update)
+			case <-c.stop:
+				c.drain(ctx, updates)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops receiving updates and hands the ones already received to the
+// dispatcher.
+func (c *updateConsumer) Stop(ctx context.Context) error {
+	err := c.source.Stop(ctx)
+	close(c.stop)
+
+	select {
+	case <-c.done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for update consumer: %w", ctx.Err())
+	}
+}
+
+func (c *updateConsumer) drain(ctx context.Context, updates tgbotapi.UpdatesChannel) {
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			c.dispatch(ctx, update)
+		default:
+			return
+		}
+	}
+}
+
+func (c *updateConsumer) dispatch(ctx context.Context, update tgbotapi.Update) {
+	if update.CallbackQuery == nil && update.Message == nil {
+		return
+	}
+	if err := c.disp.Dispatch(ctx, update); err != nil {
+		c.logger.Error("Failed to dispatch update", zap.Error(err), zap.Int("update_id", update.UpdateID))
+	}
+}
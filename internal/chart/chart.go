@@ -0,0 +1,240 @@
+// Package chart draws adherence charts as PNG images with the standard
+// library only.
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+// Day is how many of the day's doses were taken. Open doses are still
+// waiting for an answer and are not counted as taken or missed yet.
+type Day struct {
+	Date  time.Time
+	Taken int
+	Open  int
+	Total int
+}
+
+// Week sums the days of a week starting on Monday.
+type Week struct {
+	Start time.Time
+	Taken int
+	Open  int
+	Total int
+}
+
+// Rate returns the share of the week's answered doses that were taken, from
+// 0 to 1.
+func (w Week) Rate() float64 {
+	answered := w.Total - w.Open
+	if answered <= 0 {
+		return 0
+	}
+	return float64(w.Taken) / float64(answered)
+}
+
+const (
+	cellSize     = 36
+	cellGap      = 4
+	padding      = 16
+	barWidth     = 240
+	barMargin    = 16
+	labelGap     = 8
+	labelScale   = 2
+	legendMargin = 16
+	legendGap    = 16
+)
+
+var (
+	backgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	emptyColor      = color.RGBA{0xeb, 0xed, 0xf0, 0xff}
+	takenColor      = color.RGBA{0x2d, 0xa4, 0x4e, 0xff}
+	partialColor    = color.RGBA{0xe3, 0xb3, 0x41, 0xff}
+	missedColor     = color.RGBA{0xcf, 0x22, 0x2e, 0xff}
+	openColor       = color.RGBA{0x8a, 0xb4, 0xf8, 0xff}
+	goalColor       = color.RGBA{0x8c, 0x95, 0x9f, 0xff}
+	textColor       = color.RGBA{0x24, 0x29, 0x2f, 0xff}
+)
+
+// goalRate is the weekly adherence marked on the bars.
+const goalRate = 0.8
+
+var weekdayLabels = [7]string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"}
+
+var legend = []struct {
+	color color.RGBA
+	label string
+}{
+	{takenColor, "100%"},
+	{partialColor, "1-99%"},
+	{missedColor, "0%"},
+	{emptyColor, "нет"},
+	{openColor, "идёт"},
+}
+
+// Weeks groups consecutive days into weeks starting on Monday.
+func Weeks(days []Day) []Week {
+	var weeks []Week
+	for _, day := range days {
+		start := weekStart(day.Date)
+		if len(weeks) == 0 || !weeks[len(weeks)-1].Start.Equal(start) {
+			weeks = append(weeks, Week{Start: start})
+		}
+		weeks[len(weeks)-1].Taken += day.Taken
+		weeks[len(weeks)-1].Open += day.Open
+		weeks[len(weeks)-1].Total += day.Total
+	}
+	return weeks
+}
+
+// layout places the parts of a chart of the given number of weeks.
+type layout struct {
+	heatmap image.Point // top left corner of the first cell
+	barX    int
+	legendY int
+	width   int
+	height  int
+}
+
+func newLayout(weeks int) layout {
+	labelHeight := glyphHeight * labelScale
+	heatmapWidth := 7*(cellSize+cellGap) - cellGap
+
+	var l layout
+	l.heatmap = image.Pt(
+		padding+textWidth("00.00", labelScale)+labelGap,
+		padding+labelHeight+labelGap,
+	)
+	l.barX = l.heatmap.X + heatmapWidth + barMargin
+	l.width = l.barX + barWidth + labelGap + textWidth("100%", labelScale) + padding
+	l.legendY = l.heatmap.Y + max(weeks*(cellSize+cellGap)-cellGap, 0) + legendMargin
+	l.height = l.legendY + labelHeight + padding
+	return l
+}
+
+// cell returns the top left corner of the day's cell.
+func (l layout) cell(row, column int) image.Point {
+	return l.heatmap.Add(image.Pt(column*(cellSize+cellGap), row*(cellSize+cellGap)))
+}
+
+// Draw lays the days out as a calendar heatmap, one row per week from
+// Monday to Sunday labelled with the date the week starts on, with the
+// week's adherence as a bar to the right of its row and a legend below. A
+// day is green when every answered dose was taken, yellow when some were,
+// red when none were, grey when it had no doses and blue when its doses are
+// still waiting for an answer.
+func Draw(days []Day) *image.RGBA {
+	weeks := Weeks(days)
+	l := newLayout(len(weeks))
+
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	fill(img, img.Bounds(), backgroundColor)
+
+	for column, label := range weekdayLabels {
+		x := l.cell(0, column).X + (cellSize-textWidth(label, labelScale))/2
+		drawText(img, x, padding, label, labelScale, textColor)
+	}
+
+	for _, day := range days {
+		row := weekIndex(weeks, weekStart(day.Date))
+		column := (int(day.Date.Weekday()) + 6) % 7
+		at := l.cell(row, column)
+		c := dayColor(day)
+		fill(img, image.Rect(at.X, at.Y, at.X+cellSize, at.Y+cellSize), c)
+
+		numberColor := backgroundColor
+		if c == emptyColor {
+			numberColor = textColor
+		}
+		drawText(img, at.X+3, at.Y+3, fmt.Sprint(day.Date.Day()), 1, numberColor)
+	}
+
+	goalX := l.barX + int(goalRate*barWidth)
+	labelOffset := (cellSize - glyphHeight*labelScale) / 2
+	for row, week := range weeks {
+		y := l.cell(row, 0).Y
+		drawText(img, padding, y+labelOffset, week.Start.Format("02.01"), labelScale, textColor)
+
+		fill(img, image.Rect(l.barX, y, l.barX+barWidth, y+cellSize), emptyColor)
+		if week.Total > week.Open {
+			filled := int(week.Rate()*barWidth + 0.5)
+			fill(img, image.Rect(l.barX, y, l.barX+filled, y+cellSize), rateColor(week.Rate()))
+			label := fmt.Sprintf("%d%%", int(week.Rate()*100+0.5))
+			drawText(img, l.barX+barWidth+labelGap, y+labelOffset, label, labelScale, textColor)
+		}
+		fill(img, image.Rect(goalX-1, y, goalX+1, y+cellSize), goalColor)
+	}
+
+	x := padding
+	size := glyphHeight * labelScale
+	for _, entry := range legend {
+		fill(img, image.Rect(x, l.legendY, x+size, l.legendY+size), entry.color)
+		x += size + labelGap/2
+		drawText(img, x, l.legendY, entry.label, labelScale, textColor)
+		x += textWidth(entry.label, labelScale) + legendGap
+	}
+
+	return img
+}
+
+// Render draws the days as Draw does and encodes the chart as PNG.
+func Render(days []Day) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Draw(days)); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// dayColor colours the day by its answered doses; a day whose doses are all
+// still open is in progress.
+func dayColor(day Day) color.RGBA {
+	answered := day.Total - day.Open
+	switch {
+	case day.Total == 0:
+		return emptyColor
+	case answered <= 0:
+		return openColor
+	case day.Taken >= answered:
+		return takenColor
+	case day.Taken == 0:
+		return missedColor
+	default:
+		return partialColor
+	}
+}
+
+func rateColor(rate float64) color.RGBA {
+	switch {
+	case rate >= goalRate:
+		return takenColor
+	case rate >= goalRate/2:
+		return partialColor
+	default:
+		return missedColor
+	}
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+func weekIndex(weeks []Week, start time.Time) int {
+	for i, week := range weeks {
+		if week.Start.Equal(start) {
+			return i
+		}
+	}
+	return 0
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	draw.Draw(img, rect, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
@@ -0,0 +1,117 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeeks(t *testing.T) {
+	// 2026-05-01 is a Friday.
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	var days []Day
+	for i := 0; i < 10; i++ {
+		days = append(days, Day{Date: start.AddDate(0, 0, i), Taken: i % 2, Total: 1})
+	}
+
+	weeks := Weeks(days)
+
+	require.Len(t, weeks, 2)
+	assert.Equal(t, time.Date(2026, 4, 27, 0, 0, 0, 0, time.UTC), weeks[0].Start)
+	assert.Equal(t, 3, weeks[0].Total)
+	assert.Equal(t, 1, weeks[0].Taken)
+	assert.Equal(t, time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), weeks[1].Start)
+	assert.Equal(t, 7, weeks[1].Total)
+	assert.InDelta(t, 4.0/7, weeks[1].Rate(), 0.001)
+	assert.Zero(t, Week{}.Rate())
+}
+
+func TestDraw(t *testing.T) {
+	monday := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	days := []Day{
+		{Date: monday, Taken: 2, Total: 2},
+		{Date: monday.AddDate(0, 0, 1), Taken: 1, Total: 2},
+		{Date: monday.AddDate(0, 0, 2), Taken: 0, Total: 2},
+		{Date: monday.AddDate(0, 0, 3)},
+		{Date: monday.AddDate(0, 0, 7), Taken: 1, Total: 1},
+	}
+
+	img := Draw(days)
+	l := newLayout(2)
+
+	assert.Equal(t, l.width, img.Bounds().Dx())
+	assert.Equal(t, l.height, img.Bounds().Dy())
+
+	cell := func(row, column int) any {
+		at := l.cell(row, column)
+		return img.RGBAAt(at.X+cellSize/2, at.Y+cellSize/2)
+	}
+	assert.Equal(t, takenColor, cell(0, 0))
+	assert.Equal(t, partialColor, cell(0, 1))
+	assert.Equal(t, missedColor, cell(0, 2))
+	assert.Equal(t, emptyColor, cell(0, 3))
+	assert.Equal(t, backgroundColor, cell(0, 4))
+	assert.Equal(t, takenColor, cell(1, 0))
+
+	barY := l.cell(0, 0).Y + cellSize/2
+	// The first week took 3 of 6 doses: half of the bar is filled.
+	assert.Equal(t, partialColor, img.RGBAAt(l.barX+barWidth/4, barY))
+	assert.Equal(t, emptyColor, img.RGBAAt(l.barX+barWidth*3/4, barY))
+
+	// Weekday and week labels and the legend are drawn in the margins.
+	assert.True(t, hasColor(img, image.Rect(l.heatmap.X, padding, l.barX, l.heatmap.Y), textColor))
+	assert.True(t, hasColor(img, image.Rect(padding, l.heatmap.Y, l.heatmap.X, l.legendY), textColor))
+	assert.True(t, hasColor(img, image.Rect(padding, l.legendY, l.width, l.height), openColor))
+}
+
+func TestDraw_OpenDoses(t *testing.T) {
+	monday := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	days := []Day{
+		{Date: monday, Taken: 1, Total: 1},
+		{Date: monday.AddDate(0, 0, 1), Taken: 1, Open: 1, Total: 2},
+		{Date: monday.AddDate(0, 0, 2), Open: 2, Total: 2},
+	}
+
+	img := Draw(days)
+	l := newLayout(1)
+
+	cell := func(column int) any {
+		at := l.cell(0, column)
+		return img.RGBAAt(at.X+cellSize/2, at.Y+cellSize/2)
+	}
+	// Doses still waiting for an answer are neither taken nor missed.
+	assert.Equal(t, takenColor, cell(0))
+	assert.Equal(t, takenColor, cell(1))
+	assert.Equal(t, openColor, cell(2))
+
+	weeks := Weeks(days)
+	require.Len(t, weeks, 1)
+	assert.Equal(t, 3, weeks[0].Open)
+	assert.InDelta(t, 1.0, weeks[0].Rate(), 0.001)
+}
+
+func hasColor(img *image.RGBA, r image.Rectangle, c color.RGBA) bool {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if img.RGBAAt(x, y) == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestRender(t *testing.T) {
+	data, err := Render([]Day{{Date: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), Taken: 1, Total: 1}})
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, Draw(nil).Bounds().Dx(), img.Bounds().Dx())
+}
@@ -0,0 +1,71 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+)
+
+// glyphs is a 5×7 bitmap font with just the characters the chart labels
+// use: digits, dates, percentages and short Russian weekday and legend
+// words. Each row is five bits, the highest one leftmost.
+var glyphs = map[rune][7]uint8{
+	' ': {},
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'.': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	'%': {0b11000, 0b11001, 0b00010, 0b00100, 0b01000, 0b10011, 0b00011},
+	'-': {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'В': {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'П': {0b11111, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001},
+	'С': {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'Ч': {0b10001, 0b10001, 0b10001, 0b01111, 0b00001, 0b00001, 0b00001},
+	'б': {0b01111, 0b10000, 0b11110, 0b10001, 0b10001, 0b10001, 0b01110},
+	'д': {0b00000, 0b00000, 0b00110, 0b01010, 0b01010, 0b11111, 0b10001},
+	'е': {0b00000, 0b00000, 0b01110, 0b10001, 0b11111, 0b10000, 0b01110},
+	'ё': {0b01010, 0b00000, 0b01110, 0b10001, 0b11111, 0b10000, 0b01110},
+	'и': {0b00000, 0b00000, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001},
+	'н': {0b00000, 0b00000, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001},
+	'р': {0b00000, 0b00000, 0b11110, 0b10001, 0b11110, 0b10000, 0b10000},
+	'с': {0b00000, 0b00000, 0b01110, 0b10000, 0b10000, 0b10001, 0b01110},
+	'т': {0b00000, 0b00000, 0b11111, 0b00100, 0b00100, 0b00100, 0b00100},
+}
+
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// textWidth returns how many pixels wide text is at the given scale.
+func textWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+1) - 1) * scale
+}
+
+// drawText draws text with its top left corner at x, y, every font pixel
+// scale pixels wide. Characters missing from the font are left blank.
+func drawText(img *image.RGBA, x, y int, text string, scale int, c color.RGBA) {
+	for _, r := range text {
+		glyph := glyphs[r]
+		for row, bits := range glyph {
+			for column := 0; column < glyphWidth; column++ {
+				if bits&(1<<(glyphWidth-1-column)) == 0 {
+					continue
+				}
+				px, py := x+column*scale, y+row*scale
+				fill(img, image.Rect(px, py, px+scale, py+scale), c)
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 
 	"github.com/joho/godotenv"
@@ -10,10 +12,39 @@ import (
 
 type Config struct {
 	TelegramBotToken string
+	Webhook          WebhookConfig
+	Updates          UpdatesConfig
 	Database         DatabaseConfig
 	App              AppConfig
 }
 
+// WebhookConfig switches the bot from long polling to a webhook: Telegram
+// posts updates to URL, which the reverse proxy forwards to ListenAddr.
+// Requests must carry SecretToken in the X-Telegram-Bot-Api-Secret-Token
+// header.
+type WebhookConfig struct {
+	Enabled     bool
+	URL         string
+	ListenAddr  string
+	SecretToken string
+}
+
+// Path returns the path of URL the webhook is served on.
+func (c *WebhookConfig) Path() string {
+	parsed, err := url.Parse(c.URL)
+	if err != nil || parsed.Path == "" {
+		return "/"
+	}
+	return parsed.Path
+}
+
+// UpdatesConfig sizes the worker pool that handles incoming updates: each
+// worker owns a queue of QueueSize updates.
+type UpdatesConfig struct {
+	Workers   int
+	QueueSize int
+}
+
 type DatabaseConfig struct {
 	Host     string
 	Port     int
@@ -43,6 +74,15 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
+		Webhook: WebhookConfig{
+			URL:         getEnv("WEBHOOK_URL", ""),
+			ListenAddr:  getEnv("WEBHOOK_LISTEN_ADDR", ":8080"),
+			SecretToken: getEnv("WEBHOOK_SECRET_TOKEN", ""),
+		},
+		Updates: UpdatesConfig{
+			Workers:   getEnvAsInt("UPDATE_WORKERS", 8),
+			QueueSize: getEnvAsInt("UPDATE_QUEUE_SIZE", 100),
+		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnvAsInt("DB_PORT", 5432),
@@ -61,9 +101,35 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
 	}
 
+	switch mode := getEnv("BOT_MODE", "polling"); mode {
+	case "polling":
+	case "webhook":
+		cfg.Webhook.Enabled = true
+		if err := cfg.Webhook.validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("BOT_MODE must be polling or webhook, got %q", mode)
+	}
+
 	return cfg, nil
 }
 
+// secretTokenPattern is the alphabet and length Telegram allows for a
+// webhook secret token.
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+func (c *WebhookConfig) validate() error {
+	parsed, err := url.Parse(c.URL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("WEBHOOK_URL must be an https URL in webhook mode")
+	}
+	if !secretTokenPattern.MatchString(c.SecretToken) {
+		return fmt.Errorf("WEBHOOK_SECRET_TOKEN must be 1-256 characters A-Z, a-z, 0-9, _ or - in webhook mode")
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,246 @@
+// Package dispatcher processes Telegram updates on a pool of workers. Updates
+// of one chat always go to the same worker, so they are handled one at a
+// time and in the order they arrived, while different chats proceed in
+// parallel.
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/config"
+)
+
+// ErrClosed is returned by Dispatch once Shutdown has been called.
+var ErrClosed = errors.New("dispatcher is closed")
+
+// statsInterval is how often queue statistics are logged.
+const statsInterval = time.Minute
+
+type Handler func(ctx context.Context, update tgbotapi.Update)
+
+// Stats are counters since the dispatcher was created plus the current
+// queue depth. Waits counts updates that found their queue full and had to
+// wait for room, WaitTime is the total time spent waiting.
+type Stats struct {
+	Dispatched uint64
+	Processed  uint64
+	Rejected   uint64
+	Panics     uint64
+	Waits      uint64
+	WaitTime   time.Duration
+	Queued     int
+	MaxQueued  int
+}
+
+type Dispatcher struct {
+	handler Handler
+	logger  *zap.Logger
+	queues  []chan tgbotapi.Update
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+	stop   chan struct{}
+
+	dispatched atomic.Uint64
+	processed  atomic.Uint64
+	rejected   atomic.Uint64
+	panics     atomic.Uint64
+	waits      atomic.Uint64
+	waitTime   atomic.Int64
+}
+
+func NewDispatcher(handler Handler, cfg config.UpdatesConfig, logger *zap.Logger) *Dispatcher {
+	workers := max(cfg.Workers, 1)
+	queueSize := max(cfg.QueueSize, 1)
+
+	queues := make([]chan tgbotapi.Update, workers)
+	for i := range queues {
+		queues[i] = make(chan tgbotapi.Update, queueSize)
+	}
+
+	return &Dispatcher{
+		handler: handler,
+		logger:  logger,
+		queues:  queues,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start runs the workers. ctx is passed to the handler and should stay alive
+// until Shutdown returns, otherwise queued updates are handled with a
+// cancelled context.
+func (d *Dispatcher) Start(ctx context.Context) {
+	for _, queue := range d.queues {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for update := range queue {
+				d.process(ctx, update)
+			}
+		}()
+	}
+
+	go d.reportStats()
+
+	d.logger.Info("Update dispatcher started",
+		zap.Int("workers", len(d.queues)),
+		zap.Int("queue_size", cap(d.queues[0])),
+	)
+}
+
+// Dispatch queues an update for its chat's worker. When that queue is full
+// it blocks until there is room, which slows down reading updates instead of
+// growing memory without bound.
+func (d *Dispatcher) Dispatch(ctx context.Context, update tgbotapi.Update) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		d.rejected.Add(1)
+		return ErrClosed
+	}
+
+	queue := d.queues[d.shard(update)]
+	select {
+	case queue <- update:
+		d.dispatched.Add(1)
+		return nil
+	default:
+	}
+
+	d.waits.Add(1)
+	started := time.Now()
+	defer func() { d.waitTime.Add(int64(time.Since(started))) }()
+
+	select {
+	case queue <- update:
+		d.dispatched.Add(1)
+		return nil
+	case <-ctx.Done():
+		d.rejected.Add(1)
+		return fmt.Errorf("failed to queue update %d: %w", update.UpdateID, ctx.Err())
+	}
+}
+
+// Shutdown stops accepting updates and waits until the workers have handled
+// everything already queued, or until ctx is done.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	close(d.stop)
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		d.logger.Info("Update dispatcher stopped", statsFields(d.Stats())...)
+		return nil
+	case <-ctx.Done():
+		stats := d.Stats()
+		return fmt.Errorf("failed to drain update queues, %d updates left: %w", stats.Queued, ctx.Err())
+	}
+}
+
+func (d *Dispatcher) Stats() Stats {
+	stats := Stats{
+		Dispatched: d.dispatched.Load(),
+		Processed:  d.processed.Load(),
+		Rejected:   d.rejected.Load(),
+		Panics:     d.panics.Load(),
+		Waits:      d.waits.Load(),
+		WaitTime:   time.Duration(d.waitTime.Load()),
+	}
+	for _, queue := range d.queues {
+		stats.Queued += len(queue)
+		stats.MaxQueued = max(stats.MaxQueued, len(queue))
+	}
+	return stats
+}
+
+// shard picks the worker for an update by its chat, falling back to the
+// sender for updates without one.
+func (d *Dispatcher) shard(update tgbotapi.Update) int {
+	var key int64
+	if chat := update.FromChat(); chat != nil {
+		key = chat.ID
+	} else if user := update.SentFrom(); user != nil {
+		key = user.ID
+	} else {
+		key = int64(update.UpdateID)
+	}
+	return int(uint64(key) % uint64(len(d.queues)))
+}
+
+func (d *Dispatcher) process(ctx context.Context, update tgbotapi.Update) {
+	defer d.processed.Add(1)
+	defer func() {
+		if r := recover(); r != nil {
+			d.panics.Add(1)
+			d.logger.Error("panic while handling update",
+				zap.Any("panic", r),
+				zap.Int("update_id", update.UpdateID),
+				zap.Stack("stack"),
+			)
+		}
+	}()
+
+	d.handler(ctx, update)
+}
+
+// reportStats logs the counters periodically while there is traffic, and
+// warns when updates had to wait for a full queue since the last report.
+func (d *Dispatcher) reportStats() {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	var last Stats
+	for {
+		select {
+		case <-ticker.C:
+			stats := d.Stats()
+			switch {
+			case stats.Waits > last.Waits || stats.Rejected > last.Rejected:
+				d.logger.Warn("update queues are full, reading updates is slowed down", statsFields(stats)...)
+			case stats.Dispatched > last.Dispatched:
+				d.logger.Info("update queue stats", statsFields(stats)...)
+			}
+			last = stats
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+func statsFields(stats Stats) []zap.Field {
+	return []zap.Field{
+		zap.Uint64("dispatched", stats.Dispatched),
+		zap.Uint64("processed", stats.Processed),
+		zap.Uint64("rejected", stats.Rejected),
+		zap.Uint64("panics", stats.Panics),
+		zap.Uint64("waits", stats.Waits),
+		zap.Duration("wait_time", stats.WaitTime),
+		zap.Int("queued", stats.Queued),
+		zap.Int("max_queued", stats.MaxQueued),
+	}
+}
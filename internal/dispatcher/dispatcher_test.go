@@ -0,0 +1,161 @@
+package dispatcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/config"
+)
+
+func messageUpdate(updateID int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: chatID},
+			From: &tgbotapi.User{ID: chatID},
+		},
+	}
+}
+
+func TestDispatcher_KeepsOrderPerChat(t *testing.T) {
+	var mu sync.Mutex
+	handled := map[int64][]int{}
+
+	d := NewDispatcher(func(ctx context.Context, update tgbotapi.Update) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		chatID := update.Message.Chat.ID
+		handled[chatID] = append(handled[chatID], update.UpdateID)
+	}, config.UpdatesConfig{Workers: 4, QueueSize: 2}, zap.NewNop())
+	d.Start(context.Background())
+
+	chats := []int64{1, 2, 3, -1001234567890}
+	updateID := 0
+	for i := 0; i < 20; i++ {
+		for _, chatID := range chats {
+			updateID++
+			require.NoError(t, d.Dispatch(context.Background(), messageUpdate(updateID, chatID)))
+		}
+	}
+	require.NoError(t, d.Shutdown(context.Background()))
+
+	for _, chatID := range chats {
+		ids := handled[chatID]
+		assert.Len(t, ids, 20)
+		assert.IsIncreasing(t, ids, "chat %d", chatID)
+	}
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(80), stats.Dispatched)
+	assert.Equal(t, uint64(80), stats.Processed)
+	assert.Zero(t, stats.Queued)
+}
+
+func TestDispatcher_SlowChatDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	fast := make(chan int64, 1)
+
+	d := NewDispatcher(func(ctx context.Context, update tgbotapi.Update) {
+		if update.Message.Chat.ID == 1 {
+			<-release
+			return
+		}
+		fast <- update.Message.Chat.ID
+	}, config.UpdatesConfig{Workers: 2, QueueSize: 1}, zap.NewNop())
+	d.Start(context.Background())
+
+	require.NoError(t, d.Dispatch(context.Background(), messageUpdate(1, 1)))
+	require.NoError(t, d.Dispatch(context.Background(), messageUpdate(2, 2)))
+
+	select {
+	case chatID := <-fast:
+		assert.Equal(t, int64(2), chatID)
+	case <-time.After(time.Second):
+		t.Fatal("update of another chat was not handled while chat 1 was busy")
+	}
+
+	close(release)
+	require.NoError(t, d.Shutdown(context.Background()))
+}
+
+func TestDispatcher_FullQueueAppliesBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	d := NewDispatcher(func(ctx context.Context, update tgbotapi.Update) {
+		<-release
+	}, config.UpdatesConfig{Workers: 1, QueueSize: 1}, zap.NewNop())
+	d.Start(context.Background())
+
+	// The first update is taken by the worker, the second fills the queue.
+	require.NoError(t, d.Dispatch(context.Background(), messageUpdate(1, 1)))
+	require.Eventually(t, func() bool { return d.Stats().Queued == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, d.Dispatch(context.Background(), messageUpdate(2, 1)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := d.Dispatch(ctx, messageUpdate(3, 1))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(1), stats.Waits)
+	assert.Equal(t, uint64(1), stats.Rejected)
+	assert.GreaterOrEqual(t, stats.WaitTime, 20*time.Millisecond)
+	assert.Equal(t, 1, stats.Queued)
+
+	close(release)
+	require.NoError(t, d.Shutdown(context.Background()))
+	assert.Equal(t, uint64(2), d.Stats().Processed)
+}
+
+func TestDispatcher_Shutdown(t *testing.T) {
+	t.Run("rejects updates after shutdown", func(t *testing.T) {
+		d := NewDispatcher(func(ctx context.Context, update tgbotapi.Update) {}, config.UpdatesConfig{Workers: 1, QueueSize: 1}, zap.NewNop())
+		d.Start(context.Background())
+		require.NoError(t, d.Shutdown(context.Background()))
+
+		assert.ErrorIs(t, d.Dispatch(context.Background(), messageUpdate(1, 1)), ErrClosed)
+		assert.NoError(t, d.Shutdown(context.Background()))
+	})
+
+	t.Run("gives up when handlers do not finish in time", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		d := NewDispatcher(func(ctx context.Context, update tgbotapi.Update) {
+			<-release
+		}, config.UpdatesConfig{Workers: 1, QueueSize: 1}, zap.NewNop())
+		d.Start(context.Background())
+		require.NoError(t, d.Dispatch(context.Background(), messageUpdate(1, 1)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, d.Shutdown(ctx), context.DeadlineExceeded)
+	})
+}
+
+func TestDispatcher_RecoversFromPanic(t *testing.T) {
+	var mu sync.Mutex
+	var handled []int
+	d := NewDispatcher(func(ctx context.Context, update tgbotapi.Update) {
+		if update.UpdateID == 1 {
+			panic("boom")
+		}
+		mu.Lock()
+		handled = append(handled, update.UpdateID)
+		mu.Unlock()
+	}, config.UpdatesConfig{Workers: 1, QueueSize: 2}, zap.NewNop())
+	d.Start(context.Background())
+
+	require.NoError(t, d.Dispatch(context.Background(), messageUpdate(1, 1)))
+	require.NoError(t, d.Dispatch(context.Background(), messageUpdate(2, 1)))
+	require.NoError(t, d.Shutdown(context.Background()))
+
+	assert.Equal(t, []int{2}, handled)
+	assert.Equal(t, uint64(1), d.Stats().Panics)
+}
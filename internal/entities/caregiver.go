@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CaregiverStatus string
+
+const (
+	CaregiverStatusPending  CaregiverStatus = "pending"
+	CaregiverStatusAccepted CaregiverStatus = "accepted"
+)
+
+// Caregiver links a patient to a user who is told about the patient's
+// missed doses. It starts as a pending invite identified by InviteToken and
+// gets a CaregiverID once the invite is accepted.
+type Caregiver struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	PatientID   uuid.UUID       `gorm:"type:uuid;not null;index" json:"patient_id"`
+	CaregiverID *uuid.UUID      `gorm:"type:uuid;index" json:"caregiver_id"`
+	InviteToken string          `gorm:"size:64;not null;uniqueIndex" json:"invite_token"`
+	Status      CaregiverStatus `gorm:"type:varchar(50);not null;index" json:"status"`
+	AcceptedAt  *time.Time      `json:"accepted_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func (Caregiver) TableName() string {
+	return "caregivers"
+}
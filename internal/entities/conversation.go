@@ -0,0 +1,143 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ConversationStep string
+
+const (
+	ConversationStepTitle    ConversationStep = "title"
+	ConversationStepType     ConversationStep = "type"
+	ConversationStepSchedule ConversationStep = "schedule"
+	ConversationStepComment  ConversationStep = "comment"
+	ConversationStepPhoto    ConversationStep = "photo"
+
+	// ConversationStepSnooze is not part of the wizard: it waits for a
+	// custom snooze duration for the dose in ExecutionID.
+	ConversationStepSnooze ConversationStep = "snooze"
+)
+
+// conversationSteps is the order in which the /new wizard asks for the
+// reminder's fields.
+var conversationSteps = []ConversationStep{
+	ConversationStepTitle,
+	ConversationStepType,
+	ConversationStepSchedule,
+	ConversationStepComment,
+	ConversationStepPhoto,
+}
+
+// ConversationStepCount is the number of steps in the /new wizard.
+var ConversationStepCount = len(conversationSteps)
+
+// Number returns the 1-based position of s in the wizard.
+func (s ConversationStep) Number() int {
+	for i, step := range conversationSteps {
+		if step == s {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Next returns the step after s, or an empty step when s is the last one.
+func (s ConversationStep) Next() ConversationStep {
+	for i, step := range conversationSteps {
+		if step == s && i+1 < len(conversationSteps) {
+			return conversationSteps[i+1]
+		}
+	}
+	return ""
+}
+
+// Previous returns the step before s, or an empty step when s is the first
+// one.
+func (s ConversationStep) Previous() ConversationStep {
+	for i, step := range conversationSteps {
+		if step == s && i > 0 {
+			return conversationSteps[i-1]
+		}
+	}
+	return ""
+}
+
+// Conversation is the state of the /new wizard in a chat. It is kept in the
+// database so that a half-filled reminder survives a bot restart. When
+// ReminderID is set the conversation edits that reminder instead of creating
+// a new one. A snooze conversation instead keeps the execution being
+// snoozed and the message of its reminder.
+type Conversation struct {
+	ChatID      int64            `gorm:"primaryKey;autoIncrement:false" json:"chat_id"`
+	UserID      uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	ReminderID  *uuid.UUID       `gorm:"type:uuid" json:"reminder_id"`
+	ExecutionID *uuid.UUID       `gorm:"type:uuid" json:"execution_id"`
+	MessageID   *int             `json:"message_id"`
+	Step        ConversationStep `gorm:"type:varchar(50);not null" json:"step"`
+	Draft       ReminderDraft    `gorm:"type:jsonb;not null" json:"draft"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// IsEdit reports whether the conversation changes an existing reminder.
+func (c *Conversation) IsEdit() bool {
+	return c.ReminderID != nil
+}
+
+func (Conversation) TableName() string {
+	return "conversations"
+}
+
+// ReminderDraft holds the reminder fields collected so far by the wizard.
+type ReminderDraft struct {
+	Title              string       `json:"title,omitempty"`
+	Type               ReminderType `json:"type,omitempty"`
+	IntervalHours      *int         `json:"interval_hours,omitempty"`
+	TimesOfDay         []string     `json:"times_of_day,omitempty"`
+	Weekdays           Weekdays     `json:"weekdays,omitempty"`
+	RRule              *string      `json:"rrule,omitempty"`
+	MinIntervalMinutes *int         `json:"min_interval_minutes,omitempty"`
+	MaxDosesPerDay     *int         `json:"max_doses_per_day,omitempty"`
+	Comment            *string      `json:"comment,omitempty"`
+	ImageFileID        *string      `json:"image_file_id,omitempty"`
+}
+
+func (d ReminderDraft) Value() (driver.Value, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (d *ReminderDraft) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*d = ReminderDraft{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unsupported type for ReminderDraft: %T", value)
+	}
+	return json.Unmarshal(data, d)
+}
+
+// ClearSchedule forgets the schedule, which only makes sense for the type it
+// was entered for.
+func (d *ReminderDraft) ClearSchedule() {
+	d.IntervalHours = nil
+	d.TimesOfDay = nil
+	d.Weekdays = 0
+	d.RRule = nil
+	d.MinIntervalMinutes = nil
+	d.MaxDosesPerDay = nil
+}
@@ -13,24 +13,59 @@ const (
 	ReminderTypeWeekly   ReminderType = "weekly"
 	ReminderTypeCustom   ReminderType = "custom"
 	ReminderTypeSpecific ReminderType = "specific"
+	ReminderTypeRRule    ReminderType = "rrule"
+	ReminderTypeAsNeeded ReminderType = "as_needed"
 )
 
 type Reminder struct {
-	ID            uuid.UUID    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	UserID        uuid.UUID    `gorm:"type:uuid;not null;index" json:"user_id"`
-	Title         string       `gorm:"size:255;not null" json:"title"`
-	Comment       *string      `gorm:"type:text" json:"comment"`
-	ImageURL      *string      `gorm:"type:text" json:"image_url"`
-	Type          ReminderType `gorm:"type:varchar(50);not null;index" json:"type"`
-	IntervalHours *int         `json:"interval_hours"`
-	TimeOfDay     *string      `gorm:"size:5" json:"time_of_day"`
-	IsActive      bool         `gorm:"default:true;not null;index" json:"is_active"`
-	LastSentAt    *time.Time   `json:"last_sent_at"`
-	NextSendAt    *time.Time   `gorm:"index" json:"next_send_at"`
-	CreatedAt     time.Time    `json:"created_at"`
-	UpdatedAt     time.Time    `json:"updated_at"`
+	ID                 uuid.UUID    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID             uuid.UUID    `gorm:"type:uuid;not null;index" json:"user_id"`
+	Title              string       `gorm:"size:255;not null" json:"title"`
+	Comment            *string      `gorm:"type:text" json:"comment"`
+	ImageURL           *string      `gorm:"type:text" json:"image_url"`
+	ImageFileID        *string      `gorm:"size:255" json:"image_file_id"`
+	Type               ReminderType `gorm:"type:varchar(50);not null;index" json:"type"`
+	IntervalHours      *int         `json:"interval_hours"`
+	TimesOfDay         TimesOfDay   `gorm:"column:time_of_day;size:255" json:"times_of_day"`
+	Weekdays           Weekdays     `gorm:"not null;default:0" json:"weekdays"`
+	RRule              *string      `gorm:"column:rrule;type:text" json:"rrule"`
+	StartsAt           *time.Time   `gorm:"index" json:"starts_at"`
+	EndsAt             *time.Time   `json:"ends_at"`
+	MaxDoses           *int         `json:"max_doses"`
+	NagIntervalMinutes *int         `json:"nag_interval_minutes"`
+	NagMaxRepeats      *int         `json:"nag_max_repeats"`
+	MinIntervalMinutes *int         `json:"min_interval_minutes"`
+	MaxDosesPerDay     *int         `json:"max_doses_per_day"`
+	IsActive           bool         `gorm:"default:true;not null;index" json:"is_active"`
+	LastSentAt         *time.Time   `json:"last_sent_at"`
+	NextSendAt         *time.Time   `gorm:"index" json:"next_send_at"`
+	CreatedAt          time.Time    `json:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at"`
 }
 
 func (Reminder) TableName() string {
 	return "reminders"
 }
+
+// Nags reports whether an unanswered dose is repeated every
+// NagIntervalMinutes, up to NagMaxRepeats times, before it counts as missed.
+func (r *Reminder) Nags() bool {
+	return r.NagIntervalMinutes != nil && r.NagMaxRepeats != nil
+}
+
+// IsAsNeeded reports whether the medicine is taken only when needed: such a
+// reminder is never sent by the scheduler, its doses are logged by the user.
+func (r *Reminder) IsAsNeeded() bool {
+	return r.Type == ReminderTypeAsNeeded
+}
+
+// HasDoseLimits reports whether as-needed doses are limited by a minimum
+// interval between them or a maximum number per 24 hours.
+func (r *Reminder) HasDoseLimits() bool {
+	return r.MinIntervalMinutes != nil || r.MaxDosesPerDay != nil
+}
+
+// HasCourse reports whether the reminder is limited to a course of treatment.
+func (r *Reminder) HasCourse() bool {
+	return r.EndsAt != nil || r.MaxDoses != nil
+}
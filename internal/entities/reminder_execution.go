@@ -12,18 +12,92 @@ const (
 	ExecutionStatusSent      ExecutionStatus = "sent"
 	ExecutionStatusConfirmed ExecutionStatus = "confirmed"
 	ExecutionStatusSkipped   ExecutionStatus = "skipped"
+	ExecutionStatusSnoozed   ExecutionStatus = "snoozed"
+	ExecutionStatusMissed    ExecutionStatus = "missed"
 )
 
+// DeliveryStatus tracks sending a scheduled reminder to Telegram. A pending
+// delivery is retried until it is delivered or given up as failed.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// ExecutionSource tells how an execution came about: a scheduled reminder,
+// a dose the user logged without one or an intake of an as-needed medicine.
+// Only scheduled doses count towards adherence.
+type ExecutionSource string
+
+const (
+	ExecutionSourceScheduled ExecutionSource = "scheduled"
+	ExecutionSourceLogged    ExecutionSource = "logged"
+	ExecutionSourceAsNeeded  ExecutionSource = "as_needed"
+)
+
+// OnTimeWindow is how long after the reminder a dose still counts as taken
+// on time.
+const OnTimeWindow = 30 * time.Minute
+
 type ReminderExecution struct {
-	ID          uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	ReminderID  uuid.UUID       `gorm:"type:uuid;not null;index" json:"reminder_id"`
-	UserID      uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
-	Status      ExecutionStatus `gorm:"type:varchar(50);not null;index" json:"status"`
-	SentAt      time.Time       `gorm:"not null;index" json:"sent_at"`
-	ConfirmedAt *time.Time      `json:"confirmed_at"`
-	CreatedAt   time.Time       `json:"created_at"`
+	ID               uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ReminderID       uuid.UUID       `gorm:"type:uuid;not null;index" json:"reminder_id"`
+	UserID           uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status           ExecutionStatus `gorm:"type:varchar(50);not null;index" json:"status"`
+	Source           ExecutionSource `gorm:"type:varchar(20);not null;default:'scheduled';index" json:"source"`
+	Slot             *string         `gorm:"size:5" json:"slot"`
+	SentAt           time.Time       `gorm:"not null;index" json:"sent_at"`
+	ConfirmedAt      *time.Time      `json:"confirmed_at"`
+	TakenAt          *time.Time      `json:"taken_at"`
+	SnoozedUntil     *time.Time      `gorm:"index" json:"snoozed_until"`
+	SnoozeCount      int             `gorm:"not null;default:0" json:"snooze_count"`
+	MessageID        *int            `json:"message_id"`
+	DeliveredAt      *time.Time      `json:"delivered_at"`
+	DeliveryStatus   DeliveryStatus  `gorm:"type:varchar(20);not null;default:'delivered';index" json:"delivery_status"`
+	DeliveryAttempts int             `gorm:"not null;default:0" json:"delivery_attempts"`
+	NextAttemptAt    *time.Time      `gorm:"index" json:"next_attempt_at"`
+	DeliveryError    *string         `gorm:"type:text" json:"delivery_error"`
+	NagCount         int             `gorm:"not null;default:0" json:"nag_count"`
+	NaggedAt         *time.Time      `json:"nagged_at"`
+	EscalatedAt      *time.Time      `json:"escalated_at"`
+	CreatedAt        time.Time       `json:"created_at"`
 }
 
 func (ReminderExecution) TableName() string {
 	return "reminder_executions"
 }
+
+// IsFinal reports whether the dose has been answered or given up on, after
+// which its status no longer changes.
+func (e *ReminderExecution) IsFinal() bool {
+	switch e.Status {
+	case ExecutionStatusConfirmed, ExecutionStatusSkipped, ExecutionStatusMissed:
+		return true
+	default:
+		return false
+	}
+}
+
+// IntakeTime returns when a confirmed dose was taken. Doses confirmed before
+// intake times were recorded fall back to their confirmation time.
+func (e *ReminderExecution) IntakeTime() time.Time {
+	switch {
+	case e.TakenAt != nil:
+		return *e.TakenAt
+	case e.ConfirmedAt != nil:
+		return *e.ConfirmedAt
+	default:
+		return e.SentAt
+	}
+}
+
+// IsLate reports whether a confirmed dose was taken later than OnTimeWindow
+// after its reminder.
+func (e *ReminderExecution) IsLate() bool {
+	if e.Status != ExecutionStatusConfirmed {
+		return false
+	}
+	return e.IntakeTime().Sub(e.SentAt) > OnTimeWindow
+}
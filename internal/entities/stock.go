@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stock tracks how much of a reminder's medication is left. OnHand and
+// PerDose are fractional so that half tablets can be counted.
+type Stock struct {
+	ID                  uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ReminderID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"reminder_id"`
+	UserID              uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	OnHand              float64    `gorm:"not null;default:0" json:"on_hand"`
+	PerDose             float64    `gorm:"not null;default:1" json:"per_dose"`
+	RefillThresholdDays int        `gorm:"not null;default:0" json:"refill_threshold_days"`
+	LowWarnedAt         *time.Time `json:"low_warned_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func (Stock) TableName() string {
+	return "stocks"
+}
+
+// DosesLeft returns how many whole doses the stock still covers.
+func (s *Stock) DosesLeft() int {
+	if s.PerDose <= 0 {
+		return 0
+	}
+	return int(s.OnHand/s.PerDose + 1e-9)
+}
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// TimesOfDay is a list of "HH:MM" dose times stored as a comma-separated
+// string, so single-time rows written before it existed read back unchanged.
+type TimesOfDay []string
+
+func (t TimesOfDay) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	return strings.Join(t, ","), nil
+}
+
+func (t *TimesOfDay) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("unsupported type for TimesOfDay: %T", value)
+	}
+
+	var times TimesOfDay
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			times = append(times, part)
+		}
+	}
+	*t = times
+	return nil
+}
+
+func (TimesOfDay) GormDataType() string {
+	return "string"
+}
+
+func (t TimesOfDay) String() string {
+	return strings.Join(t, ", ")
+}
@@ -7,17 +7,53 @@ import (
 )
 
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	TelegramID   int64     `gorm:"uniqueIndex;not null" json:"telegram_id"`
-	Username     *string   `gorm:"size:255" json:"username"`
-	FirstName    string    `gorm:"size:255;not null" json:"first_name"`
-	LastName     *string   `gorm:"size:255" json:"last_name"`
-	LanguageCode *string   `gorm:"size:10" json:"language_code"`
-	IsActive     bool      `gorm:"default:true;not null;index" json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                    uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TelegramID            int64      `gorm:"uniqueIndex;not null" json:"telegram_id"`
+	Username              *string    `gorm:"size:255" json:"username"`
+	FirstName             string     `gorm:"size:255;not null" json:"first_name"`
+	LastName              *string    `gorm:"size:255" json:"last_name"`
+	LanguageCode          *string    `gorm:"size:10" json:"language_code"`
+	Timezone              *string    `gorm:"size:64" json:"timezone"`
+	CaregiverDelayMinutes *int       `json:"caregiver_delay_minutes"`
+	DigestWeekday         *int       `json:"digest_weekday"`
+	DigestTime            *string    `gorm:"size:5" json:"digest_time"`
+	DigestNextAt          *time.Time `gorm:"index" json:"digest_next_at"`
+	IsActive              bool       `gorm:"default:true;not null;index" json:"is_active"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
+
+// Location returns the user's time zone, falling back to the server zone
+// when none is set or the stored name can no longer be loaded.
+func (u *User) Location() *time.Location {
+	if u.Timezone == nil || *u.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(*u.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// HasDigest reports whether the user subscribed to the weekly digest.
+func (u *User) HasDigest() bool {
+	return u.DigestWeekday != nil && u.DigestTime != nil
+}
+
+// DefaultCaregiverDelay is used when the user has not chosen how long a dose
+// may stay unconfirmed before caregivers are notified.
+const DefaultCaregiverDelay = time.Hour
+
+// CaregiverDelay returns how long a dose may stay unconfirmed before the
+// user's caregivers are notified.
+func (u *User) CaregiverDelay() time.Duration {
+	if u.CaregiverDelayMinutes == nil {
+		return DefaultCaregiverDelay
+	}
+	return time.Duration(*u.CaregiverDelayMinutes) * time.Minute
+}
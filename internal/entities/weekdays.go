@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// Weekdays is a bit set of days of the week, bit N standing for time.Weekday(N).
+type Weekdays uint8
+
+func NewWeekdays(days ...time.Weekday) Weekdays {
+	var w Weekdays
+	for _, day := range days {
+		w |= 1 << uint(day)
+	}
+	return w
+}
+
+func (w Weekdays) Has(day time.Weekday) bool {
+	return w&(1<<uint(day)) != 0
+}
+
+func (w Weekdays) IsEmpty() bool {
+	return w&0x7f == 0
+}
+
+// Days returns the set's days in calendar order starting from Monday.
+func (w Weekdays) Days() []time.Weekday {
+	var days []time.Weekday
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		if w.Has(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
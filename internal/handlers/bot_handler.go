@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -57,6 +59,11 @@ func (h *BotHandler) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
 		return
 	}
 
+	if msg.Location != nil {
+		h.handleLocation(ctx, msg)
+		return
+	}
+
 	h.handleTextMessage(ctx, msg)
 }
 
@@ -66,15 +73,31 @@ func (h *BotHandler) handleCommand(ctx context.Context, msg *tgbotapi.Message) {
 
 	switch command {
 	case "start":
+		if payload := msg.CommandArguments(); strings.HasPrefix(payload, caregiverInvitePrefix) {
+			h.handleCaregiverInvite(ctx, chatID, int64(msg.From.ID), strings.TrimPrefix(payload, caregiverInvitePrefix))
+			return
+		}
 		h.handleStart(ctx, chatID, msg.From)
 	case "help":
 		h.handleHelp(ctx, chatID)
 	case "new":
 		h.handleNewReminder(ctx, chatID, int64(msg.From.ID))
+	case "cancel":
+		h.handleCancel(ctx, chatID)
 	case "list":
 		h.handleListReminders(ctx, chatID, int64(msg.From.ID))
 	case "stats":
-		h.handleStats(ctx, chatID, int64(msg.From.ID))
+		h.handleStats(ctx, chatID, int64(msg.From.ID), msg.CommandArguments())
+	case "timezone":
+		h.handleTimezone(ctx, chatID, int64(msg.From.ID), msg.CommandArguments())
+	case "caregivers":
+		h.handleCaregivers(ctx, chatID, int64(msg.From.ID), msg.CommandArguments())
+	case "stock":
+		h.handleStock(ctx, chatID, int64(msg.From.ID), msg.CommandArguments())
+	case "digest":
+		h.handleDigest(ctx, chatID, int64(msg.From.ID), msg.CommandArguments())
+	case "took":
+		h.handleTook(ctx, chatID, int64(msg.From.ID), msg.CommandArguments())
 	default:
 		h.sendMessage(chatID, "Неизвестная команда. Используйте /help для списка команд.")
 	}
@@ -88,6 +111,10 @@ func (h *BotHandler) handleStart(ctx context.Context, chatID int64, user *tgbota
 			"/new - создать новое напоминание\n"+
 			"/list - список ваших напоминаний\n"+
 			"/stats - статистика выполнения\n"+
+			"/timezone - часовой пояс\n"+
+			"/stock - остатки лекарств\n"+
+			"/caregivers - опекуны и подопечные\n"+
+			"/digest - итоги недели\n"+
 			"/help - помощь\n\n"+
 			"Начните с команды /new для создания первого напоминания!",
 		user.FirstName,
@@ -100,41 +127,39 @@ func (h *BotHandler) handleHelp(ctx context.Context, chatID int64) {
 
 /new - Создать новое напоминание
 /list - Показать все ваши напоминания
-/stats - Показать статистику выполнения напоминаний
+/stats - Показать статистику выполнения напоминаний (период выбирается кнопками или, например, /stats 01.05.2026-31.05.2026)
+/timezone - Установить часовой пояс (например, /timezone Europe/Moscow)
+/took - Отметить принятое лекарство, в том числе задним числом (например, /took 1 08:30)
+/stock - Остатки лекарств и напоминание о покупке (например, /stock 1 60)
+/caregivers - Опекуны: пригласить (/caregivers invite), задержка уведомления (/caregivers delay 60)
+/digest - Итоги недели по выбранному дню и времени (например, /digest вс 20:00; отключить — /digest off)
+/cancel - Отменить создание напоминания
 /help - Показать эту справку
 
-Для создания напоминания используйте команду /new и следуйте инструкциям.`
-	h.sendMessage(chatID, text)
-}
+Для создания напоминания используйте команду /new и следуйте шагам.
+Чтобы прикрепить фото упаковки, отправьте его с подписью — номером напоминания из /list или его названием.
 
-func (h *BotHandler) handleNewReminder(ctx context.Context, chatID int64, telegramUserID int64) {
-	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
-	if err != nil || user == nil {
-		h.sendMessage(chatID, "Ошибка: пользователь не найден. Попробуйте /start")
-		return
-	}
-
-	text := `Создание нового напоминания 📝
-
-Пожалуйста, отправьте данные в следующем формате:
-Название|Тип|Комментарий|Время
+Быстрое создание одной строкой:
+Название|Тип|Комментарий|Время|Курс|Повтор
 
 Типы напоминаний:
 - daily - ежедневно
-- weekly - еженедельно
+- weekly - по дням недели (формат: пн,ср,пт 08:00)
 - custom - кастомный интервал (укажите количество часов)
-- specific - конкретное время каждый день (формат HH:MM)
+- specific - конкретное время каждый день (формат HH:MM, можно несколько через запятую)
+- rrule - правило повторения iCalendar (RRULE, можно с DTSTART и EXDATE)
+- as\_needed - по необходимости: бот не напоминает, приём отмечается через /took (формат: минимальный интервал в часах и максимум за сутки, например 4 3)
 
 Примеры:
 Лекарство|daily|Принять после еды|09:00
-Витамины|custom|Утром|6
-Завтрак|specific|Важно!|08:30
-
-Или используйте упрощенный формат:
-Название|daily
-
-Для отмены отправьте /cancel`
-
+Антибиотик|specific|После еды|08:00,14:00,21:00|7 дней
+Укол|weekly|Вечером|пн,ср,пт 20:00
+Таблетка|rrule|Через день|DTSTART:20260101T090000 RRULE:FREQ=DAILY;INTERVAL=2
+Инсулин|specific||08:00||15x3
+Ибупрофен|as\_needed|Не больше 3 раз в день|4 3
+
+Курс лечения (необязательно): период 01.02.2026-14.02.2026, длительность "10 дней" и/или число приёмов "20 доз" через запятую.
+Повтор (необязательно): "15x3" — если не ответить, бот повторит напоминание каждые 15 минут до 3 раз.`
 	h.sendMessage(chatID, text)
 }
 
@@ -157,73 +182,178 @@ func (h *BotHandler) handleListReminders(ctx context.Context, chatID int64, tele
 		return
 	}
 
+	loc := user.Location()
+
 	var builder strings.Builder
 	builder.WriteString("📋 Ваши напоминания:\n\n")
 
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(reminders))
 	for i, reminder := range reminders {
-		status := "✅ Активно"
-		if !reminder.IsActive {
-			status = "❌ Неактивно"
-		}
-
 		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, reminder.Title))
-		builder.WriteString(fmt.Sprintf("   Тип: %s\n", reminder.Type))
-		if reminder.Comment != nil {
-			builder.WriteString(fmt.Sprintf("   Комментарий: %s\n", *reminder.Comment))
-		}
-		if reminder.NextSendAt != nil {
-			builder.WriteString(fmt.Sprintf("   Следующая отправка: %s\n", reminder.NextSendAt.Format("02.01.2006 15:04")))
-		}
-		builder.WriteString(fmt.Sprintf("   Статус: %s\n\n", status))
+		builder.WriteString(formatReminderDetails(reminder, loc))
+		builder.WriteString("\n")
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⚙️ %d. %s", i+1, reminder.Title), reminderCallback("open", reminder.ID)),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, builder.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+func formatReminderDetails(reminder *entities.Reminder, loc *time.Location) string {
+	status := "✅ Активно"
+	if !reminder.IsActive {
+		status = "❌ Неактивно"
 	}
 
-	h.sendMessage(chatID, builder.String())
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("   Тип: %s\n", reminder.Type))
+	if !reminder.Weekdays.IsEmpty() {
+		builder.WriteString(fmt.Sprintf("   Дни: %s\n", formatWeekdays(reminder.Weekdays)))
+	}
+	if len(reminder.TimesOfDay) > 0 {
+		builder.WriteString(fmt.Sprintf("   Время: %s\n", reminder.TimesOfDay))
+	}
+	if reminder.IntervalHours != nil {
+		builder.WriteString(fmt.Sprintf("   Интервал: %d ч\n", *reminder.IntervalHours))
+	}
+	if reminder.RRule != nil {
+		builder.WriteString(fmt.Sprintf("   Правило: %s\n", strings.ReplaceAll(*reminder.RRule, "\n", " ")))
+	}
+	if reminder.IsAsNeeded() {
+		builder.WriteString(fmt.Sprintf("   Ограничения: %s\n", formatDoseLimits(reminder)))
+	}
+	if reminder.HasCourse() {
+		builder.WriteString(fmt.Sprintf("   Курс: %s\n", formatCourse(reminder, loc)))
+	}
+	if reminder.Nags() {
+		builder.WriteString(fmt.Sprintf("   Повтор: %s\n", formatNag(reminder)))
+	}
+	if reminder.Comment != nil {
+		builder.WriteString(fmt.Sprintf("   Комментарий: %s\n", *reminder.Comment))
+	}
+	if hasImage(reminder) {
+		builder.WriteString("   Фото: 🖼 есть\n")
+	}
+	if reminder.NextSendAt != nil && reminder.IsActive {
+		builder.WriteString(fmt.Sprintf("   Следующая отправка: %s\n", reminder.NextSendAt.In(loc).Format("02.01.2006 15:04")))
+	}
+	builder.WriteString(fmt.Sprintf("   Статус: %s\n", status))
+	return builder.String()
 }
 
-func (h *BotHandler) handleStats(ctx context.Context, chatID int64, telegramUserID int64) {
+func (h *BotHandler) handleTimezone(ctx context.Context, chatID int64, telegramUserID int64, args string) {
 	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
 	if err != nil || user == nil {
-		h.sendMessage(chatID, "Ошибка: пользователь не найден.")
+		h.sendMessage(chatID, "Ошибка: пользователь не найден. Попробуйте /start")
 		return
 	}
 
-	toDate := time.Now()
-	fromDate := toDate.AddDate(0, 0, -30)
+	args = strings.TrimSpace(args)
+	if args == "" {
+		current := "не задан (используется часовой пояс сервера)"
+		if user.Timezone != nil {
+			current = *user.Timezone
+		}
 
-	stats, err := h.usecases.ReminderExecution.GetStatisticsByUserID(ctx, user.ID, fromDate, toDate)
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+			"🕰 Ваш часовой пояс: %s\n"+
+				"Текущее время: %s\n\n"+
+				"Чтобы изменить, отправьте название пояса, например:\n"+
+				"/timezone Europe/Moscow\n"+
+				"/timezone Asia/Yekaterinburg\n\n"+
+				"Или поделитесь местоположением кнопкой ниже.",
+			current,
+			time.Now().In(user.Location()).Format("02.01.2006 15:04"),
+		))
+		keyboard := tgbotapi.NewOneTimeReplyKeyboard(
+			tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonLocation("📍 Отправить местоположение")),
+		)
+		keyboard.ResizeKeyboard = true
+		msg.ReplyMarkup = keyboard
+		if _, err := h.bot.Send(msg); err != nil {
+			h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+		}
+		return
+	}
+
+	user, err = h.usecases.User.SetTimezone(ctx, telegramUserID, args)
 	if err != nil {
-		h.logger.Error("failed to get statistics", zap.Error(err))
-		h.sendMessage(chatID, "Ошибка при получении статистики.")
+		h.logger.Warn("failed to set timezone", zap.Error(err), zap.Int64("user_id", telegramUserID))
+		h.sendMessage(chatID, "Неизвестный часовой пояс. Используйте название из базы IANA, например Europe/Moscow.")
 		return
 	}
 
-	text := fmt.Sprintf(
-		"📊 Статистика за последние 30 дней:\n\n"+
-			"Отправлено: %d\n"+
-			"Подтверждено: %d\n"+
-			"Пропущено: %d\n"+
-			"Процент выполнения: %.1f%%",
-		stats.TotalSent,
-		stats.TotalConfirmed,
-		stats.TotalSkipped,
-		stats.ConfirmationRate,
-	)
+	h.onTimezoneChanged(ctx, chatID, user)
+}
 
-	h.sendMessage(chatID, text)
+func (h *BotHandler) handleLocation(ctx context.Context, msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+	telegramUserID := int64(msg.From.ID)
+
+	user, err := h.usecases.User.SetTimezoneByCoordinates(ctx, telegramUserID, msg.Location.Latitude, msg.Location.Longitude)
+	if err != nil {
+		h.logger.Error("failed to set timezone by location", zap.Error(err), zap.Int64("user_id", telegramUserID))
+		h.sendMessage(chatID, "Не удалось определить часовой пояс по местоположению. Попробуйте /timezone Europe/Moscow")
+		return
+	}
+
+	h.onTimezoneChanged(ctx, chatID, user)
+}
+
+func (h *BotHandler) onTimezoneChanged(ctx context.Context, chatID int64, user *entities.User) {
+	if err := h.usecases.Reminder.RescheduleByUserID(ctx, user.ID); err != nil {
+		h.logger.Error("failed to reschedule reminders", zap.Error(err), zap.String("user_id", user.ID.String()))
+	}
+	if err := h.usecases.Digest.Reschedule(ctx, user); err != nil {
+		h.logger.Error("failed to reschedule digest", zap.Error(err), zap.String("user_id", user.ID.String()))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(
+		"✅ Часовой пояс установлен: %s\nТекущее время: %s\n\nНапоминания пересчитаны по новому времени.",
+		*user.Timezone,
+		time.Now().In(user.Location()).Format("02.01.2006 15:04"),
+	))
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(false)
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
 }
 
 func (h *BotHandler) handleTextMessage(ctx context.Context, msg *tgbotapi.Message) {
-	text := strings.TrimSpace(msg.Text)
 	chatID := msg.Chat.ID
-	telegramUserID := int64(msg.From.ID)
 
-	if text == "/cancel" {
-		h.sendMessage(chatID, "Создание напоминания отменено.")
+	conversation, err := h.usecases.Conversation.Get(ctx, chatID)
+	if err != nil {
+		h.logger.Error("failed to get conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+	if conversation != nil && conversation.Step == entities.ConversationStepSnooze {
+		h.handleSnoozeMessage(ctx, msg, conversation)
+		return
+	}
+	if conversation != nil {
+		h.handleWizardMessage(ctx, msg, conversation)
 		return
 	}
 
+	if len(msg.Photo) > 0 {
+		h.handlePhoto(ctx, msg)
+		return
+	}
+
+	h.createFromLine(ctx, chatID, int64(msg.From.ID), strings.TrimSpace(msg.Text))
+}
+
+// createFromLine creates a reminder from the one-line format
+// Название|Тип|Комментарий|Время|Курс|Повтор.
+func (h *BotHandler) createFromLine(ctx context.Context, chatID int64, telegramUserID int64, text string) {
 	if !strings.Contains(text, "|") {
-		h.sendMessage(chatID, "Неверный формат. Используйте формат: Название|Тип|Комментарий|Время\nИли: Название|Тип\n\nДля отмены отправьте /cancel")
+		h.sendMessage(chatID, "Чтобы создать напоминание, отправьте /new. Список команд: /help")
 		return
 	}
 
@@ -245,24 +375,20 @@ func (h *BotHandler) handleTextMessage(ctx context.Context, msg *tgbotapi.Messag
 		return
 	}
 
-	var reminderType entities.ReminderType
-	switch reminderTypeStr {
-	case "daily":
-		reminderType = entities.ReminderTypeDaily
-	case "weekly":
-		reminderType = entities.ReminderTypeWeekly
-	case "custom":
-		reminderType = entities.ReminderTypeCustom
-	case "specific":
-		reminderType = entities.ReminderTypeSpecific
-	default:
-		h.sendMessage(chatID, fmt.Sprintf("Неизвестный тип напоминания: %s\nДоступные типы: daily, weekly, custom, specific", reminderTypeStr))
+	reminderType, ok := parseReminderType(reminderTypeStr)
+	if !ok {
+		h.sendMessage(chatID, fmt.Sprintf("Неизвестный тип напоминания: %s\nДоступные типы: daily, weekly, custom, specific, rrule, as\\_needed", escapeMarkdown(reminderTypeStr)))
 		return
 	}
 
 	var comment *string
-	var timeOfDay *string
+	var timesOfDay []string
+	var weekdays entities.Weekdays
 	var intervalHours *int
+	var rule *string
+	var startsAt, endsAt *time.Time
+	var maxDoses *int
+	var minInterval, maxPerDay *int
 
 	if len(parts) >= 3 && parts[2] != "" {
 		comment = &parts[2]
@@ -276,15 +402,33 @@ func (h *BotHandler) handleTextMessage(ctx context.Context, msg *tgbotapi.Messag
 				return
 			}
 			intervalHours = &interval
+		} else if reminderType == entities.ReminderTypeWeekly {
+			days, times, ok := parseWeeklySchedule(parts[3])
+			if !ok {
+				h.sendMessage(chatID, "Ошибка: для типа 'weekly' укажите дни недели и время, например: пн,ср,пт 08:00")
+				return
+			}
+			weekdays = days
+			timesOfDay = times
 		} else if reminderType == entities.ReminderTypeSpecific {
-			if _, err := time.Parse("15:04", parts[3]); err != nil {
-				h.sendMessage(chatID, "Ошибка: неверный формат времени. Используйте формат HH:MM (например, 09:00)")
+			times, ok := parseTimesOfDay(parts[3])
+			if !ok {
+				h.sendMessage(chatID, "Ошибка: неверный формат времени. Используйте формат HH:MM (например, 09:00 или 08:00,14:00,21:00)")
+				return
+			}
+			timesOfDay = times
+		} else if reminderType == entities.ReminderTypeRRule {
+			rule = &parts[3]
+		} else if reminderType == entities.ReminderTypeAsNeeded {
+			var ok bool
+			minInterval, maxPerDay, ok = parseDoseLimits(parts[3])
+			if !ok {
+				h.sendMessage(chatID, "Ошибка: для типа 'as\\_needed' укажите минимальный интервал в часах и максимум приёмов за сутки, например: 4 3")
 				return
 			}
-			timeOfDay = &parts[3]
 		} else {
-			if _, err := time.Parse("15:04", parts[3]); err == nil {
-				timeOfDay = &parts[3]
+			if times, ok := parseTimesOfDay(parts[3]); ok {
+				timesOfDay = times
 			}
 		}
 	}
@@ -295,31 +439,86 @@ func (h *BotHandler) handleTextMessage(ctx context.Context, msg *tgbotapi.Messag
 		return
 	}
 
-	reminder, err := h.usecases.Reminder.Create(ctx, user.ID, title, comment, nil, reminderType, intervalHours, timeOfDay)
+	if len(parts) >= 5 && parts[4] != "" {
+		var ok bool
+		startsAt, endsAt, maxDoses, ok = parseCourse(parts[4], time.Now().In(user.Location()))
+		if !ok {
+			h.sendMessage(chatID, "Ошибка: неверный формат курса. Примеры: 01.02.2026-14.02.2026, 10 дней, 20 доз")
+			return
+		}
+	}
+
+	var nagInterval, nagRepeats *int
+	if len(parts) >= 6 && parts[5] != "" {
+		interval, repeats, ok := parseNag(parts[5])
+		if !ok {
+			h.sendMessage(chatID, "Ошибка: неверный формат повтора. Пример: 15x3 — каждые 15 минут, до 3 раз")
+			return
+		}
+		nagInterval, nagRepeats = &interval, &repeats
+	}
+
+	reminder, err := h.usecases.Reminder.Create(ctx, usecases.CreateReminderInput{
+		UserID:             user.ID,
+		Title:              title,
+		Comment:            comment,
+		Type:               reminderType,
+		IntervalHours:      intervalHours,
+		TimesOfDay:         timesOfDay,
+		Weekdays:           weekdays,
+		RRule:              rule,
+		StartsAt:           startsAt,
+		EndsAt:             endsAt,
+		MaxDoses:           maxDoses,
+		NagIntervalMinutes: nagInterval,
+		NagMaxRepeats:      nagRepeats,
+		MinIntervalMinutes: minInterval,
+		MaxDosesPerDay:     maxPerDay,
+	})
 	if err != nil {
 		h.logger.Error("failed to create reminder", zap.Error(err), zap.Int64("user_id", telegramUserID))
-		h.sendMessage(chatID, fmt.Sprintf("Ошибка при создании напоминания: %s", err.Error()))
+		h.sendMessage(chatID, fmt.Sprintf("Ошибка при создании напоминания: %s", escapeMarkdown(err.Error())))
 		return
 	}
 
-	var responseBuilder strings.Builder
-	responseBuilder.WriteString("✅ Напоминание успешно создано!\n\n")
-	responseBuilder.WriteString(fmt.Sprintf("📝 Название: %s\n", reminder.Title))
-	responseBuilder.WriteString(fmt.Sprintf("🔄 Тип: %s\n", reminder.Type))
+	h.sendMessage(chatID, formatCreatedReminder(reminder, user.Location()))
+}
+
+func formatCreatedReminder(reminder *entities.Reminder, loc *time.Location) string {
+	var builder strings.Builder
+	builder.WriteString("✅ Напоминание успешно создано!\n\n")
+	builder.WriteString(fmt.Sprintf("📝 Название: %s\n", reminder.Title))
+	builder.WriteString(fmt.Sprintf("🔄 Тип: %s\n", reminder.Type))
 	if reminder.Comment != nil {
-		responseBuilder.WriteString(fmt.Sprintf("💬 Комментарий: %s\n", *reminder.Comment))
+		builder.WriteString(fmt.Sprintf("💬 Комментарий: %s\n", *reminder.Comment))
+	}
+	if !reminder.Weekdays.IsEmpty() {
+		builder.WriteString(fmt.Sprintf("📆 Дни: %s\n", formatWeekdays(reminder.Weekdays)))
 	}
-	if reminder.TimeOfDay != nil {
-		responseBuilder.WriteString(fmt.Sprintf("⏰ Время: %s\n", *reminder.TimeOfDay))
+	if len(reminder.TimesOfDay) > 0 {
+		builder.WriteString(fmt.Sprintf("⏰ Время: %s\n", reminder.TimesOfDay))
+	}
+	if reminder.RRule != nil {
+		builder.WriteString(fmt.Sprintf("🔁 Правило:\n%s\n", *reminder.RRule))
+	}
+	if reminder.HasCourse() {
+		builder.WriteString(fmt.Sprintf("💊 Курс: %s\n", formatCourse(reminder, loc)))
+	}
+	if reminder.Nags() {
+		builder.WriteString(fmt.Sprintf("🔁 Повтор: %s\n", formatNag(reminder)))
 	}
 	if reminder.IntervalHours != nil {
-		responseBuilder.WriteString(fmt.Sprintf("⏱ Интервал: %d часов\n", *reminder.IntervalHours))
+		builder.WriteString(fmt.Sprintf("⏱ Интервал: %d часов\n", *reminder.IntervalHours))
+	}
+	if reminder.IsAsNeeded() {
+		builder.WriteString(fmt.Sprintf("⚖️ Ограничения: %s\n", formatDoseLimits(reminder)))
+		builder.WriteString("\nОтмечайте приём кнопкой «💊 Принять» в карточке из /list или командой /took.\n")
 	}
 	if reminder.NextSendAt != nil {
-		responseBuilder.WriteString(fmt.Sprintf("📅 Следующая отправка: %s\n", reminder.NextSendAt.Format("02.01.2006 15:04")))
+		builder.WriteString(fmt.Sprintf("📅 Следующая отправка: %s\n", reminder.NextSendAt.In(loc).Format("02.01.2006 15:04")))
 	}
 
-	h.sendMessage(chatID, responseBuilder.String())
+	return builder.String()
 }
 
 func (h *BotHandler) handleCallbackQuery(ctx context.Context, callback *tgbotapi.CallbackQuery) {
@@ -333,29 +532,356 @@ func (h *BotHandler) handleCallbackQuery(ctx context.Context, callback *tgbotapi
 	}
 
 	action := parts[0]
+	if strings.HasPrefix(action, "cg") {
+		h.handleCaregiverCallback(ctx, callback, action, parts[1])
+		return
+	}
+	if action == wizardCallbackPrefix {
+		h.handleWizardCallback(ctx, callback, parts)
+		return
+	}
+	if action == reminderCallbackPrefix {
+		h.handleReminderCallback(ctx, callback, parts)
+		return
+	}
+	if action == tookCallbackPrefix {
+		h.handleTookCallback(ctx, callback, parts)
+		return
+	}
+	if action == statsCallbackPrefix {
+		h.handleStatsCallback(ctx, callback, parts[1])
+		return
+	}
+
+	// Older messages carry "action:reminderID:executionID"; the execution
+	// ID is always the last UUID in the data.
+	executionIDPart := parts[len(parts)-1]
+	if action == "snooze" || action == "tookat" {
+		executionIDPart = parts[1]
+	}
+	executionID, err := uuid.Parse(executionIDPart)
+	if err != nil {
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+		return
+	}
+
+	user, err := h.usecases.User.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		h.answerCallbackQuery(callback.ID, "Пользователь не найден, отправьте /start")
+		return
+	}
 
 	switch action {
 	case "confirm":
+		now := time.Now()
+		changed, err := h.usecases.ReminderExecution.RecordConfirmed(ctx, user.ID, executionID, now)
+		if err != nil {
+			h.answerExecutionError(callback, executionID, err)
+			return
+		}
+		if !changed {
+			h.answerCallbackQuery(callback.ID, "Этот приём уже отмечен")
+			h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "✅ Подтверждено!")
+		h.markAnswered(callback.Message, "✅ Принято "+formatAnswerTime(now, user.Location()))
+	case "tookat":
+		minutes := 0
 		if len(parts) >= 3 {
-			executionID, err := uuid.Parse(parts[2])
-			if err == nil {
-				if err := h.usecases.ReminderExecution.RecordConfirmed(ctx, executionID); err == nil {
-					h.answerCallbackQuery(callback.ID, "✅ Подтверждено!")
-					h.sendMessage(chatID, "Спасибо! Напоминание подтверждено.")
-				}
-			}
+			minutes, _ = strconv.Atoi(parts[2])
+		}
+		if minutes <= 0 {
+			h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+			return
+		}
+		now := time.Now()
+		takenAt := now.Add(-time.Duration(minutes) * time.Minute)
+		changed, err := h.usecases.ReminderExecution.RecordConfirmed(ctx, user.ID, executionID, takenAt)
+		if err != nil {
+			h.answerExecutionError(callback, executionID, err)
+			return
 		}
+		if !changed {
+			h.answerCallbackQuery(callback.ID, "Этот приём уже отмечен")
+			h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "✅ Подтверждено!")
+		h.markAnswered(callback.Message, fmt.Sprintf("✅ Принято %s (отмечено в %s)",
+			formatAnswerTime(takenAt, user.Location()), now.In(user.Location()).Format("15:04")))
+	case "tookmenu":
+		h.editReplyMarkup(chatID, callback.Message.MessageID, takenAtKeyboard(executionID))
+		h.answerCallbackQuery(callback.ID, "")
 	case "skip":
-		if len(parts) >= 3 {
-			executionID, err := uuid.Parse(parts[2])
-			if err == nil {
-				if err := h.usecases.ReminderExecution.RecordSkipped(ctx, executionID); err == nil {
-					h.answerCallbackQuery(callback.ID, "⏭ Пропущено")
-					h.sendMessage(chatID, "Напоминание пропущено.")
-				}
+		changed, err := h.usecases.ReminderExecution.RecordSkipped(ctx, user.ID, executionID)
+		if err != nil {
+			h.answerExecutionError(callback, executionID, err)
+			return
+		}
+		if !changed {
+			h.answerCallbackQuery(callback.ID, "Этот приём уже отмечен")
+			h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "⏭ Пропущено")
+		h.markAnswered(callback.Message, "⏭ Пропущено "+formatAnswerTime(time.Now(), user.Location()))
+	case "snoozemenu":
+		h.editReplyMarkup(chatID, callback.Message.MessageID, snoozeKeyboard(executionID))
+		h.answerCallbackQuery(callback.ID, "")
+	case "snoozeback":
+		h.editReplyMarkup(chatID, callback.Message.MessageID, reminderKeyboard(executionID))
+		h.answerCallbackQuery(callback.ID, "")
+	case "snooze":
+		h.handleSnooze(ctx, callback, user, executionID, parts)
+	case "snoozecustom":
+		h.startCustomSnooze(ctx, callback, user, executionID)
+	default:
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+	}
+}
+
+// markAnswered appends the outcome to the reminder message and removes its
+// buttons, so the chat shows how each dose was answered.
+func (h *BotHandler) markAnswered(msg *tgbotapi.Message, outcome string) {
+	footer := "\n\n" + outcome
+
+	var edit tgbotapi.Chattable
+	if len(msg.Photo) > 0 {
+		caption := tgbotapi.NewEditMessageCaption(msg.Chat.ID, msg.MessageID, msg.Caption+footer)
+		caption.CaptionEntities = msg.CaptionEntities
+		edit = caption
+	} else {
+		text := tgbotapi.NewEditMessageText(msg.Chat.ID, msg.MessageID, msg.Text+footer)
+		text.Entities = msg.Entities
+		edit = text
+	}
+
+	if _, err := h.bot.Send(edit); err != nil {
+		h.logger.Error("failed to edit message", zap.Error(err), zap.Int64("chat_id", msg.Chat.ID))
+		h.editReplyMarkup(msg.Chat.ID, msg.MessageID, emptyKeyboard())
+	}
+}
+
+// answerExecutionError tells the user why a dose button did nothing. Buttons
+// of a dose that can no longer change are removed.
+func (h *BotHandler) answerExecutionError(callback *tgbotapi.CallbackQuery, executionID uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrExecutionFinalized):
+		h.answerCallbackQuery(callback.ID, "Этот приём уже отмечен")
+		h.editReplyMarkup(callback.Message.Chat.ID, callback.Message.MessageID, emptyKeyboard())
+	case errors.Is(err, usecases.ErrForbidden):
+		h.logger.Warn("execution access denied",
+			zap.Int64("telegram_user_id", callback.From.ID),
+			zap.String("execution_id", executionID.String()),
+		)
+		h.answerCallbackQuery(callback.ID, "Это напоминание принадлежит другому пользователю")
+	case errors.Is(err, usecases.ErrNotFound):
+		h.answerCallbackQuery(callback.ID, "Напоминание не найдено")
+		h.editReplyMarkup(callback.Message.Chat.ID, callback.Message.MessageID, emptyKeyboard())
+	default:
+		h.logger.Error("failed to update execution", zap.Error(err), zap.String("execution_id", executionID.String()))
+		h.answerCallbackQuery(callback.ID, "Ошибка, попробуйте ещё раз")
+	}
+}
+
+func (h *BotHandler) handleSnooze(ctx context.Context, callback *tgbotapi.CallbackQuery, user *entities.User, executionID uuid.UUID, parts []string) {
+	chatID := callback.Message.Chat.ID
+
+	minutes := 0
+	if len(parts) >= 3 {
+		minutes, _ = strconv.Atoi(parts[2])
+	}
+	if minutes <= 0 {
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+		return
+	}
+
+	until, err := h.usecases.ReminderExecution.Snooze(ctx, user.ID, executionID, time.Duration(minutes)*time.Minute)
+	if err != nil {
+		h.answerExecutionError(callback, executionID, err)
+		return
+	}
+
+	h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+	h.answerCallbackQuery(callback.ID, "💤 Отложено")
+	h.sendMessage(chatID, fmt.Sprintf("💤 Напомню ещё раз в %s.", until.In(user.Location()).Format("15:04")))
+}
+
+const snoozeDurationPrompt = "Через сколько напомнить? Например: 40, 1,5 ч, 2 ч 30 мин или 1:30 — от 1 минуты до 12 часов.\n\n/cancel — отменить."
+
+// startCustomSnooze asks for a snooze duration; the answer is handled by
+// handleSnoozeMessage.
+func (h *BotHandler) startCustomSnooze(ctx context.Context, callback *tgbotapi.CallbackQuery, user *entities.User, executionID uuid.UUID) {
+	chatID := callback.Message.Chat.ID
+
+	if _, err := h.usecases.Conversation.StartSnooze(ctx, chatID, user.ID, executionID, callback.Message.MessageID); err != nil {
+		h.logger.Error("failed to start conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+		h.answerCallbackQuery(callback.ID, "Ошибка, попробуйте ещё раз")
+		return
+	}
+
+	h.editReplyMarkup(chatID, callback.Message.MessageID, reminderKeyboard(executionID))
+	h.answerCallbackQuery(callback.ID, "")
+	h.sendMessage(chatID, snoozeDurationPrompt)
+}
+
+func (h *BotHandler) handleSnoozeMessage(ctx context.Context, msg *tgbotapi.Message, conversation *entities.Conversation) {
+	chatID := msg.Chat.ID
+
+	duration, ok := parseSnoozeDuration(msg.Text)
+	if !ok {
+		h.sendMessage(chatID, "Не удалось разобрать время. "+snoozeDurationPrompt)
+		return
+	}
+	if duration < usecases.MinSnoozeDuration || duration > usecases.MaxSnoozeDuration {
+		h.sendMessage(chatID, "Отложить можно от 1 минуты до 12 часов. Отправьте другое время или /cancel.")
+		return
+	}
+
+	until, err := h.usecases.ReminderExecution.Snooze(ctx, conversation.UserID, *conversation.ExecutionID, duration)
+	if err != nil && !errors.Is(err, usecases.ErrExecutionFinalized) && !errors.Is(err, usecases.ErrNotFound) && !errors.Is(err, usecases.ErrForbidden) {
+		h.logger.Error("failed to snooze execution", zap.Error(err), zap.String("execution_id", conversation.ExecutionID.String()))
+		h.sendMessage(chatID, "Ошибка, попробуйте ещё раз.")
+		return
+	}
+
+	if err := h.usecases.Conversation.Finish(ctx, chatID); err != nil {
+		h.logger.Error("failed to finish conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+	switch {
+	case errors.Is(err, usecases.ErrExecutionFinalized):
+		h.sendMessage(chatID, "Этот приём уже отмечен.")
+		return
+	case err != nil:
+		h.sendMessage(chatID, "Напоминание не найдено.")
+		return
+	}
+
+	if conversation.MessageID != nil {
+		h.editReplyMarkup(chatID, *conversation.MessageID, emptyKeyboard())
+	}
+	loc := time.UTC
+	if user, err := h.usecases.User.GetByID(ctx, conversation.UserID); err == nil && user != nil {
+		loc = user.Location()
+	}
+	h.sendMessage(chatID, fmt.Sprintf("💤 Напомню ещё раз в %s.", until.In(loc).Format("15:04")))
+}
+
+// parseSnoozeDuration reads a snooze duration such as "40", "40 мин",
+// "1,5 ч", "2ч 30м" or "1:30". A bare number means minutes.
+func parseSnoozeDuration(value string) (time.Duration, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, false
+	}
+
+	if hours, minutes, ok := strings.Cut(value, ":"); ok {
+		h, err := strconv.Atoi(hours)
+		if err != nil || h < 0 {
+			return 0, false
+		}
+		m, err := strconv.Atoi(minutes)
+		if err != nil || m < 0 || m > 59 || len(minutes) != 2 {
+			return 0, false
+		}
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, true
+	}
+
+	matches := snoozeDurationPart.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+
+	var total time.Duration
+	end := 0
+	for _, match := range matches {
+		if strings.TrimSpace(value[end:match[0]]) != "" {
+			return 0, false
+		}
+		end = match[1]
+
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(value[match[2]:match[3]], ",", "."), 64)
+		if err != nil {
+			return 0, false
+		}
+		unit := time.Minute
+		if match[4] >= 0 {
+			if suffix := value[match[4]:match[5]]; strings.HasPrefix(suffix, "ч") || suffix == "h" {
+				unit = time.Hour
 			}
 		}
+		total += time.Duration(amount * float64(unit))
+	}
+	if strings.TrimSpace(value[end:]) != "" {
+		return 0, false
 	}
+	return total.Round(time.Minute), true
+}
+
+var snoozeDurationPart = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*(часов|часа|час|ч|h|минуты|минуту|минут|мин|м|m)?\.?`)
+
+func (h *BotHandler) editReplyMarkup(chatID int64, messageID int, keyboard tgbotapi.InlineKeyboardMarkup) {
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
+	if _, err := h.bot.Request(edit); err != nil {
+		h.logger.Error("failed to edit message keyboard", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+// emptyKeyboard removes the inline buttons from a message when used in an
+// edit; a nil keyboard would be rejected by Telegram.
+func emptyKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+}
+
+// reminderKeyboard builds the buttons attached to a delivered reminder.
+// Callback data stays within Telegram's 64-byte limit, so it carries only
+// the execution ID.
+func reminderKeyboard(executionID uuid.UUID) tgbotapi.InlineKeyboardMarkup {
+	id := executionID.String()
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Выполнено", "confirm:"+id),
+			tgbotapi.NewInlineKeyboardButtonData("⏭ Пропустить", "skip:"+id),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💤 10 мин", "snooze:"+id+":10"),
+			tgbotapi.NewInlineKeyboardButtonData("💤 30 мин", "snooze:"+id+":30"),
+			tgbotapi.NewInlineKeyboardButtonData("💤 1 ч", "snooze:"+id+":60"),
+			tgbotapi.NewInlineKeyboardButtonData("💤 Другое", "snoozemenu:"+id),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🕒 Принял раньше", "tookmenu:"+id),
+		),
+	)
+}
+
+func snoozeKeyboard(executionID uuid.UUID) tgbotapi.InlineKeyboardMarkup {
+	id := executionID.String()
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("5 мин", "snooze:"+id+":5"),
+			tgbotapi.NewInlineKeyboardButtonData("15 мин", "snooze:"+id+":15"),
+			tgbotapi.NewInlineKeyboardButtonData("2 ч", "snooze:"+id+":120"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("3 ч", "snooze:"+id+":180"),
+			tgbotapi.NewInlineKeyboardButtonData("4 ч", "snooze:"+id+":240"),
+			tgbotapi.NewInlineKeyboardButtonData("8 ч", "snooze:"+id+":480"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Своё время", "snoozecustom:"+id),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", "snoozeback:"+id),
+		),
+	)
+}
+
+// escapeMarkdown makes text that is not ours, such as user input or an error
+// naming a field like max_doses, safe to pass to sendMessage.
+func escapeMarkdown(text string) string {
+	return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, text)
 }
 
 func (h *BotHandler) sendMessage(chatID int64, text string) {
@@ -366,47 +892,287 @@ func (h *BotHandler) sendMessage(chatID int64, text string) {
 	}
 }
 
-func (h *BotHandler) SendReminder(ctx context.Context, reminder *entities.Reminder, executionID uuid.UUID) error {
+// SendReminder delivers the reminder for the given execution and returns
+// the ID of the Telegram message it was sent in.
+func (h *BotHandler) SendReminder(ctx context.Context, reminder *entities.Reminder, executionID uuid.UUID) (int, error) {
+	return h.deliverReminder(ctx, reminder, executionID, "")
+}
+
+// SendNag repeats an unanswered reminder as a new message, so the user is
+// notified again, and strips the buttons from the previous one.
+func (h *BotHandler) SendNag(ctx context.Context, reminder *entities.Reminder, execution *entities.ReminderExecution) (int, error) {
+	user, err := h.usecases.User.GetByID(ctx, reminder.UserID)
+	if err != nil || user == nil {
+		return 0, fmt.Errorf("user not found")
+	}
+
+	if execution.MessageID != nil {
+		h.editReplyMarkup(int64(user.TelegramID), *execution.MessageID, emptyKeyboard())
+	}
+
+	header := "🔁 Повторное напоминание"
+	if reminder.NagMaxRepeats != nil {
+		header = fmt.Sprintf("🔁 Повторное напоминание (%d из %d)", execution.NagCount+1, *reminder.NagMaxRepeats)
+	}
+	return h.deliverReminder(ctx, reminder, execution.ID, header)
+}
+
+// SendMissed tells the user a dose was marked as missed after all repeats
+// went unanswered.
+func (h *BotHandler) SendMissed(ctx context.Context, reminder *entities.Reminder, execution *entities.ReminderExecution) error {
 	user, err := h.usecases.User.GetByID(ctx, reminder.UserID)
 	if err != nil || user == nil {
 		return fmt.Errorf("user not found")
 	}
 
+	if execution.MessageID != nil {
+		h.editReplyMarkup(int64(user.TelegramID), *execution.MessageID, emptyKeyboard())
+	}
+
+	msg := tgbotapi.NewMessage(int64(user.TelegramID), fmt.Sprintf("❗ Приём *%s* отмечен как пропущенный: ответа не было.", reminder.Title))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := h.bot.Send(msg); err != nil {
+		return fmt.Errorf("failed to send missed notice: %w", err)
+	}
+
+	return nil
+}
+
+func (h *BotHandler) deliverReminder(ctx context.Context, reminder *entities.Reminder, executionID uuid.UUID, header string) (int, error) {
+	user, err := h.usecases.User.GetByID(ctx, reminder.UserID)
+	if err != nil || user == nil {
+		return 0, fmt.Errorf("user not found")
+	}
+
 	var builder strings.Builder
+	if header != "" {
+		builder.WriteString(header + "\n")
+	}
 	builder.WriteString(fmt.Sprintf("🔔 *%s*\n\n", reminder.Title))
 
 	if reminder.Comment != nil {
 		builder.WriteString(fmt.Sprintf("%s\n\n", *reminder.Comment))
 	}
 
-	confirmBtn := tgbotapi.NewInlineKeyboardButtonData("✅ Выполнено", fmt.Sprintf("confirm:%s:%s", reminder.ID.String(), executionID.String()))
-	skipBtn := tgbotapi.NewInlineKeyboardButtonData("⏭ Пропустить", fmt.Sprintf("skip:%s:%s", reminder.ID.String(), executionID.String()))
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(confirmBtn, skipBtn),
-	)
+	keyboard := reminderKeyboard(executionID)
 
-	if reminder.ImageURL != nil && *reminder.ImageURL != "" {
-		photo := tgbotapi.NewPhoto(int64(user.TelegramID), tgbotapi.FileURL(*reminder.ImageURL))
+	var image tgbotapi.RequestFileData
+	switch {
+	case reminder.ImageFileID != nil && *reminder.ImageFileID != "":
+		image = tgbotapi.FileID(*reminder.ImageFileID)
+	case reminder.ImageURL != nil && *reminder.ImageURL != "":
+		image = tgbotapi.FileURL(*reminder.ImageURL)
+	}
+
+	var sent tgbotapi.Message
+	if image != nil {
+		photo := tgbotapi.NewPhoto(int64(user.TelegramID), image)
 		photo.Caption = builder.String()
 		photo.ParseMode = tgbotapi.ModeMarkdown
 		photo.ReplyMarkup = keyboard
 
-		if _, err := h.bot.Send(photo); err != nil {
-			return fmt.Errorf("failed to send reminder: %w", err)
+		if sent, err = h.bot.Send(photo); err != nil {
+			return 0, fmt.Errorf("failed to send reminder: %w", err)
 		}
 	} else {
 		msg := tgbotapi.NewMessage(int64(user.TelegramID), builder.String())
 		msg.ParseMode = tgbotapi.ModeMarkdown
 		msg.ReplyMarkup = keyboard
 
-		if _, err := h.bot.Send(msg); err != nil {
-			return fmt.Errorf("failed to send reminder: %w", err)
+		if sent, err = h.bot.Send(msg); err != nil {
+			return 0, fmt.Errorf("failed to send reminder: %w", err)
 		}
 	}
 
+	return sent.MessageID, nil
+}
+
+func (h *BotHandler) SendCourseFinished(ctx context.Context, reminder *entities.Reminder, summary *usecases.CourseSummary) error {
+	user, err := h.usecases.User.GetByID(ctx, reminder.UserID)
+	if err != nil || user == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("🏁 Курс *%s* завершён, напоминание отключено.\n\n", reminder.Title))
+	builder.WriteString(fmt.Sprintf("Всего напоминаний: %d\n", summary.TotalDoses))
+	builder.WriteString(fmt.Sprintf("✅ Выполнено: %d\n", summary.Confirmed))
+	builder.WriteString(fmt.Sprintf("⏭ Пропущено: %d\n", summary.Skipped))
+	builder.WriteString(fmt.Sprintf("❗ Пропущено без ответа: %d\n", summary.Missed))
+	builder.WriteString(fmt.Sprintf("❔ Без ответа: %d\n", summary.Unanswered))
+	builder.WriteString(fmt.Sprintf("📈 Соблюдение курса: %.1f%%\n", summary.AdherenceRate))
+
+	msg := tgbotapi.NewMessage(int64(user.TelegramID), builder.String())
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := h.bot.Send(msg); err != nil {
+		return fmt.Errorf("failed to send course summary: %w", err)
+	}
+
 	return nil
 }
 
+func parseTimesOfDay(value string) ([]string, bool) {
+	var times []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if _, err := time.Parse("15:04", part); err != nil {
+			return nil, false
+		}
+		times = append(times, part)
+	}
+	return times, len(times) > 0
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"пн": time.Monday, "вт": time.Tuesday, "ср": time.Wednesday, "чт": time.Thursday,
+	"пт": time.Friday, "сб": time.Saturday, "вс": time.Sunday,
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+var weekdayShortNames = [...]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"}
+
+// parseCourse parses the optional course part of /new: a date range
+// "01.02.2026-14.02.2026", a length "10 дней" counted from today and/or a
+// dose limit "20 доз", separated by commas.
+func parseCourse(value string, now time.Time) (*time.Time, *time.Time, *int, bool) {
+	var startsAt, endsAt *time.Time
+	var maxDoses *int
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if from, to, ok := strings.Cut(part, "-"); ok {
+			start, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(from), now.Location())
+			if err != nil {
+				return nil, nil, nil, false
+			}
+			last, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(to), now.Location())
+			if err != nil || last.Before(start) {
+				return nil, nil, nil, false
+			}
+			end := last.AddDate(0, 0, 1)
+			startsAt, endsAt = &start, &end
+			continue
+		}
+
+		fields := strings.Fields(part)
+		if len(fields) != 2 {
+			return nil, nil, nil, false
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n <= 0 {
+			return nil, nil, nil, false
+		}
+		switch {
+		case strings.HasPrefix(fields[1], "доз"):
+			maxDoses = &n
+		case strings.HasPrefix(fields[1], "дн") || strings.HasPrefix(fields[1], "ден"):
+			end := startOfDay(now).AddDate(0, 0, n)
+			endsAt = &end
+		default:
+			return nil, nil, nil, false
+		}
+	}
+
+	return startsAt, endsAt, maxDoses, true
+}
+
+// parseNag parses the repeat part of /new: "15x3" means every 15 minutes,
+// up to 3 times. Latin and Cyrillic "x" are both accepted.
+func parseNag(value string) (int, int, bool) {
+	value = strings.ToLower(strings.ReplaceAll(value, " ", ""))
+	value = strings.ReplaceAll(value, "мин", "")
+	value = strings.ReplaceAll(value, "х", "x")
+
+	intervalPart, repeatsPart, ok := strings.Cut(value, "x")
+	if !ok {
+		return 0, 0, false
+	}
+	interval, err := strconv.Atoi(intervalPart)
+	if err != nil || interval <= 0 {
+		return 0, 0, false
+	}
+	repeats, err := strconv.Atoi(repeatsPart)
+	if err != nil || repeats <= 0 {
+		return 0, 0, false
+	}
+	return interval, repeats, true
+}
+
+func formatNag(reminder *entities.Reminder) string {
+	return fmt.Sprintf("каждые %d мин, до %d раз", *reminder.NagIntervalMinutes, *reminder.NagMaxRepeats)
+}
+
+func formatCourse(reminder *entities.Reminder, loc *time.Location) string {
+	var parts []string
+	if reminder.StartsAt != nil {
+		parts = append(parts, "с "+reminder.StartsAt.In(loc).Format("02.01.2006"))
+	}
+	if reminder.EndsAt != nil {
+		parts = append(parts, "до "+reminder.EndsAt.In(loc).Format("02.01.2006 15:04"))
+	}
+	if reminder.MaxDoses != nil {
+		parts = append(parts, fmt.Sprintf("%d доз", *reminder.MaxDoses))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseWeeklySchedule parses "пн,ср,пт 08:00" or "пн-пт 08:00,20:00".
+func parseWeeklySchedule(value string) (entities.Weekdays, []string, bool) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return 0, nil, false
+	}
+
+	weekdays, ok := parseWeekdays(fields[0])
+	if !ok {
+		return 0, nil, false
+	}
+
+	times, ok := parseTimesOfDay(fields[1])
+	if !ok {
+		return 0, nil, false
+	}
+
+	return weekdays, times, true
+}
+
+func parseWeekdays(value string) (entities.Weekdays, bool) {
+	var weekdays entities.Weekdays
+	for _, part := range strings.Split(strings.ToLower(value), ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		from, ok := weekdayNames[bounds[0]]
+		if !ok {
+			return 0, false
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, ok = weekdayNames[bounds[1]]; !ok {
+				return 0, false
+			}
+		}
+		for day := from; ; day = (day + 1) % 7 {
+			weekdays |= entities.NewWeekdays(day)
+			if day == to {
+				break
+			}
+		}
+	}
+	return weekdays, !weekdays.IsEmpty()
+}
+
+func formatWeekdays(weekdays entities.Weekdays) string {
+	var names []string
+	for _, day := range weekdays.Days() {
+		names = append(names, weekdayShortNames[day])
+	}
+	return strings.Join(names, ", ")
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
 func (h *BotHandler) answerCallbackQuery(callbackID string, text string) {
 	callback := tgbotapi.NewCallback(callbackID, text)
 	if _, err := h.bot.Request(callback); err != nil {
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+)
+
+// caregiverInvitePrefix marks a /start deep-link payload as a caregiver
+// invite: https://t.me/<bot>?start=care_<token>.
+const caregiverInvitePrefix = "care_"
+
+func (h *BotHandler) handleCaregivers(ctx context.Context, chatID int64, telegramUserID int64, args string) {
+	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден. Попробуйте /start")
+		return
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) > 0 {
+		switch strings.ToLower(fields[0]) {
+		case "invite":
+			h.sendCaregiverInvite(ctx, chatID, user)
+			return
+		case "delay":
+			if len(fields) < 2 {
+				h.sendMessage(chatID, "Укажите задержку в минутах, например: /caregivers delay 60")
+				return
+			}
+			minutes, err := strconv.Atoi(fields[1])
+			if err != nil {
+				h.sendMessage(chatID, "Ошибка: задержка должна быть числом минут.")
+				return
+			}
+			if _, err := h.usecases.Caregiver.SetDelay(ctx, user.ID, minutes); err != nil {
+				h.sendMessage(chatID, fmt.Sprintf("Ошибка: %s", escapeMarkdown(err.Error())))
+				return
+			}
+			h.sendMessage(chatID, fmt.Sprintf("✅ Опекуны получат уведомление, если приём не подтверждён в течение %d мин.", minutes))
+			return
+		default:
+			h.sendMessage(chatID, "Неизвестная подкоманда. Используйте /caregivers, /caregivers invite или /caregivers delay 60")
+			return
+		}
+	}
+
+	caregivers, err := h.usecases.Caregiver.GetCaregivers(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get caregivers", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при получении списка опекунов.")
+		return
+	}
+	patients, err := h.usecases.Caregiver.GetPatients(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get patients", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при получении списка подопечных.")
+		return
+	}
+
+	var builder strings.Builder
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	builder.WriteString("👨‍👩‍👧 Опекуны\n\n")
+	if len(caregivers) == 0 {
+		builder.WriteString("У вас пока нет опекунов. Пригласите близкого человека командой /caregivers invite\n")
+	} else {
+		builder.WriteString("Ваши опекуны:\n")
+		for _, caregiver := range caregivers {
+			builder.WriteString(fmt.Sprintf("• %s\n", displayName(caregiver)))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("❌ Убрать "+displayName(caregiver), "cgremove:"+caregiver.ID.String()),
+			))
+		}
+		builder.WriteString(fmt.Sprintf("\nУведомление отправляется, если приём не подтверждён в течение %d мин. Изменить: /caregivers delay 60\n",
+			int(user.CaregiverDelay()/time.Minute)))
+	}
+
+	if len(patients) > 0 {
+		builder.WriteString("\nВы опекаете:\n")
+		for _, patient := range patients {
+			builder.WriteString(fmt.Sprintf("• %s\n", displayName(patient)))
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("📊 "+displayName(patient), "cgstats:"+patient.ID.String()),
+				tgbotapi.NewInlineKeyboardButtonData("🚪 Перестать опекать", "cgleave:"+patient.ID.String()),
+			))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, builder.String())
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+func (h *BotHandler) sendCaregiverInvite(ctx context.Context, chatID int64, user *entities.User) {
+	invite, err := h.usecases.Caregiver.CreateInvite(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to create caregiver invite", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при создании приглашения.")
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%s", h.bot.Self.UserName, caregiverInvitePrefix, invite.InviteToken)
+	text := fmt.Sprintf("Отправьте эту ссылку человеку, который будет получать уведомления о пропущенных приёмах:\n\n%s\n\nСсылка действует 7 дней и может быть использована один раз.", link)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.DisableWebPagePreview = true
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+func (h *BotHandler) handleCaregiverInvite(ctx context.Context, chatID int64, telegramUserID int64, token string) {
+	invite, err := h.usecases.Caregiver.GetInvite(ctx, token)
+	if err != nil {
+		h.sendMessage(chatID, "Приглашение не найдено или уже недействительно.")
+		return
+	}
+
+	patient, err := h.usecases.User.GetByID(ctx, invite.PatientID)
+	if err != nil || patient == nil {
+		h.sendMessage(chatID, "Приглашение не найдено или уже недействительно.")
+		return
+	}
+	if patient.TelegramID == telegramUserID {
+		h.sendMessage(chatID, "Это ваше собственное приглашение — отправьте ссылку близкому человеку.")
+		return
+	}
+
+	text := fmt.Sprintf("%s приглашает вас стать опекуном.\n\nВы будете получать уведомления, если приём лекарства не подтверждён вовремя, и сможете смотреть статистику приёма.", displayName(patient))
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Принять", "cgaccept:"+token),
+			tgbotapi.NewInlineKeyboardButtonData("✖️ Отклонить", "cgdecline:"+token),
+		),
+	)
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+func (h *BotHandler) handleCaregiverCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, action, arg string) {
+	chatID := callback.Message.Chat.ID
+
+	user, err := h.usecases.User.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		h.answerCallbackQuery(callback.ID, "Пользователь не найден")
+		return
+	}
+
+	switch action {
+	case "cgaccept":
+		invite, err := h.usecases.Caregiver.AcceptInvite(ctx, arg, user.ID)
+		if err != nil {
+			h.answerCallbackQuery(callback.ID, "Не удалось принять приглашение")
+			h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+			h.sendMessage(chatID, fmt.Sprintf("Не удалось принять приглашение: %s", escapeMarkdown(err.Error())))
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "✅ Приглашение принято")
+		h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+
+		patient, err := h.usecases.User.GetByID(ctx, invite.PatientID)
+		if err == nil && patient != nil {
+			h.sendMessage(chatID, fmt.Sprintf("Теперь вы опекун: %s. Статистика доступна в /stats и /caregivers.", displayName(patient)))
+			h.sendMessage(patient.TelegramID, fmt.Sprintf("👨‍👩‍👧 %s теперь ваш опекун.", displayName(user)))
+		}
+
+	case "cgdecline":
+		h.answerCallbackQuery(callback.ID, "Приглашение отклонено")
+		h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+
+	case "cgremove", "cgleave":
+		otherID, err := uuid.Parse(arg)
+		if err != nil {
+			h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+			return
+		}
+		patientID, caregiverID := user.ID, otherID
+		if action == "cgleave" {
+			patientID, caregiverID = otherID, user.ID
+		}
+		if err := h.usecases.Caregiver.Remove(ctx, patientID, caregiverID); err != nil {
+			h.answerCallbackQuery(callback.ID, "Связь не найдена")
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "Готово")
+		h.sendMessage(chatID, "Связь с опекуном удалена.")
+
+	case "cgstats":
+		patientID, err := uuid.Parse(arg)
+		if err != nil {
+			h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+			return
+		}
+		allowed, err := h.usecases.Caregiver.IsCaregiverOf(ctx, user.ID, patientID)
+		if err != nil || !allowed {
+			h.answerCallbackQuery(callback.ID, "Нет доступа к статистике")
+			return
+		}
+		patient, err := h.usecases.User.GetByID(ctx, patientID)
+		if err != nil || patient == nil {
+			h.answerCallbackQuery(callback.ID, "Пользователь не найден")
+			return
+		}
+		r, _ := presetStatsRange(defaultStatsPeriod, time.Now().In(patient.Location()))
+		text, err := h.buildStats(ctx, patient, r)
+		if err != nil {
+			h.logger.Error("failed to get statistics", zap.Error(err))
+			h.answerCallbackQuery(callback.ID, "Ошибка при получении статистики")
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "")
+		h.sendMessage(chatID, fmt.Sprintf("👤 %s\n\n%s", displayName(patient), text))
+
+	default:
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+	}
+}
+
+// SendCaregiverAlert tells a caregiver that the patient has not confirmed a
+// dose in time.
+func (h *BotHandler) SendCaregiverAlert(ctx context.Context, caregiver *entities.User, reminder *entities.Reminder, execution *entities.ReminderExecution) error {
+	patient, err := h.usecases.User.GetByID(ctx, execution.UserID)
+	if err != nil || patient == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	deliveredAt := execution.SentAt
+	if execution.DeliveredAt != nil {
+		deliveredAt = *execution.DeliveredAt
+	}
+
+	text := fmt.Sprintf("⚠️ %s не подтвердил(а) приём *%s*.\n\nНапоминание было доставлено в %s, прошло %s.",
+		displayName(patient),
+		reminder.Title,
+		deliveredAt.In(caregiver.Location()).Format("02.01 15:04"),
+		formatLateness(time.Since(deliveredAt)),
+	)
+
+	msg := tgbotapi.NewMessage(caregiver.TelegramID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := h.bot.Send(msg); err != nil {
+		return fmt.Errorf("failed to send caregiver alert: %w", err)
+	}
+
+	return nil
+}
+
+func patientStatsKeyboard(patients []*entities.User) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(patients))
+	for _, patient := range patients {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 "+displayName(patient), "cgstats:"+patient.ID.String()),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func displayName(user *entities.User) string {
+	name := user.FirstName
+	if user.LastName != nil && *user.LastName != "" {
+		name += " " + *user.LastName
+	}
+	if name == "" && user.Username != nil {
+		name = "@" + *user.Username
+	}
+	return name
+}
+
+func formatLateness(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	if hours == 0 {
+		return fmt.Sprintf("%d мин", minutes)
+	}
+	return fmt.Sprintf("%d ч %d мин", hours, minutes)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+)
+
+const digestUsage = "Использование:\n" +
+	"/digest вс 20:00 - присылать итоги недели по воскресеньям в 20:00\n" +
+	"/digest off - отключить итоги недели"
+
+func (h *BotHandler) handleDigest(ctx context.Context, chatID int64, telegramUserID int64, args string) {
+	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден. Попробуйте /start")
+		return
+	}
+
+	fields := strings.Fields(strings.ToLower(args))
+	switch {
+	case len(fields) == 0:
+		if !user.HasDigest() {
+			h.sendMessage(chatID, "📬 Итоги недели отключены.\n\n"+digestUsage)
+			return
+		}
+		h.sendMessage(chatID, fmt.Sprintf("📬 Итоги недели приходят: %s\n\n%s", formatDigestSchedule(user), digestUsage))
+
+	case len(fields) == 1 && (fields[0] == "off" || fields[0] == "выкл"):
+		if _, err := h.usecases.Digest.Disable(ctx, user.ID); err != nil {
+			h.logger.Error("failed to disable digest", zap.Error(err))
+			h.sendMessage(chatID, "Не удалось отключить итоги недели.")
+			return
+		}
+		h.sendMessage(chatID, "📭 Итоги недели отключены.")
+
+	case len(fields) == 2:
+		weekday, ok := weekdayNames[fields[0]]
+		if !ok {
+			h.sendMessage(chatID, digestUsage)
+			return
+		}
+		if _, ok := parseTimesOfDay(fields[1]); !ok {
+			h.sendMessage(chatID, digestUsage)
+			return
+		}
+		user, err = h.usecases.Digest.Enable(ctx, user.ID, weekday, fields[1])
+		if err != nil {
+			h.logger.Error("failed to enable digest", zap.Error(err))
+			h.sendMessage(chatID, "Не удалось включить итоги недели.")
+			return
+		}
+		h.sendMessage(chatID, fmt.Sprintf("📬 Итоги недели будут приходить: %s\nБлижайшие — %s.",
+			formatDigestSchedule(user), user.DigestNextAt.In(user.Location()).Format("02.01.2006 15:04")))
+
+	default:
+		h.sendMessage(chatID, digestUsage)
+	}
+}
+
+func formatDigestSchedule(user *entities.User) string {
+	return fmt.Sprintf("%s в %s", weekdayShortNames[*user.DigestWeekday], *user.DigestTime)
+}
+
+// SendDigest sends the user the summary of the past week.
+func (h *BotHandler) SendDigest(ctx context.Context, user *entities.User, digest *usecases.Digest) error {
+	msg := tgbotapi.NewMessage(user.TelegramID, formatDigest(digest))
+	if _, err := h.bot.Send(msg); err != nil {
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+	return nil
+}
+
+func formatDigest(digest *usecases.Digest) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("📬 Итоги недели %s–%s\n\n", digest.From.Format("02.01"), digest.To.Format("02.01")))
+
+	week, previous := digest.Week, digest.PreviousWeek
+	if week.TotalDelivered == 0 {
+		builder.WriteString("За неделю приёмов не было.\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("Принято %d из %d (%.1f%%)\n", week.TotalConfirmed, week.TotalDelivered, week.AdherenceRate))
+		builder.WriteString(fmt.Sprintf("Пропущено: %d, без ответа: %d\n", week.TotalSkipped, week.TotalMissed+week.TotalPending))
+		builder.WriteString(formatDigestComparison(week, previous) + "\n")
+	}
+
+	if len(digest.Reminders) > 0 {
+		builder.WriteString("\n💊 По напоминаниям:\n")
+		for _, item := range digest.Reminders {
+			builder.WriteString(fmt.Sprintf("%s — принято %d, пропущено %d, без ответа %d (неделей раньше: %d/%d/%d)\n",
+				item.Reminder.Title,
+				item.Week.TotalConfirmed, item.Week.TotalSkipped, item.Week.TotalMissed+item.Week.TotalPending,
+				item.PreviousWeek.TotalConfirmed, item.PreviousWeek.TotalSkipped, item.PreviousWeek.TotalMissed+item.PreviousWeek.TotalPending,
+			))
+		}
+	}
+
+	if len(digest.CourseEnds) > 0 {
+		builder.WriteString("\n🏁 Скоро заканчивается курс:\n")
+		for _, reminder := range digest.CourseEnds {
+			// EndsAt is the start of the day after the last one.
+			lastDay := reminder.EndsAt.In(digest.To.Location()).Add(-time.Minute)
+			builder.WriteString(fmt.Sprintf("%s — последний день %s\n", reminder.Title, lastDay.Format("02.01")))
+		}
+	}
+
+	if len(digest.LowStock) > 0 {
+		builder.WriteString("\n🛒 Скоро закончится:\n")
+		for _, low := range digest.LowStock {
+			builder.WriteString(fmt.Sprintf("%s — осталось %s, примерно на %d дн.\n",
+				low.Reminder.Title, formatAmount(low.Stock.OnHand), int(math.Floor(low.DaysLeft))))
+		}
+	}
+
+	builder.WriteString("\nОтключить итоги недели: /digest off")
+	return builder.String()
+}
+
+func formatDigestComparison(week, previous *repository.ExecutionStatistics) string {
+	if previous.TotalDelivered == 0 {
+		return "Неделей раньше приёмов не было."
+	}
+	diff := week.AdherenceRate - previous.AdherenceRate
+	switch {
+	case math.Abs(diff) < 0.05:
+		return fmt.Sprintf("Так же, как неделей раньше (%.1f%%).", previous.AdherenceRate)
+	case diff > 0:
+		return fmt.Sprintf("📈 На %.1f п.п. лучше, чем неделей раньше (%.1f%%).", diff, previous.AdherenceRate)
+	default:
+		return fmt.Sprintf("📉 На %.1f п.п. хуже, чем неделей раньше (%.1f%%).", -diff, previous.AdherenceRate)
+	}
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+)
+
+// Dose logging buttons carry "took:<reminderID>" to log a dose now, after
+// checking the limits of an as-needed medicine, and
+// "took:<reminderID>:<unix time>" to log it despite a warning.
+const tookCallbackPrefix = "took"
+
+const tookCancel = "cancel"
+
+const tookUsage = "Отметить принятое лекарство:\n" +
+	"/took — выбрать напоминание, приём запишется текущим временем\n" +
+	"/took 1 — напоминание №1 из /list, принято сейчас\n" +
+	"/took 1 08:30 — принято в 08:30 (если это время ещё не наступило — вчера)\n" +
+	"Вместо номера можно указать название напоминания."
+
+// handleTook logs a dose the user has taken, possibly some time ago.
+func (h *BotHandler) handleTook(ctx context.Context, chatID int64, telegramUserID int64, args string) {
+	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден.")
+		return
+	}
+
+	reminders, err := h.usecases.Reminder.GetByUserID(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get reminders", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при получении списка напоминаний.")
+		return
+	}
+	if len(reminders) == 0 {
+		h.sendMessage(chatID, "У вас пока нет напоминаний. Создайте первое с помощью /new")
+		return
+	}
+
+	args = strings.TrimSpace(args)
+	if args == "" {
+		rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(reminders))
+		for i, reminder := range reminders {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("💊 %d. %s", i+1, reminder.Title), tookCallbackPrefix+":"+reminder.ID.String()),
+			))
+		}
+		msg := tgbotapi.NewMessage(chatID, tookUsage+"\n\nЧто вы приняли?")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+		if _, err := h.bot.Send(msg); err != nil {
+			h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+		}
+		return
+	}
+
+	loc := user.Location()
+	now := time.Now().In(loc)
+	takenAt := now
+	query := args
+	if i := strings.LastIndex(args, " "); i > 0 {
+		if at, ok := parseTakenAt(args[i+1:], now); ok {
+			takenAt = at
+			query = strings.TrimSpace(args[:i])
+		}
+	}
+
+	reminder := findReminder(reminders, query)
+	if reminder == nil {
+		h.sendMessage(chatID, fmt.Sprintf("Напоминание «%s» не найдено.\n\n%s", query, tookUsage))
+		return
+	}
+
+	h.logDose(ctx, chatID, user, reminder, takenAt, false)
+}
+
+func (h *BotHandler) handleTookCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, parts []string) {
+	chatID := callback.Message.Chat.ID
+	reminderID, err := uuid.Parse(parts[1])
+	if err != nil {
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+		return
+	}
+
+	user, reminder, ok := h.ownedReminder(ctx, callback.From.ID, reminderID)
+	if !ok {
+		h.answerCallbackQuery(callback.ID, "Напоминание не найдено")
+		return
+	}
+
+	takenAt, force := time.Now(), false
+	if len(parts) >= 3 {
+		if parts[2] == tookCancel {
+			h.answerCallbackQuery(callback.ID, "Приём не записан")
+			h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+			return
+		}
+		unix, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+			return
+		}
+		takenAt, force = time.Unix(unix, 0), true
+	}
+
+	h.answerCallbackQuery(callback.ID, "")
+	if len(parts) >= 3 {
+		h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+	}
+	h.logDose(ctx, chatID, user, reminder, takenAt, force)
+}
+
+// logDose records a dose taken at takenAt. A dose that breaks the limits of
+// an as-needed medicine is only recorded with force; otherwise the user is
+// warned and asked to confirm.
+func (h *BotHandler) logDose(ctx context.Context, chatID int64, user *entities.User, reminder *entities.Reminder, takenAt time.Time, force bool) {
+	loc := user.Location()
+	if reminder.IsAsNeeded() && reminder.HasDoseLimits() && !force {
+		limits, err := h.usecases.ReminderExecution.CheckDoseLimits(ctx, reminder, takenAt)
+		if err != nil {
+			h.logger.Error("failed to check dose limits", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+			h.sendMessage(chatID, "Не удалось проверить ограничения приёма, попробуйте ещё раз.")
+			return
+		}
+		if limits.Exceeded() {
+			id := reminder.ID.String()
+			msg := tgbotapi.NewMessage(chatID, formatDoseLimitsWarning(reminder, limits, loc))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Всё равно записать", tookCallbackPrefix+":"+id+":"+strconv.FormatInt(takenAt.Unix(), 10)),
+				tgbotapi.NewInlineKeyboardButtonData("Не записывать", tookCallbackPrefix+":"+id+":"+tookCancel),
+			))
+			if _, err := h.bot.Send(msg); err != nil {
+				h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+			}
+			return
+		}
+	}
+
+	execution, err := h.usecases.ReminderExecution.LogDose(ctx, user.ID, reminder, takenAt)
+	switch {
+	case errors.Is(err, usecases.ErrInvalidTakenAt):
+		h.sendMessage(chatID, fmt.Sprintf("Время приёма не может быть в будущем или раньше чем %d дней назад.", int(usecases.MaxBackdate.Hours()/24)))
+		return
+	case err != nil && execution == nil:
+		h.logger.Error("failed to log dose", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+		h.sendMessage(chatID, "Не удалось записать приём, попробуйте ещё раз.")
+		return
+	case err != nil:
+		h.logger.Error("failed to log dose", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+	}
+
+	text := fmt.Sprintf("✅ Записал приём «%s» %s.", reminder.Title, formatAnswerTime(takenAt, loc))
+	if !execution.SentAt.Equal(takenAt) {
+		text += fmt.Sprintf("\nОтмечено напоминание от %s", execution.SentAt.In(loc).Format("15:04"))
+		if execution.IsLate() {
+			text += " — с опозданием"
+		}
+		text += "."
+	}
+	h.sendMessage(chatID, text)
+}
+
+// parseTakenAt reads an HH:MM intake time relative to now: the latest such
+// moment that is not in the future.
+func parseTakenAt(value string, now time.Time) (time.Time, bool) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	takenAt := time.Date(now.Year(), now.Month(), now.Day(), parsed.Hour(), parsed.Minute(), 0, 0, now.Location())
+	if takenAt.After(now) {
+		takenAt = takenAt.AddDate(0, 0, -1)
+	}
+	return takenAt, true
+}
+
+func formatAnswerTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("02.01 в 15:04")
+}
+
+// takenAtKeyboard offers intake times for a dose taken before the button
+// was pressed.
+func takenAtKeyboard(executionID uuid.UUID) tgbotapi.InlineKeyboardMarkup {
+	id := executionID.String()
+	button := func(label string, minutesAgo int) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(label, "tookat:"+id+":"+strconv.Itoa(minutesAgo))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			button("15 мин назад", 15),
+			button("30 мин назад", 30),
+			button("1 ч назад", 60),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			button("2 ч назад", 120),
+			button("3 ч назад", 180),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", "snoozeback:"+id),
+		),
+	)
+}
+
+// parseDoseLimits parses the limits of an as-needed medicine: "4 3" means at
+// least 4 hours between doses and at most 3 doses per 24 hours. Either part
+// may be 0 and the count may be left out; "-" means no limits.
+func parseDoseLimits(value string) (*int, *int, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "-" {
+		return nil, nil, true
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) > 2 {
+		return nil, nil, false
+	}
+
+	hoursValue := strings.TrimRight(strings.ToLower(fields[0]), "чh")
+	hours, err := strconv.ParseFloat(strings.ReplaceAll(hoursValue, ",", "."), 64)
+	if err != nil || hours < 0 || hours > 24 {
+		return nil, nil, false
+	}
+	var minInterval *int
+	if minutes := int(hours * 60); minutes > 0 {
+		minInterval = &minutes
+	}
+
+	var maxPerDay *int
+	if len(fields) == 2 {
+		count, err := strconv.Atoi(fields[1])
+		if err != nil || count < 0 || count > usecases.MaxDosesPerDayLimit {
+			return nil, nil, false
+		}
+		if count > 0 {
+			maxPerDay = &count
+		}
+	}
+	return minInterval, maxPerDay, true
+}
+
+func formatDoseLimits(reminder *entities.Reminder) string {
+	if !reminder.HasDoseLimits() {
+		return "без ограничений"
+	}
+
+	var limits []string
+	if reminder.MinIntervalMinutes != nil {
+		limits = append(limits, "не чаще чем раз в "+formatMinutes(*reminder.MinIntervalMinutes))
+	}
+	if reminder.MaxDosesPerDay != nil {
+		limits = append(limits, fmt.Sprintf("не больше %d раз за сутки", *reminder.MaxDosesPerDay))
+	}
+	return strings.Join(limits, ", ")
+}
+
+func formatMinutes(minutes int) string {
+	switch {
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d ч", minutes/60)
+	case minutes > 60:
+		return fmt.Sprintf("%d ч %d мин", minutes/60, minutes%60)
+	default:
+		return fmt.Sprintf("%d мин", minutes)
+	}
+}
+
+func formatDoseLimitsWarning(reminder *entities.Reminder, limits *usecases.DoseLimits, loc *time.Location) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("⚠️ %s: ", reminder.Title))
+	switch {
+	case limits.TooSoon && limits.OverDailyMax:
+		builder.WriteString("прошло меньше минимального интервала и достигнут максимум приёмов за сутки.")
+	case limits.TooSoon:
+		builder.WriteString("с прошлого приёма прошло меньше минимального интервала.")
+	default:
+		builder.WriteString("достигнут максимум приёмов за сутки.")
+	}
+	builder.WriteString(fmt.Sprintf("\n\nОграничения: %s.", formatDoseLimits(reminder)))
+	if limits.LastTakenAt != nil {
+		builder.WriteString(fmt.Sprintf("\nПоследний приём: %s.", formatAnswerTime(*limits.LastTakenAt, loc)))
+	}
+	builder.WriteString(fmt.Sprintf("\nЗа последние сутки: %d.", limits.TakenInDay))
+	builder.WriteString(fmt.Sprintf("\nСледующий приём можно %s.", formatAnswerTime(limits.NextAllowedAt, loc)))
+	builder.WriteString("\n\nВсё равно записать приём?")
+	return builder.String()
+}
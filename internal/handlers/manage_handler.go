@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+)
+
+// Reminder management buttons carry "rem:<action>:<reminderID>".
+const reminderCallbackPrefix = "rem"
+
+// reminderHistoryLimit is how many recent doses the history button shows.
+const reminderHistoryLimit = 10
+
+func reminderCallback(action string, reminderID uuid.UUID) string {
+	return reminderCallbackPrefix + ":" + action + ":" + reminderID.String()
+}
+
+func (h *BotHandler) handleReminderCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, parts []string) {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	if len(parts) != 3 {
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+		return
+	}
+	action := parts[1]
+	reminderID, err := uuid.Parse(parts[2])
+	if err != nil {
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+		return
+	}
+
+	user, reminder, ok := h.ownedReminder(ctx, callback.From.ID, reminderID)
+	if !ok {
+		h.answerCallbackQuery(callback.ID, "Напоминание не найдено")
+		h.editReplyMarkup(chatID, messageID, emptyKeyboard())
+		return
+	}
+	loc := user.Location()
+
+	switch action {
+	case "open":
+		h.answerCallbackQuery(callback.ID, "")
+		msg := tgbotapi.NewMessage(chatID, formatReminderCard(reminder, loc))
+		msg.ReplyMarkup = reminderCardKeyboard(reminder)
+		if _, err := h.bot.Send(msg); err != nil {
+			h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+		}
+
+	case "card":
+		h.answerCallbackQuery(callback.ID, "")
+		h.editMessage(chatID, messageID, formatReminderCard(reminder, loc), reminderCardKeyboard(reminder))
+
+	case "pause", "resume":
+		isActive := action == "resume"
+		updated, err := h.usecases.Reminder.Update(ctx, reminder.ID, usecases.UpdateReminderInput{IsActive: &isActive})
+		if err != nil {
+			h.logger.Error("failed to update reminder", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+			h.answerCallbackQuery(callback.ID, "Не удалось изменить напоминание")
+			return
+		}
+		if isActive {
+			h.answerCallbackQuery(callback.ID, "▶️ Напоминание возобновлено")
+		} else {
+			h.answerCallbackQuery(callback.ID, "⏸ Напоминание приостановлено")
+		}
+		h.editMessage(chatID, messageID, formatReminderCard(updated, loc), reminderCardKeyboard(updated))
+
+	case "edit":
+		h.answerCallbackQuery(callback.ID, "")
+		h.editReplyMarkup(chatID, messageID, reminderEditKeyboard(reminder))
+
+	case "etitle", "esched", "ecomment", "ephoto":
+		step := map[string]entities.ConversationStep{
+			"etitle":   entities.ConversationStepTitle,
+			"esched":   entities.ConversationStepType,
+			"ecomment": entities.ConversationStepComment,
+			"ephoto":   entities.ConversationStepPhoto,
+		}[action]
+		conversation, err := h.usecases.Conversation.StartEdit(ctx, chatID, reminder, step)
+		if err != nil {
+			h.logger.Error("failed to start conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+			h.answerCallbackQuery(callback.ID, "Ошибка, попробуйте ещё раз")
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "")
+		h.editReplyMarkup(chatID, messageID, reminderCardKeyboard(reminder))
+		h.promptWizardStep(chatID, conversation)
+
+	case "del":
+		h.answerCallbackQuery(callback.ID, "")
+		h.editReplyMarkup(chatID, messageID, tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🗑 Да, удалить", reminderCallback("delok", reminder.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("Нет", reminderCallback("card", reminder.ID)),
+			),
+		))
+
+	case "delok":
+		if err := h.usecases.Reminder.Delete(ctx, reminder.ID); err != nil {
+			h.logger.Error("failed to delete reminder", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+			h.answerCallbackQuery(callback.ID, "Не удалось удалить напоминание")
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "🗑 Удалено")
+		h.editMessage(chatID, messageID, fmt.Sprintf("🗑 Напоминание «%s» удалено.", reminder.Title), emptyKeyboard())
+
+	case "history":
+		executions, err := h.usecases.ReminderExecution.GetHistoryByReminderID(ctx, reminder.ID, reminderHistoryLimit)
+		if err != nil {
+			h.logger.Error("failed to get history", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+			h.answerCallbackQuery(callback.ID, "Ошибка при получении истории")
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "")
+		h.sendMessage(chatID, formatReminderHistory(reminder, executions, loc))
+
+	default:
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+	}
+}
+
+// ownedReminder loads a reminder on behalf of a Telegram user and reports
+// whether it exists and belongs to them.
+func (h *BotHandler) ownedReminder(ctx context.Context, telegramUserID int64, reminderID uuid.UUID) (*entities.User, *entities.Reminder, bool) {
+	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
+	if err != nil || user == nil {
+		return nil, nil, false
+	}
+
+	reminder, err := h.usecases.Reminder.GetOwned(ctx, user.ID, reminderID)
+	switch {
+	case errors.Is(err, usecases.ErrForbidden):
+		h.logger.Warn("reminder access denied",
+			zap.Int64("telegram_user_id", telegramUserID),
+			zap.String("reminder_id", reminderID.String()),
+		)
+		return nil, nil, false
+	case errors.Is(err, usecases.ErrNotFound):
+		return nil, nil, false
+	case err != nil:
+		h.logger.Error("failed to get reminder", zap.Error(err), zap.String("reminder_id", reminderID.String()))
+		return nil, nil, false
+	}
+
+	return user, reminder, true
+}
+
+func (h *BotHandler) editMessage(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	if _, err := h.bot.Send(edit); err != nil {
+		h.logger.Error("failed to edit message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+func formatReminderCard(reminder *entities.Reminder, loc *time.Location) string {
+	return fmt.Sprintf("💊 %s\n%s", reminder.Title, formatReminderDetails(reminder, loc))
+}
+
+func reminderCardKeyboard(reminder *entities.Reminder) tgbotapi.InlineKeyboardMarkup {
+	toggle := tgbotapi.NewInlineKeyboardButtonData("⏸ Пауза", reminderCallback("pause", reminder.ID))
+	if !reminder.IsActive {
+		toggle = tgbotapi.NewInlineKeyboardButtonData("▶️ Возобновить", reminderCallback("resume", reminder.ID))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if reminder.IsAsNeeded() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💊 Принять", tookCallbackPrefix+":"+reminder.ID.String()),
+		))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			toggle,
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить", reminderCallback("edit", reminder.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📜 История", reminderCallback("history", reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", reminderCallback("del", reminder.ID)),
+		),
+	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func reminderEditKeyboard(reminder *entities.Reminder) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 Название", reminderCallback("etitle", reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Расписание", reminderCallback("esched", reminder.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💬 Комментарий", reminderCallback("ecomment", reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🖼 Фото", reminderCallback("ephoto", reminder.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", reminderCallback("card", reminder.ID)),
+		),
+	)
+}
+
+func formatReminderHistory(reminder *entities.Reminder, executions []*entities.ReminderExecution, loc *time.Location) string {
+	if len(executions) == 0 {
+		return fmt.Sprintf("📜 %s\n\nНапоминание ещё ни разу не отправлялось.", reminder.Title)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("📜 %s — последние приёмы:\n\n", reminder.Title))
+	for _, execution := range executions {
+		label := executionStatusLabel(execution.Status)
+		if execution.TakenAt != nil && execution.Status == entities.ExecutionStatusConfirmed {
+			label += " в " + execution.TakenAt.In(loc).Format("15:04")
+			if execution.IsLate() {
+				label += ", с опозданием"
+			}
+		}
+		builder.WriteString(fmt.Sprintf("%s — %s\n", execution.SentAt.In(loc).Format("02.01 15:04"), label))
+	}
+	return builder.String()
+}
+
+func executionStatusLabel(status entities.ExecutionStatus) string {
+	switch status {
+	case entities.ExecutionStatusConfirmed:
+		return "✅ принято"
+	case entities.ExecutionStatusSkipped:
+		return "⏭ пропущено"
+	case entities.ExecutionStatusSnoozed:
+		return "⏰ отложено"
+	case entities.ExecutionStatusMissed:
+		return "❗ пропущено без ответа"
+	default:
+		return "❔ без ответа"
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+)
+
+// handlePhoto attaches a photo sent outside the wizard to the reminder named
+// in its caption, either by its number in /list or by its title.
+func (h *BotHandler) handlePhoto(ctx context.Context, msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	user, err := h.usecases.User.GetByTelegramID(ctx, int64(msg.From.ID))
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден.")
+		return
+	}
+
+	caption := strings.TrimSpace(msg.Caption)
+	if caption == "" {
+		h.sendMessage(chatID, "Чтобы прикрепить фото к напоминанию, отправьте его с подписью — номером напоминания из /list или его названием. "+
+			"Также фото можно заменить кнопкой «✏️ Изменить» → «🖼 Фото» в карточке напоминания.")
+		return
+	}
+
+	reminders, err := h.usecases.Reminder.GetByUserID(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get reminders", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при получении списка напоминаний.")
+		return
+	}
+
+	reminder := findReminder(reminders, caption)
+	if reminder == nil {
+		h.sendMessage(chatID, fmt.Sprintf("Напоминание «%s» не найдено. Укажите в подписи номер из /list или точное название.", caption))
+		return
+	}
+
+	fileID, _ := photoFileID(msg)
+	url := ""
+	updated, err := h.usecases.Reminder.Update(ctx, reminder.ID, usecases.UpdateReminderInput{
+		ImageFileID: &fileID,
+		ImageURL:    &url,
+	})
+	if err != nil {
+		h.logger.Error("failed to update reminder", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+		h.sendMessage(chatID, "Не удалось сохранить фото.")
+		return
+	}
+
+	msgText := fmt.Sprintf("🖼 Фото прикреплено к напоминанию «%s».", updated.Title)
+	if hasImage(reminder) {
+		msgText = fmt.Sprintf("🖼 Фото напоминания «%s» заменено.", updated.Title)
+	}
+	h.sendMessage(chatID, msgText)
+}
+
+// findReminder picks a reminder by its 1-based position in /list or by a
+// case-insensitive title match.
+func findReminder(reminders []*entities.Reminder, query string) *entities.Reminder {
+	if number, err := strconv.Atoi(query); err == nil {
+		if number < 1 || number > len(reminders) {
+			return nil
+		}
+		return reminders[number-1]
+	}
+
+	for _, reminder := range reminders {
+		if strings.EqualFold(reminder.Title, query) {
+			return reminder
+		}
+	}
+	return nil
+}
+
+// photoFileID returns the file_id of the largest size of a photo message.
+// Images sent as files are not accepted: Telegram refuses to send a document
+// file_id as a photo.
+func photoFileID(msg *tgbotapi.Message) (string, bool) {
+	if len(msg.Photo) == 0 {
+		return "", false
+	}
+	return msg.Photo[len(msg.Photo)-1].FileID, true
+}
+
+func hasImage(reminder *entities.Reminder) bool {
+	return (reminder.ImageFileID != nil && *reminder.ImageFileID != "") ||
+		(reminder.ImageURL != nil && *reminder.ImageURL != "")
+}
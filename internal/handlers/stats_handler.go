@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/chart"
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+)
+
+// Period buttons under /stats carry "stats:<period>".
+const statsCallbackPrefix = "stats"
+
+const (
+	statsCustomPeriod  = "custom"
+	defaultStatsPeriod = "30d"
+	maxStatsRangeDays  = 366
+)
+
+var statsPeriods = []struct {
+	key   string
+	label string
+	days  int
+}{
+	{"7d", "7 дней", 7},
+	{"30d", "30 дней", 30},
+	{"90d", "90 дней", 90},
+}
+
+const statsUsage = "Укажите период в днях (/stats 7d, 30d или 90d) или диапазон дат, например:\n/stats 01.05.2026-31.05.2026"
+
+// statsRange is the span of the user's local calendar a report covers. Key
+// is the preset period it came from, empty for a custom range.
+type statsRange struct {
+	key  string
+	from time.Time
+	to   time.Time
+}
+
+func (r statsRange) title() string {
+	for _, period := range statsPeriods {
+		if period.key == r.key {
+			return "за последние " + period.label
+		}
+	}
+	return fmt.Sprintf("с %s по %s", r.from.Format("02.01.2006"), r.to.Format("02.01.2006"))
+}
+
+// presetStatsRange returns the preset period ending now, or false if key
+// names none.
+func presetStatsRange(key string, now time.Time) (statsRange, bool) {
+	for _, period := range statsPeriods {
+		if period.key == key {
+			return statsRange{key: key, from: startOfDay(now).AddDate(0, 0, -(period.days - 1)), to: now}, true
+		}
+	}
+	return statsRange{}, false
+}
+
+// parseStatsRange parses the /stats argument: a preset period such as "7d"
+// or "7", or a date range "01.05.2026-31.05.2026". A range reaching past
+// today ends now.
+func parseStatsRange(value string, now time.Time) (statsRange, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		value = defaultStatsPeriod
+	}
+	if r, ok := presetStatsRange(value, now); ok {
+		return r, true
+	}
+	if r, ok := presetStatsRange(value+"d", now); ok {
+		return r, true
+	}
+
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return statsRange{}, false
+	}
+	start, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(from), now.Location())
+	if err != nil {
+		return statsRange{}, false
+	}
+	last, err := time.ParseInLocation("02.01.2006", strings.TrimSpace(to), now.Location())
+	if err != nil || last.Before(start) || start.After(now) || last.Sub(start) >= maxStatsRangeDays*24*time.Hour {
+		return statsRange{}, false
+	}
+
+	end := last.AddDate(0, 0, 1).Add(-time.Second)
+	if end.After(now) {
+		end = now
+	}
+	return statsRange{from: start, to: end}, true
+}
+
+func (h *BotHandler) handleStats(ctx context.Context, chatID int64, telegramUserID int64, args string) {
+	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден.")
+		return
+	}
+
+	r, ok := parseStatsRange(args, time.Now().In(user.Location()))
+	if !ok {
+		h.sendMessage(chatID, statsUsage)
+		return
+	}
+
+	text, err := h.buildStats(ctx, user, r)
+	if err != nil {
+		h.logger.Error("failed to get statistics", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при получении статистики.")
+		return
+	}
+
+	h.sendStatsChart(ctx, chatID, user, r)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = h.statsKeyboard(ctx, user, r.key)
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+func (h *BotHandler) handleStatsCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, key string) {
+	chatID := callback.Message.Chat.ID
+
+	if key == statsCustomPeriod {
+		h.answerCallbackQuery(callback.ID, "")
+		h.sendMessage(chatID, "Отправьте период командой, например:\n/stats 01.05.2026-31.05.2026")
+		return
+	}
+
+	user, err := h.usecases.User.GetByTelegramID(ctx, callback.From.ID)
+	if err != nil || user == nil {
+		h.answerCallbackQuery(callback.ID, "Пользователь не найден, отправьте /start")
+		return
+	}
+
+	r, ok := presetStatsRange(key, time.Now().In(user.Location()))
+	if !ok {
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+		return
+	}
+
+	text, err := h.buildStats(ctx, user, r)
+	if err != nil {
+		h.logger.Error("failed to get statistics", zap.Error(err))
+		h.answerCallbackQuery(callback.ID, "Ошибка при получении статистики")
+		return
+	}
+
+	h.answerCallbackQuery(callback.ID, "")
+	h.editMessage(chatID, callback.Message.MessageID, text, h.statsKeyboard(ctx, user, r.key))
+	h.sendStatsChart(ctx, chatID, user, r)
+}
+
+const statsChartLegend = "🟩 всё принято  🟨 частично  🟥 ничего не принято  ⬜ приёмов не было  🟦 ждут ответа\n" +
+	"Полоса справа — доля принятых за неделю, отметка — 80%."
+
+// sendStatsChart sends the adherence heatmap of r as a photo. Nothing is
+// sent when the period had no doses; a failed chart only gets logged, the
+// report itself goes out as text anyway.
+func (h *BotHandler) sendStatsChart(ctx context.Context, chatID int64, user *entities.User, r statsRange) {
+	adherence, err := h.usecases.ReminderExecution.GetDailyAdherence(ctx, user.ID, r.from, r.to)
+	if err != nil {
+		h.logger.Error("failed to get daily adherence", zap.Error(err))
+		return
+	}
+
+	days := make([]chart.Day, 0, len(adherence))
+	hasDoses := false
+	for _, day := range adherence {
+		days = append(days, chart.Day{Date: day.Date, Taken: day.Confirmed, Open: day.Pending, Total: day.Total()})
+		hasDoses = hasDoses || day.Total() > 0
+	}
+	if !hasDoses {
+		return
+	}
+
+	data, err := chart.Render(days)
+	if err != nil {
+		h.logger.Error("failed to render chart", zap.Error(err))
+		return
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "stats.png", Bytes: data})
+	photo.Caption = fmt.Sprintf("📅 Приём %s\n\n%s", r.title(), statsChartLegend)
+	if _, err := h.bot.Send(photo); err != nil {
+		h.logger.Error("failed to send chart", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+// statsKeyboard offers the preset periods, marking the current one, and
+// the statistics of the user's patients.
+func (h *BotHandler) statsKeyboard(ctx context.Context, user *entities.User, current string) tgbotapi.InlineKeyboardMarkup {
+	var periodRow []tgbotapi.InlineKeyboardButton
+	for _, period := range statsPeriods {
+		label := period.label
+		if period.key == current {
+			label = "✅ " + label
+		}
+		periodRow = append(periodRow, tgbotapi.NewInlineKeyboardButtonData(label, statsCallbackPrefix+":"+period.key))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		periodRow,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 Свой период", statsCallbackPrefix+":"+statsCustomPeriod),
+		),
+	}
+
+	patients, err := h.usecases.Caregiver.GetPatients(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get patients", zap.Error(err))
+	}
+	if len(patients) > 0 {
+		rows = append(rows, patientStatsKeyboard(patients).InlineKeyboard...)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// buildStats renders the /stats report for the user over r: the totals,
+// adherence streaks and a breakdown by reminder and by time of intake.
+func (h *BotHandler) buildStats(ctx context.Context, user *entities.User, r statsRange) (string, error) {
+	stats, err := h.usecases.ReminderExecution.GetStatisticsByUserID(ctx, user.ID, r.from, r.to)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(
+		"📊 Статистика %s:\n\n"+
+			"Доставлено: %d\n"+
+			"Принято: %d\n"+
+			"Пропущено: %d\n"+
+			"Без ответа (пропущено): %d\n"+
+			"Ждут ответа: %d\n"+
+			"Откладываний: %d\n"+
+			"Соблюдение режима: %.1f%%",
+		r.title(),
+		stats.TotalDelivered,
+		stats.TotalConfirmed,
+		stats.TotalSkipped,
+		stats.TotalMissed,
+		stats.TotalPending,
+		stats.TotalSnoozed,
+		stats.AdherenceRate,
+	))
+	if stats.TotalConfirmed > 0 {
+		builder.WriteString(fmt.Sprintf("\nВовремя (в течение %d мин): %.1f%%\nС опозданием: %.1f%%",
+			int(entities.OnTimeWindow.Minutes()), stats.OnTimeRate, stats.LateRate))
+	}
+
+	streaks, err := h.usecases.ReminderExecution.GetStreaks(ctx, user.ID, time.Now().In(user.Location()))
+	if err != nil {
+		h.logger.Error("failed to get streaks", zap.Error(err))
+	} else {
+		builder.WriteString(fmt.Sprintf("\n\n🔥 Серия без пропусков: %d дн. (лучшая — %d дн.)", streaks.Current, streaks.Longest))
+	}
+
+	reminders, err := h.usecases.Reminder.GetByUserID(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get reminders", zap.Error(err))
+	}
+
+	var reminderBuilder, asNeededBuilder strings.Builder
+	for _, reminder := range reminders {
+		if reminder.IsAsNeeded() {
+			intakes, err := h.usecases.ReminderExecution.CountAsNeededIntakes(ctx, reminder.ID, r.from, r.to)
+			if err != nil {
+				h.logger.Error("failed to count as-needed intakes", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+				continue
+			}
+			if intakes > 0 {
+				asNeededBuilder.WriteString(fmt.Sprintf("%s — приёмов: %d\n", reminder.Title, intakes))
+			}
+			continue
+		}
+
+		reminderStats, err := h.usecases.ReminderExecution.GetStatisticsByReminderID(ctx, reminder.ID, r.from, r.to)
+		if err != nil {
+			h.logger.Error("failed to get reminder statistics", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+			continue
+		}
+		if reminderStats.TotalDelivered == 0 {
+			continue
+		}
+
+		reminderBuilder.WriteString(fmt.Sprintf("\n%s — принято %d из %d (%.1f%%)\n",
+			reminder.Title, reminderStats.TotalConfirmed, reminderStats.TotalDelivered, reminderStats.AdherenceRate))
+
+		if len(reminder.TimesOfDay) < 2 {
+			continue
+		}
+		slots, err := h.usecases.ReminderExecution.GetSlotStatisticsByReminderID(ctx, reminder.ID, r.from, r.to)
+		if err != nil {
+			h.logger.Error("failed to get slot statistics", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+			continue
+		}
+		for _, slot := range slots {
+			reminderBuilder.WriteString(fmt.Sprintf("   ⏰ %s — принято %d из %d (%.1f%%)\n",
+				slot.Slot, slot.TotalConfirmed, slot.TotalDelivered, slot.AdherenceRate))
+		}
+	}
+	if reminderBuilder.Len() > 0 {
+		builder.WriteString("\n\n💊 По напоминаниям:\n" + reminderBuilder.String())
+	}
+	if asNeededBuilder.Len() > 0 {
+		builder.WriteString("\n\n🩹 По необходимости (не входят в соблюдение режима):\n" + asNeededBuilder.String())
+	}
+
+	return builder.String(), nil
+}
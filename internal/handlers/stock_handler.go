@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+)
+
+// DefaultRefillThresholdDays is used when /stock starts tracking a
+// medication without an explicit threshold.
+const DefaultRefillThresholdDays = 7
+
+const stockUsage = "Использование:\n" +
+	"/stock - остатки лекарств\n" +
+	"/stock 1 60 - на руках 60 шт. для напоминания №1 из /list\n" +
+	"/stock 1 60 0.5 10 - 60 шт., по 0.5 за приём, предупредить за 10 дней\n" +
+	"/stock 1 +30 - пополнить на 30 шт.\n" +
+	"/stock 1 off - не отслеживать остаток"
+
+func (h *BotHandler) handleStock(ctx context.Context, chatID int64, telegramUserID int64, args string) {
+	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден. Попробуйте /start")
+		return
+	}
+
+	reminders, err := h.usecases.Reminder.GetByUserID(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get reminders", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при получении списка напоминаний.")
+		return
+	}
+	if len(reminders) == 0 {
+		h.sendMessage(chatID, "У вас пока нет напоминаний. Создайте первое с помощью /new")
+		return
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		h.sendStockList(ctx, chatID, user, reminders)
+		return
+	}
+
+	number, err := strconv.Atoi(fields[0])
+	if err != nil || number < 1 || number > len(reminders) || len(fields) < 2 {
+		h.sendMessage(chatID, stockUsage)
+		return
+	}
+	reminder := reminders[number-1]
+
+	var stock *entities.Stock
+	switch {
+	case strings.EqualFold(fields[1], "off"):
+		if err := h.usecases.Stock.Remove(ctx, reminder.ID); err != nil {
+			h.sendMessage(chatID, fmt.Sprintf("Ошибка: %s", escapeMarkdown(err.Error())))
+			return
+		}
+		h.sendMessage(chatID, fmt.Sprintf("Остаток «%s» больше не отслеживается.", reminder.Title))
+		return
+
+	case strings.HasPrefix(fields[1], "+"):
+		amount, ok := parseAmount(strings.TrimPrefix(fields[1], "+"))
+		if !ok {
+			h.sendMessage(chatID, stockUsage)
+			return
+		}
+		stock, err = h.usecases.Stock.TopUp(ctx, reminder.ID, amount)
+
+	default:
+		input, ok := parseStockInput(fields[1:])
+		if !ok {
+			h.sendMessage(chatID, stockUsage)
+			return
+		}
+		input.ReminderID = reminder.ID
+		input.UserID = user.ID
+
+		if current, err := h.usecases.Stock.Get(ctx, reminder.ID); err == nil && current != nil {
+			if len(fields) < 3 {
+				input.PerDose = current.PerDose
+			}
+			if len(fields) < 4 {
+				input.RefillThresholdDays = current.RefillThresholdDays
+			}
+		}
+		stock, err = h.usecases.Stock.Set(ctx, input)
+	}
+	if err != nil {
+		h.sendMessage(chatID, fmt.Sprintf("Ошибка: %s", escapeMarkdown(err.Error())))
+		return
+	}
+
+	h.sendMessage(chatID, "✅ Остаток обновлён.\n\n"+h.formatStock(ctx, reminder, stock))
+}
+
+func (h *BotHandler) sendStockList(ctx context.Context, chatID int64, user *entities.User, reminders []*entities.Reminder) {
+	stocks, err := h.usecases.Stock.GetByUserID(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("failed to get stocks", zap.Error(err))
+		h.sendMessage(chatID, "Ошибка при получении остатков.")
+		return
+	}
+	byReminder := make(map[string]*entities.Stock, len(stocks))
+	for _, stock := range stocks {
+		byReminder[stock.ReminderID.String()] = stock
+	}
+
+	var builder strings.Builder
+	builder.WriteString("💊 Остатки лекарств:\n\n")
+	for i, reminder := range reminders {
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, reminder.Title))
+		if stock, ok := byReminder[reminder.ID.String()]; ok {
+			builder.WriteString(h.formatStock(ctx, reminder, stock))
+			builder.WriteString("\n")
+		} else {
+			builder.WriteString("   Не отслеживается\n\n")
+		}
+	}
+	builder.WriteString(stockUsage)
+
+	h.sendMessage(chatID, builder.String())
+}
+
+func (h *BotHandler) formatStock(ctx context.Context, reminder *entities.Reminder, stock *entities.Stock) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("   На руках: %s (по %s за приём)\n", formatAmount(stock.OnHand), formatAmount(stock.PerDose)))
+
+	daysLeft, runsOut, err := h.usecases.Stock.DaysLeft(ctx, reminder, stock)
+	switch {
+	case err != nil:
+		h.logger.Error("failed to project stock", zap.Error(err), zap.String("reminder_id", reminder.ID.String()))
+	case runsOut:
+		builder.WriteString(fmt.Sprintf("   Хватит примерно на %d дн.\n", int(math.Floor(daysLeft))))
+	default:
+		builder.WriteString("   Приёмов по расписанию больше не запланировано\n")
+	}
+
+	if stock.RefillThresholdDays > 0 {
+		builder.WriteString(fmt.Sprintf("   Напомнить о покупке за %d дн.\n", stock.RefillThresholdDays))
+	}
+	return builder.String()
+}
+
+// SendLowStock warns the user that a medication is about to run out.
+func (h *BotHandler) SendLowStock(ctx context.Context, low *usecases.LowStock) error {
+	user, err := h.usecases.User.GetByID(ctx, low.Reminder.UserID)
+	if err != nil || user == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	text := fmt.Sprintf("🛒 Заканчивается *%s*: осталось %s, этого хватит примерно на %d дн.\n\nКупите заранее и пополните остаток командой /stock.",
+		low.Reminder.Title,
+		formatAmount(low.Stock.OnHand),
+		int(math.Floor(low.DaysLeft)),
+	)
+	if low.Stock.DosesLeft() == 0 {
+		text = fmt.Sprintf("🛒 Закончилось *%s*.\n\nКупите лекарство и пополните остаток командой /stock.", low.Reminder.Title)
+	}
+
+	msg := tgbotapi.NewMessage(user.TelegramID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := h.bot.Send(msg); err != nil {
+		return fmt.Errorf("failed to send low stock warning: %w", err)
+	}
+
+	return nil
+}
+
+// parseStockInput parses "<on hand> [per dose] [threshold days]".
+func parseStockInput(fields []string) (usecases.SetStockInput, bool) {
+	input := usecases.SetStockInput{
+		PerDose:             1,
+		RefillThresholdDays: DefaultRefillThresholdDays,
+	}
+	if len(fields) > 3 {
+		return input, false
+	}
+
+	var ok bool
+	if input.OnHand, ok = parseAmount(fields[0]); !ok {
+		return input, false
+	}
+	if len(fields) > 1 {
+		if input.PerDose, ok = parseAmount(fields[1]); !ok {
+			return input, false
+		}
+	}
+	if len(fields) > 2 {
+		days, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return input, false
+		}
+		input.RefillThresholdDays = days
+	}
+	return input, true
+}
+
+func parseAmount(value string) (float64, bool) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, false
+	}
+	return amount, true
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
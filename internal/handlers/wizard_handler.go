@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+)
+
+// Wizard buttons carry "wiz:<step>:<action>[:<value>]" so that a button
+// left over from an earlier step can be told apart from a current one.
+const wizardCallbackPrefix = "wiz"
+
+var reminderTypeLabels = []struct {
+	Type  entities.ReminderType
+	Label string
+}{
+	{entities.ReminderTypeSpecific, "⏰ В точное время"},
+	{entities.ReminderTypeWeekly, "📆 По дням недели"},
+	{entities.ReminderTypeCustom, "⏱ Каждые N часов"},
+	{entities.ReminderTypeDaily, "📅 Раз в день"},
+	{entities.ReminderTypeRRule, "🔁 Правило RRULE"},
+	{entities.ReminderTypeAsNeeded, "💊 По необходимости"},
+}
+
+func parseReminderType(value string) (entities.ReminderType, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "daily":
+		return entities.ReminderTypeDaily, true
+	case "weekly":
+		return entities.ReminderTypeWeekly, true
+	case "custom":
+		return entities.ReminderTypeCustom, true
+	case "specific":
+		return entities.ReminderTypeSpecific, true
+	case "rrule":
+		return entities.ReminderTypeRRule, true
+	case "as_needed", "prn":
+		return entities.ReminderTypeAsNeeded, true
+	default:
+		return "", false
+	}
+}
+
+func (h *BotHandler) handleNewReminder(ctx context.Context, chatID int64, telegramUserID int64) {
+	user, err := h.usecases.User.GetByTelegramID(ctx, telegramUserID)
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден. Попробуйте /start")
+		return
+	}
+
+	conversation, err := h.usecases.Conversation.Start(ctx, chatID, user.ID)
+	if err != nil {
+		h.logger.Error("failed to start conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+		h.sendMessage(chatID, "Ошибка при создании напоминания. Попробуйте позже.")
+		return
+	}
+
+	h.promptWizardStep(chatID, conversation)
+}
+
+func (h *BotHandler) handleCancel(ctx context.Context, chatID int64) {
+	conversation, err := h.usecases.Conversation.Get(ctx, chatID)
+	if err != nil {
+		h.logger.Error("failed to get conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+	if conversation == nil {
+		h.sendMessage(chatID, "Нечего отменять.")
+		return
+	}
+
+	h.cancelWizard(ctx, conversation)
+}
+
+func (h *BotHandler) cancelWizard(ctx context.Context, conversation *entities.Conversation) {
+	if err := h.usecases.Conversation.Finish(ctx, conversation.ChatID); err != nil {
+		h.logger.Error("failed to finish conversation", zap.Error(err), zap.Int64("chat_id", conversation.ChatID))
+	}
+	switch {
+	case conversation.Step == entities.ConversationStepSnooze:
+		h.sendMessage(conversation.ChatID, "Хорошо, не откладываю.")
+	case conversation.IsEdit():
+		h.sendMessage(conversation.ChatID, "Изменение отменено.")
+	default:
+		h.sendMessage(conversation.ChatID, "Создание напоминания отменено.")
+	}
+}
+
+func (h *BotHandler) handleWizardMessage(ctx context.Context, msg *tgbotapi.Message, conversation *entities.Conversation) {
+	chatID := msg.Chat.ID
+	text := strings.TrimSpace(msg.Text)
+	draft := &conversation.Draft
+
+	switch conversation.Step {
+	case entities.ConversationStepTitle:
+		if text == "" {
+			h.sendMessage(chatID, "Отправьте название текстом.")
+			return
+		}
+		if strings.Contains(text, "|") && !conversation.IsEdit() {
+			if err := h.usecases.Conversation.Finish(ctx, chatID); err != nil {
+				h.logger.Error("failed to finish conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+			}
+			h.createFromLine(ctx, chatID, int64(msg.From.ID), text)
+			return
+		}
+		if len([]rune(text)) > 255 {
+			h.sendMessage(chatID, "Название слишком длинное, максимум 255 символов.")
+			return
+		}
+		draft.Title = text
+
+	case entities.ConversationStepType:
+		reminderType, ok := parseReminderType(text)
+		if !ok {
+			h.sendMessage(chatID, "Выберите тип кнопкой под сообщением выше.")
+			return
+		}
+		if draft.Type != reminderType {
+			draft.ClearSchedule()
+		}
+		draft.Type = reminderType
+
+	case entities.ConversationStepSchedule:
+		if errText := applyWizardSchedule(draft, text); errText != "" {
+			h.sendMessage(chatID, errText)
+			return
+		}
+
+	case entities.ConversationStepComment:
+		if text == "" {
+			h.sendMessage(chatID, "Отправьте комментарий текстом или нажмите «Пропустить».")
+			return
+		}
+		draft.Comment = &text
+
+	case entities.ConversationStepPhoto:
+		fileID, ok := photoFileID(msg)
+		if !ok {
+			h.sendMessage(chatID, "Отправьте фотографию (как фото, а не файлом) или нажмите кнопку под сообщением выше.")
+			return
+		}
+		draft.ImageFileID = &fileID
+	}
+
+	h.advanceWizard(ctx, chatID, conversation)
+}
+
+func (h *BotHandler) handleWizardCallback(ctx context.Context, callback *tgbotapi.CallbackQuery, parts []string) {
+	chatID := callback.Message.Chat.ID
+	h.editReplyMarkup(chatID, callback.Message.MessageID, emptyKeyboard())
+
+	conversation, err := h.usecases.Conversation.Get(ctx, chatID)
+	if err != nil {
+		h.logger.Error("failed to get conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+	if conversation == nil || len(parts) < 3 {
+		h.answerCallbackQuery(callback.ID, "Создание напоминания уже завершено")
+		return
+	}
+	if entities.ConversationStep(parts[1]) != conversation.Step {
+		h.answerCallbackQuery(callback.ID, "Эта кнопка устарела")
+		return
+	}
+
+	switch parts[2] {
+	case "cancel":
+		h.answerCallbackQuery(callback.ID, "Отменено")
+		h.cancelWizard(ctx, conversation)
+
+	case "back":
+		h.answerCallbackQuery(callback.ID, "")
+		if previous := wizardPreviousStep(conversation); previous != "" {
+			conversation.Step = previous
+			if err := h.usecases.Conversation.Save(ctx, conversation); err != nil {
+				h.logger.Error("failed to save conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+				h.sendMessage(chatID, "Ошибка при сохранении. Попробуйте ещё раз.")
+				return
+			}
+		}
+		h.promptWizardStep(chatID, conversation)
+
+	case "type":
+		reminderType, ok := entities.ReminderType(""), false
+		if len(parts) == 4 {
+			reminderType, ok = parseReminderType(parts[3])
+		}
+		if !ok {
+			h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "")
+		if conversation.Draft.Type != reminderType {
+			conversation.Draft.ClearSchedule()
+		}
+		conversation.Draft.Type = reminderType
+		h.advanceWizard(ctx, chatID, conversation)
+
+	case "skip":
+		switch conversation.Step {
+		case entities.ConversationStepComment:
+			conversation.Draft.Comment = nil
+		case entities.ConversationStepPhoto:
+			conversation.Draft.ImageFileID = nil
+		default:
+			h.answerCallbackQuery(callback.ID, "Этот шаг нельзя пропустить")
+			return
+		}
+		h.answerCallbackQuery(callback.ID, "")
+		h.advanceWizard(ctx, chatID, conversation)
+
+	default:
+		h.answerCallbackQuery(callback.ID, "Ошибка обработки команды")
+	}
+}
+
+// advanceWizard moves to the next step, creating the reminder after the
+// last one. Editing asks only for the chosen field, plus the schedule after
+// a type.
+func (h *BotHandler) advanceWizard(ctx context.Context, chatID int64, conversation *entities.Conversation) {
+	next := conversation.Step.Next()
+	if conversation.IsEdit() && conversation.Step != entities.ConversationStepType {
+		next = ""
+	}
+	if next == "" {
+		if conversation.IsEdit() {
+			h.finishEdit(ctx, chatID, conversation)
+			return
+		}
+		h.finishWizard(ctx, chatID, conversation)
+		return
+	}
+
+	conversation.Step = next
+	if err := h.usecases.Conversation.Save(ctx, conversation); err != nil {
+		h.logger.Error("failed to save conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+		h.sendMessage(chatID, "Ошибка при сохранении. Попробуйте ещё раз.")
+		return
+	}
+	h.promptWizardStep(chatID, conversation)
+}
+
+func (h *BotHandler) finishWizard(ctx context.Context, chatID int64, conversation *entities.Conversation) {
+	user, err := h.usecases.User.GetByID(ctx, conversation.UserID)
+	if err != nil || user == nil {
+		h.sendMessage(chatID, "Ошибка: пользователь не найден. Попробуйте /start")
+		return
+	}
+
+	draft := conversation.Draft
+	reminder, err := h.usecases.Reminder.Create(ctx, usecases.CreateReminderInput{
+		UserID:             user.ID,
+		Title:              draft.Title,
+		Comment:            draft.Comment,
+		ImageFileID:        draft.ImageFileID,
+		Type:               draft.Type,
+		IntervalHours:      draft.IntervalHours,
+		TimesOfDay:         draft.TimesOfDay,
+		Weekdays:           draft.Weekdays,
+		RRule:              draft.RRule,
+		MinIntervalMinutes: draft.MinIntervalMinutes,
+		MaxDosesPerDay:     draft.MaxDosesPerDay,
+	})
+	if err != nil {
+		h.logger.Error("failed to create reminder", zap.Error(err), zap.Int64("chat_id", chatID))
+		h.sendMessage(chatID, fmt.Sprintf("Ошибка при создании напоминания: %s\n\nИсправьте расписание.", escapeMarkdown(err.Error())))
+
+		conversation.Step = entities.ConversationStepSchedule
+		if err := h.usecases.Conversation.Save(ctx, conversation); err != nil {
+			h.logger.Error("failed to save conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+			return
+		}
+		h.promptWizardStep(chatID, conversation)
+		return
+	}
+
+	if err := h.usecases.Conversation.Finish(ctx, chatID); err != nil {
+		h.logger.Error("failed to finish conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+	h.sendMessage(chatID, formatCreatedReminder(reminder, user.Location()))
+}
+
+func (h *BotHandler) finishEdit(ctx context.Context, chatID int64, conversation *entities.Conversation) {
+	draft := conversation.Draft
+
+	var input usecases.UpdateReminderInput
+	switch conversation.Step {
+	case entities.ConversationStepTitle:
+		input.Title = &draft.Title
+	case entities.ConversationStepComment:
+		comment := ""
+		if draft.Comment != nil {
+			comment = *draft.Comment
+		}
+		input.Comment = &comment
+	case entities.ConversationStepPhoto:
+		fileID, url := "", ""
+		if draft.ImageFileID != nil {
+			fileID = *draft.ImageFileID
+		}
+		input.ImageFileID = &fileID
+		input.ImageURL = &url
+	case entities.ConversationStepSchedule:
+		input.Type = &draft.Type
+		input.IntervalHours = draft.IntervalHours
+		input.TimesOfDay = draft.TimesOfDay
+		input.Weekdays = &draft.Weekdays
+		input.RRule = draft.RRule
+		if draft.Type == entities.ReminderTypeAsNeeded {
+			minInterval, maxPerDay := 0, 0
+			if draft.MinIntervalMinutes != nil {
+				minInterval = *draft.MinIntervalMinutes
+			}
+			if draft.MaxDosesPerDay != nil {
+				maxPerDay = *draft.MaxDosesPerDay
+			}
+			input.MinIntervalMinutes = &minInterval
+			input.MaxDosesPerDay = &maxPerDay
+		}
+	}
+
+	reminder, err := h.usecases.Reminder.Update(ctx, *conversation.ReminderID, input)
+	if err != nil {
+		h.logger.Error("failed to update reminder", zap.Error(err), zap.Int64("chat_id", chatID))
+		h.sendMessage(chatID, fmt.Sprintf("Ошибка при изменении напоминания: %s", escapeMarkdown(err.Error())))
+		if conversation.Step == entities.ConversationStepSchedule {
+			h.promptWizardStep(chatID, conversation)
+			return
+		}
+		if err := h.usecases.Conversation.Finish(ctx, chatID); err != nil {
+			h.logger.Error("failed to finish conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+		}
+		return
+	}
+
+	if err := h.usecases.Conversation.Finish(ctx, chatID); err != nil {
+		h.logger.Error("failed to finish conversation", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+
+	loc := time.Local
+	if user, err := h.usecases.User.GetByID(ctx, reminder.UserID); err == nil && user != nil {
+		loc = user.Location()
+	}
+	msg := tgbotapi.NewMessage(chatID, "✅ Напоминание изменено.\n\n"+formatReminderCard(reminder, loc))
+	msg.ReplyMarkup = reminderCardKeyboard(reminder)
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+// wizardPreviousStep returns the step the back button leads to. While
+// editing, only the schedule step can go back, to the type.
+func wizardPreviousStep(conversation *entities.Conversation) entities.ConversationStep {
+	if conversation.IsEdit() && conversation.Step != entities.ConversationStepSchedule {
+		return ""
+	}
+	return conversation.Step.Previous()
+}
+
+func (h *BotHandler) promptWizardStep(chatID int64, conversation *entities.Conversation) {
+	step := conversation.Step
+	draft := conversation.Draft
+	editing := conversation.IsEdit()
+
+	var text string
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	switch step {
+	case entities.ConversationStepTitle:
+		text = "Создание нового напоминания 📝\n\nШаг 1 из 5. Как называется лекарство?\n\n" +
+			"Можно сразу отправить всё одной строкой: Название|Тип|Комментарий|Время|Курс|Повтор, подробнее в /help."
+		if editing {
+			text = fmt.Sprintf("Сейчас напоминание называется «%s». Отправьте новое название.", draft.Title)
+		}
+
+	case entities.ConversationStepType:
+		text = fmt.Sprintf("Как часто напоминать о «%s»?", draft.Title)
+		for _, option := range reminderTypeLabels {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(option.Label, wizardCallback(step, "type", string(option.Type))),
+			))
+		}
+
+	case entities.ConversationStepSchedule:
+		text = wizardSchedulePrompt(draft.Type)
+
+	case entities.ConversationStepComment:
+		text = "Добавьте комментарий, например «после еды», или пропустите этот шаг."
+		skip := "➡️ Пропустить"
+		if editing {
+			text = "Отправьте новый комментарий."
+			skip = "🗑 Убрать комментарий"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(skip, wizardCallback(step, "skip")),
+		))
+
+	case entities.ConversationStepPhoto:
+		text = "Отправьте фото упаковки, чтобы его было видно в напоминании, или пропустите этот шаг."
+		skip := "➡️ Пропустить"
+		if editing {
+			text = "Отправьте новое фото упаковки."
+			skip = "🗑 Убрать фото"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(skip, wizardCallback(step, "skip")),
+		))
+	}
+
+	if !editing && step != entities.ConversationStepTitle {
+		text = fmt.Sprintf("Шаг %d из %d. %s", step.Number(), entities.ConversationStepCount, text)
+	}
+
+	navigation := tgbotapi.NewInlineKeyboardRow()
+	if wizardPreviousStep(conversation) != "" {
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", wizardCallback(step, "back")))
+	}
+	navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData("✖️ Отмена", wizardCallback(step, "cancel")))
+	rows = append(rows, navigation)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	if _, err := h.bot.Send(msg); err != nil {
+		h.logger.Error("failed to send message", zap.Error(err), zap.Int64("chat_id", chatID))
+	}
+}
+
+func wizardCallback(step entities.ConversationStep, action string, value ...string) string {
+	return strings.Join(append([]string{wizardCallbackPrefix, string(step), action}, value...), ":")
+}
+
+func wizardSchedulePrompt(reminderType entities.ReminderType) string {
+	switch reminderType {
+	case entities.ReminderTypeWeekly:
+		return "В какие дни и во сколько напоминать? Например: пн,ср,пт 08:00 или пн-пт 08:00,20:00"
+	case entities.ReminderTypeCustom:
+		return "Через сколько часов повторять напоминание? Например: 6"
+	case entities.ReminderTypeRRule:
+		return "Отправьте правило повторения iCalendar, например:\nDTSTART:20260101T090000 RRULE:FREQ=DAILY;INTERVAL=2"
+	case entities.ReminderTypeAsNeeded:
+		return "Такое лекарство бот не напоминает по расписанию — приём отмечается кнопкой «💊 Принять» в карточке или командой /took.\n\n" +
+			"Укажите минимальный интервал между приёмами в часах и максимум приёмов за сутки, например: 4 3\n" +
+			"Если ограничений нет, отправьте «-»."
+	default:
+		return "Во сколько напоминать? Формат ЧЧ:ММ, несколько — через запятую: 08:00,14:00,21:00"
+	}
+}
+
+// applyWizardSchedule parses the schedule step for the draft's type. It
+// returns the text to show the user when the value is not valid.
+func applyWizardSchedule(draft *entities.ReminderDraft, value string) string {
+	draft.ClearSchedule()
+
+	switch draft.Type {
+	case entities.ReminderTypeWeekly:
+		weekdays, times, ok := parseWeeklySchedule(value)
+		if !ok {
+			return "Укажите дни недели и время, например: пн,ср,пт 08:00"
+		}
+		draft.Weekdays = weekdays
+		draft.TimesOfDay = times
+
+	case entities.ReminderTypeCustom:
+		interval, err := strconv.Atoi(value)
+		if err != nil || interval <= 0 {
+			return "Укажите положительное число часов, например: 6"
+		}
+		draft.IntervalHours = &interval
+
+	case entities.ReminderTypeRRule:
+		if value == "" {
+			return "Отправьте правило повторения текстом."
+		}
+		draft.RRule = &value
+
+	case entities.ReminderTypeAsNeeded:
+		minInterval, maxPerDay, ok := parseDoseLimits(value)
+		if !ok {
+			return "Укажите интервал в часах и максимум приёмов за сутки, например: 4 3, или «-» без ограничений."
+		}
+		draft.MinIntervalMinutes = minInterval
+		draft.MaxDosesPerDay = maxPerDay
+
+	default:
+		times, ok := parseTimesOfDay(value)
+		if !ok {
+			return "Неверный формат времени. Используйте ЧЧ:ММ, например 09:00 или 08:00,14:00,21:00"
+		}
+		draft.TimesOfDay = times
+	}
+
+	return ""
+}
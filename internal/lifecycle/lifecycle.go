@@ -0,0 +1,93 @@
+// Package lifecycle starts the parts of the application in order and stops
+// them in reverse order, so that each part stops only after everything that
+// feeds it work has stopped.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Hook is one part of the application. OnStart must not block; OnStop stops
+// accepting work and waits until the work in progress is finished or ctx is
+// done. Either function may be nil.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+type Lifecycle struct {
+	hooks   []Hook
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewLifecycle creates a lifecycle that gives its hooks timeout to stop.
+func NewLifecycle(timeout time.Duration, logger *zap.Logger) *Lifecycle {
+	return &Lifecycle{
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+func (l *Lifecycle) Append(hook Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
+// Run starts the hooks, waits until ctx is done and stops them. The context
+// handed to the hooks is not cancelled together with ctx: it stays alive
+// while the hooks finish their work and is cancelled only when the stop
+// timeout runs out, to abort whatever is still in progress.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	runCtx, cancelRun := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRun()
+
+	started, err := l.start(runCtx)
+	if err != nil {
+		return errors.Join(err, l.stop(started, cancelRun))
+	}
+
+	<-ctx.Done()
+	l.logger.Info("Shutting down...")
+
+	return l.stop(started, cancelRun)
+}
+
+func (l *Lifecycle) start(ctx context.Context) (int, error) {
+	for i, hook := range l.hooks {
+		if hook.OnStart == nil {
+			continue
+		}
+		if err := hook.OnStart(ctx); err != nil {
+			return i, fmt.Errorf("failed to start %s: %w", hook.Name, err)
+		}
+	}
+	return len(l.hooks), nil
+}
+
+// stop runs OnStop of the first count hooks in reverse order.
+func (l *Lifecycle) stop(count int, cancelRun context.CancelFunc) error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+	context.AfterFunc(ctx, cancelRun)
+
+	var errs []error
+	for i := count - 1; i >= 0; i-- {
+		hook := l.hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		if err := hook.OnStop(ctx); err != nil {
+			l.logger.Error("failed to stop", zap.String("component", hook.Name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+			continue
+		}
+		l.logger.Info("Stopped", zap.String("component", hook.Name))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,117 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingHook appends "start <name>" and "stop <name>" to events.
+func recordingHook(name string, events *[]string) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			*events = append(*events, "start "+name)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycle_Run(t *testing.T) {
+	t.Run("stops hooks in reverse order", func(t *testing.T) {
+		var events []string
+		l := NewLifecycle(time.Second, zap.NewNop())
+		l.Append(recordingHook("database", &events))
+		l.Append(recordingHook("scheduler", &events))
+		l.Append(recordingHook("updates", &events))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.NoError(t, l.Run(ctx))
+		assert.Equal(t, []string{
+			"start database", "start scheduler", "start updates",
+			"stop updates", "stop scheduler", "stop database",
+		}, events)
+	})
+
+	t.Run("stops started hooks when one fails to start", func(t *testing.T) {
+		var events []string
+		l := NewLifecycle(time.Second, zap.NewNop())
+		l.Append(recordingHook("database", &events))
+		l.Append(recordingHook("scheduler", &events))
+		l.Append(Hook{
+			Name:    "webhook",
+			OnStart: func(ctx context.Context) error { return errors.New("address in use") },
+			OnStop: func(ctx context.Context) error {
+				events = append(events, "stop webhook")
+				return nil
+			},
+		})
+
+		err := l.Run(context.Background())
+		assert.ErrorContains(t, err, "failed to start webhook: address in use")
+		assert.Equal(t, []string{
+			"start database", "start scheduler",
+			"stop scheduler", "stop database",
+		}, events)
+	})
+
+	t.Run("keeps the run context alive while stopping", func(t *testing.T) {
+		var runCtx context.Context
+		l := NewLifecycle(time.Second, zap.NewNop())
+		l.Append(Hook{
+			Name: "scheduler",
+			OnStart: func(ctx context.Context) error {
+				runCtx = ctx
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return runCtx.Err()
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.NoError(t, l.Run(ctx))
+		assert.Error(t, runCtx.Err())
+	})
+
+	t.Run("cancels work in progress when stopping times out", func(t *testing.T) {
+		var events []string
+		l := NewLifecycle(20*time.Millisecond, zap.NewNop())
+		l.Append(recordingHook("database", &events))
+		aborted := make(chan struct{})
+		l.Append(Hook{
+			Name: "scheduler",
+			OnStart: func(ctx context.Context) error {
+				context.AfterFunc(ctx, func() { close(aborted) })
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				<-ctx.Done()
+				<-aborted
+				events = append(events, "aborted")
+				return ctx.Err()
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := l.Run(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "failed to stop scheduler")
+		assert.Equal(t, []string{"start database", "aborted", "stop database"}, events)
+	})
+}
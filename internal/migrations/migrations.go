@@ -28,11 +28,25 @@ func (m *Migrator) Run() error {
 		&entities.User{},
 		&entities.Reminder{},
 		&entities.ReminderExecution{},
+		&entities.Caregiver{},
+		&entities.Stock{},
+		&entities.Conversation{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	// Intakes of as-needed medicines logged before executions had a source
+	// were stored as scheduled doses.
+	err = m.db.Exec(`UPDATE reminder_executions SET source = ?
+		FROM reminders
+		WHERE reminders.id = reminder_executions.reminder_id AND reminders.type = ? AND reminder_executions.source = ?`,
+		entities.ExecutionSourceAsNeeded, entities.ReminderTypeAsNeeded, entities.ExecutionSourceScheduled,
+	).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark as-needed intakes: %w", err)
+	}
+
 	m.logger.Info("Migrations completed successfully")
 	return nil
 }
@@ -41,6 +55,9 @@ func (m *Migrator) Rollback() error {
 	m.logger.Info("Rolling back migrations")
 
 	err := m.db.Migrator().DropTable(
+		&entities.Conversation{},
+		&entities.Stock{},
+		&entities.Caregiver{},
 		&entities.ReminderExecution{},
 		&entities.Reminder{},
 		&entities.User{},
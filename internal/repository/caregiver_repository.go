@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+)
+
+type CaregiverRepository interface {
+	Create(ctx context.Context, caregiver *entities.Caregiver) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Caregiver, error)
+	GetByInviteToken(ctx context.Context, token string) (*entities.Caregiver, error)
+	GetAcceptedByPatientID(ctx context.Context, patientID uuid.UUID) ([]*entities.Caregiver, error)
+	GetAcceptedByCaregiverID(ctx context.Context, caregiverID uuid.UUID) ([]*entities.Caregiver, error)
+	Update(ctx context.Context, caregiver *entities.Caregiver) error
+	Accept(ctx context.Context, id, caregiverID uuid.UUID, acceptedAt time.Time) (bool, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type caregiverRepository struct {
+	db *gorm.DB
+}
+
+func NewCaregiverRepository(db *gorm.DB) CaregiverRepository {
+	return &caregiverRepository{db: db}
+}
+
+func (r *caregiverRepository) Create(ctx context.Context, caregiver *entities.Caregiver) error {
+	now := time.Now()
+	caregiver.ID = uuid.New()
+	caregiver.CreatedAt = now
+	caregiver.UpdatedAt = now
+
+	return r.db.WithContext(ctx).Create(caregiver).Error
+}
+
+func (r *caregiverRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Caregiver, error) {
+	var caregiver entities.Caregiver
+	err := r.db.WithContext(ctx).First(&caregiver, "id = ?", id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &caregiver, nil
+}
+
+func (r *caregiverRepository) GetByInviteToken(ctx context.Context, token string) (*entities.Caregiver, error) {
+	var caregiver entities.Caregiver
+	err := r.db.WithContext(ctx).First(&caregiver, "invite_token = ?", token).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &caregiver, nil
+}
+
+func (r *caregiverRepository) GetAcceptedByPatientID(ctx context.Context, patientID uuid.UUID) ([]*entities.Caregiver, error) {
+	var caregivers []*entities.Caregiver
+	err := r.db.WithContext(ctx).
+		Where("patient_id = ? AND status = ?", patientID, entities.CaregiverStatusAccepted).
+		Order("accepted_at ASC").
+		Find(&caregivers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return caregivers, nil
+}
+
+func (r *caregiverRepository) GetAcceptedByCaregiverID(ctx context.Context, caregiverID uuid.UUID) ([]*entities.Caregiver, error) {
+	var caregivers []*entities.Caregiver
+	err := r.db.WithContext(ctx).
+		Where("caregiver_id = ? AND status = ?", caregiverID, entities.CaregiverStatusAccepted).
+		Order("accepted_at ASC").
+		Find(&caregivers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return caregivers, nil
+}
+
+func (r *caregiverRepository) Update(ctx context.Context, caregiver *entities.Caregiver) error {
+	caregiver.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Save(caregiver).Error
+}
+
+// Accept links the caregiver to a pending invite and reports whether the
+// invite was still pending, so it is accepted once.
+func (r *caregiverRepository) Accept(ctx context.Context, id, caregiverID uuid.UUID, acceptedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Caregiver{}).
+		Where("id = ? AND status = ?", id, entities.CaregiverStatusPending).
+		Updates(map[string]interface{}{
+			"caregiver_id": caregiverID,
+			"status":       entities.CaregiverStatusAccepted,
+			"accepted_at":  acceptedAt,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *caregiverRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.Caregiver{}, "id = ?", id).Error
+}
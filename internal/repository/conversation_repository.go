@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+)
+
+type ConversationRepository interface {
+	GetByChatID(ctx context.Context, chatID int64) (*entities.Conversation, error)
+	Save(ctx context.Context, conversation *entities.Conversation) error
+	Delete(ctx context.Context, chatID int64) error
+}
+
+type conversationRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationRepository(db *gorm.DB) ConversationRepository {
+	return &conversationRepository{db: db}
+}
+
+func (r *conversationRepository) GetByChatID(ctx context.Context, chatID int64) (*entities.Conversation, error) {
+	var conversation entities.Conversation
+	err := r.db.WithContext(ctx).First(&conversation, "chat_id = ?", chatID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+// Save inserts the conversation or replaces the one already stored for the
+// chat.
+func (r *conversationRepository) Save(ctx context.Context, conversation *entities.Conversation) error {
+	now := time.Now()
+	if conversation.CreatedAt.IsZero() {
+		conversation.CreatedAt = now
+	}
+	conversation.UpdatedAt = now
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "reminder_id", "execution_id", "message_id", "step", "draft", "updated_at"}),
+		}).
+		Create(conversation).Error
+}
+
+func (r *conversationRepository) Delete(ctx context.Context, chatID int64) error {
+	return r.db.WithContext(ctx).Delete(&entities.Conversation{}, "chat_id = ?", chatID).Error
+}
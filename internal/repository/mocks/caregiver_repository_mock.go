@@ -0,0 +1,161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: caregiver_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=caregiver_repository.go -destination=./mocks/caregiver_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entities "github.com/Helltale/take-your-pills-on-time/internal/entities"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCaregiverRepository is a mock of CaregiverRepository interface.
+type MockCaregiverRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaregiverRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCaregiverRepositoryMockRecorder is the mock recorder for MockCaregiverRepository.
+type MockCaregiverRepositoryMockRecorder struct {
+	mock *MockCaregiverRepository
+}
+
+// NewMockCaregiverRepository creates a new mock instance.
+func NewMockCaregiverRepository(ctrl *gomock.Controller) *MockCaregiverRepository {
+	mock := &MockCaregiverRepository{ctrl: ctrl}
+	mock.recorder = &MockCaregiverRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaregiverRepository) EXPECT() *MockCaregiverRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method.
+func (m *MockCaregiverRepository) Accept(ctx context.Context, id, caregiverID uuid.UUID, acceptedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", ctx, id, caregiverID, acceptedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockCaregiverRepositoryMockRecorder) Accept(ctx, id, caregiverID, acceptedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockCaregiverRepository)(nil).Accept), ctx, id, caregiverID, acceptedAt)
+}
+
+// Create mocks base method.
+func (m *MockCaregiverRepository) Create(ctx context.Context, caregiver *entities.Caregiver) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, caregiver)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCaregiverRepositoryMockRecorder) Create(ctx, caregiver any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCaregiverRepository)(nil).Create), ctx, caregiver)
+}
+
+// Delete mocks base method.
+func (m *MockCaregiverRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCaregiverRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCaregiverRepository)(nil).Delete), ctx, id)
+}
+
+// GetAcceptedByCaregiverID mocks base method.
+func (m *MockCaregiverRepository) GetAcceptedByCaregiverID(ctx context.Context, caregiverID uuid.UUID) ([]*entities.Caregiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAcceptedByCaregiverID", ctx, caregiverID)
+	ret0, _ := ret[0].([]*entities.Caregiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAcceptedByCaregiverID indicates an expected call of GetAcceptedByCaregiverID.
+func (mr *MockCaregiverRepositoryMockRecorder) GetAcceptedByCaregiverID(ctx, caregiverID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAcceptedByCaregiverID", reflect.TypeOf((*MockCaregiverRepository)(nil).GetAcceptedByCaregiverID), ctx, caregiverID)
+}
+
+// GetAcceptedByPatientID mocks base method.
+func (m *MockCaregiverRepository) GetAcceptedByPatientID(ctx context.Context, patientID uuid.UUID) ([]*entities.Caregiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAcceptedByPatientID", ctx, patientID)
+	ret0, _ := ret[0].([]*entities.Caregiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAcceptedByPatientID indicates an expected call of GetAcceptedByPatientID.
+func (mr *MockCaregiverRepositoryMockRecorder) GetAcceptedByPatientID(ctx, patientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAcceptedByPatientID", reflect.TypeOf((*MockCaregiverRepository)(nil).GetAcceptedByPatientID), ctx, patientID)
+}
+
+// GetByID mocks base method.
+func (m *MockCaregiverRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Caregiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entities.Caregiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockCaregiverRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCaregiverRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByInviteToken mocks base method.
+func (m *MockCaregiverRepository) GetByInviteToken(ctx context.Context, token string) (*entities.Caregiver, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByInviteToken", ctx, token)
+	ret0, _ := ret[0].(*entities.Caregiver)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByInviteToken indicates an expected call of GetByInviteToken.
+func (mr *MockCaregiverRepositoryMockRecorder) GetByInviteToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByInviteToken", reflect.TypeOf((*MockCaregiverRepository)(nil).GetByInviteToken), ctx, token)
+}
+
+// Update mocks base method.
+func (m *MockCaregiverRepository) Update(ctx context.Context, caregiver *entities.Caregiver) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, caregiver)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCaregiverRepositoryMockRecorder) Update(ctx, caregiver any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCaregiverRepository)(nil).Update), ctx, caregiver)
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: conversation_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=conversation_repository.go -destination=./mocks/conversation_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/Helltale/take-your-pills-on-time/internal/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockConversationRepository is a mock of ConversationRepository interface.
+type MockConversationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockConversationRepositoryMockRecorder is the mock recorder for MockConversationRepository.
+type MockConversationRepositoryMockRecorder struct {
+	mock *MockConversationRepository
+}
+
+// NewMockConversationRepository creates a new mock instance.
+func NewMockConversationRepository(ctrl *gomock.Controller) *MockConversationRepository {
+	mock := &MockConversationRepository{ctrl: ctrl}
+	mock.recorder = &MockConversationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversationRepository) EXPECT() *MockConversationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockConversationRepository) Delete(ctx context.Context, chatID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, chatID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockConversationRepositoryMockRecorder) Delete(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockConversationRepository)(nil).Delete), ctx, chatID)
+}
+
+// GetByChatID mocks base method.
+func (m *MockConversationRepository) GetByChatID(ctx context.Context, chatID int64) (*entities.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByChatID", ctx, chatID)
+	ret0, _ := ret[0].(*entities.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByChatID indicates an expected call of GetByChatID.
+func (mr *MockConversationRepositoryMockRecorder) GetByChatID(ctx, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByChatID", reflect.TypeOf((*MockConversationRepository)(nil).GetByChatID), ctx, chatID)
+}
+
+// Save mocks base method.
+func (m *MockConversationRepository) Save(ctx context.Context, conversation *entities.Conversation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, conversation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockConversationRepositoryMockRecorder) Save(ctx, conversation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockConversationRepository)(nil).Save), ctx, conversation)
+}
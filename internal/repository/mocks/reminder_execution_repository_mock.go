@@ -44,6 +44,81 @@ func (m *MockReminderExecutionRepository) EXPECT() *MockReminderExecutionReposit
 	return m.recorder
 }
 
+// ClaimNag mocks base method.
+func (m *MockReminderExecutionRepository) ClaimNag(ctx context.Context, id uuid.UUID, nagCount int, naggedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNag", ctx, id, nagCount, naggedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimNag indicates an expected call of ClaimNag.
+func (mr *MockReminderExecutionRepositoryMockRecorder) ClaimNag(ctx, id, nagCount, naggedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNag", reflect.TypeOf((*MockReminderExecutionRepository)(nil).ClaimNag), ctx, id, nagCount, naggedAt)
+}
+
+// ClaimSnoozed mocks base method.
+func (m *MockReminderExecutionRepository) ClaimSnoozed(ctx context.Context, id uuid.UUID, now, leaseUntil time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimSnoozed", ctx, id, now, leaseUntil)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimSnoozed indicates an expected call of ClaimSnoozed.
+func (mr *MockReminderExecutionRepositoryMockRecorder) ClaimSnoozed(ctx, id, now, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSnoozed", reflect.TypeOf((*MockReminderExecutionRepository)(nil).ClaimSnoozed), ctx, id, now, leaseUntil)
+}
+
+// Confirm mocks base method.
+func (m *MockReminderExecutionRepository) Confirm(ctx context.Context, id uuid.UUID, takenAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, id, takenAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockReminderExecutionRepositoryMockRecorder) Confirm(ctx, id, takenAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockReminderExecutionRepository)(nil).Confirm), ctx, id, takenAt)
+}
+
+// CountAsNeededByReminderID mocks base method.
+func (m *MockReminderExecutionRepository) CountAsNeededByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAsNeededByReminderID", ctx, reminderID, fromDate, toDate)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAsNeededByReminderID indicates an expected call of CountAsNeededByReminderID.
+func (mr *MockReminderExecutionRepositoryMockRecorder) CountAsNeededByReminderID(ctx, reminderID, fromDate, toDate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAsNeededByReminderID", reflect.TypeOf((*MockReminderExecutionRepository)(nil).CountAsNeededByReminderID), ctx, reminderID, fromDate, toDate)
+}
+
+// CountByReminderID mocks base method.
+func (m *MockReminderExecutionRepository) CountByReminderID(ctx context.Context, reminderID uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByReminderID", ctx, reminderID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByReminderID indicates an expected call of CountByReminderID.
+func (mr *MockReminderExecutionRepositoryMockRecorder) CountByReminderID(ctx, reminderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByReminderID", reflect.TypeOf((*MockReminderExecutionRepository)(nil).CountByReminderID), ctx, reminderID)
+}
+
 // Create mocks base method.
 func (m *MockReminderExecutionRepository) Create(ctx context.Context, execution *entities.ReminderExecution) error {
 	m.ctrl.T.Helper()
@@ -58,6 +133,21 @@ func (mr *MockReminderExecutionRepositoryMockRecorder) Create(ctx, execution any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReminderExecutionRepository)(nil).Create), ctx, execution)
 }
 
+// GetByID mocks base method.
+func (m *MockReminderExecutionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetByID), ctx, id)
+}
+
 // GetByReminderID mocks base method.
 func (m *MockReminderExecutionRepository) GetByReminderID(ctx context.Context, reminderID uuid.UUID, limit int) ([]*entities.ReminderExecution, error) {
 	m.ctrl.T.Helper()
@@ -88,6 +178,96 @@ func (mr *MockReminderExecutionRepositoryMockRecorder) GetByUserID(ctx, userID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetByUserID), ctx, userID, limit)
 }
 
+// GetDueDeliveries mocks base method.
+func (m *MockReminderExecutionRepository) GetDueDeliveries(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueDeliveries", ctx, now)
+	ret0, _ := ret[0].([]*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueDeliveries indicates an expected call of GetDueDeliveries.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetDueDeliveries(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueDeliveries", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetDueDeliveries), ctx, now)
+}
+
+// GetDueEscalations mocks base method.
+func (m *MockReminderExecutionRepository) GetDueEscalations(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueEscalations", ctx, now)
+	ret0, _ := ret[0].([]*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueEscalations indicates an expected call of GetDueEscalations.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetDueEscalations(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueEscalations", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetDueEscalations), ctx, now)
+}
+
+// GetDueNags mocks base method.
+func (m *MockReminderExecutionRepository) GetDueNags(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueNags", ctx, now)
+	ret0, _ := ret[0].([]*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueNags indicates an expected call of GetDueNags.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetDueNags(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueNags", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetDueNags), ctx, now)
+}
+
+// GetDueSnoozed mocks base method.
+func (m *MockReminderExecutionRepository) GetDueSnoozed(ctx context.Context) ([]*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueSnoozed", ctx)
+	ret0, _ := ret[0].([]*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueSnoozed indicates an expected call of GetDueSnoozed.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetDueSnoozed(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueSnoozed", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetDueSnoozed), ctx)
+}
+
+// GetSentBetween mocks base method.
+func (m *MockReminderExecutionRepository) GetSentBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentBetween", ctx, userID, from, to)
+	ret0, _ := ret[0].([]*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSentBetween indicates an expected call of GetSentBetween.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetSentBetween(ctx, userID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentBetween", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetSentBetween), ctx, userID, from, to)
+}
+
+// GetSlotStatisticsByReminderID mocks base method.
+func (m *MockReminderExecutionRepository) GetSlotStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) ([]*repository.SlotStatistics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSlotStatisticsByReminderID", ctx, reminderID, fromDate, toDate)
+	ret0, _ := ret[0].([]*repository.SlotStatistics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSlotStatisticsByReminderID indicates an expected call of GetSlotStatisticsByReminderID.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetSlotStatisticsByReminderID(ctx, reminderID, fromDate, toDate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSlotStatisticsByReminderID", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetSlotStatisticsByReminderID), ctx, reminderID, fromDate, toDate)
+}
+
 // GetStatisticsByReminderID mocks base method.
 func (m *MockReminderExecutionRepository) GetStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (*repository.ExecutionStatistics, error) {
 	m.ctrl.T.Helper()
@@ -118,14 +298,190 @@ func (mr *MockReminderExecutionRepositoryMockRecorder) GetStatisticsByUserID(ctx
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatisticsByUserID", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetStatisticsByUserID), ctx, userID, fromDate, toDate)
 }
 
-// UpdateStatus mocks base method.
-func (m *MockReminderExecutionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.ExecutionStatus) error {
+// GetTakenBetween mocks base method.
+func (m *MockReminderExecutionRepository) GetTakenBetween(ctx context.Context, reminderID uuid.UUID, from, to time.Time) ([]*entities.ReminderExecution, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, status)
+	ret := m.ctrl.Call(m, "GetTakenBetween", ctx, reminderID, from, to)
+	ret0, _ := ret[0].([]*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTakenBetween indicates an expected call of GetTakenBetween.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetTakenBetween(ctx, reminderID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTakenBetween", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetTakenBetween), ctx, reminderID, from, to)
+}
+
+// GetUnansweredNear mocks base method.
+func (m *MockReminderExecutionRepository) GetUnansweredNear(ctx context.Context, reminderID uuid.UUID, at time.Time, window time.Duration) (*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnansweredNear", ctx, reminderID, at, window)
+	ret0, _ := ret[0].(*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnansweredNear indicates an expected call of GetUnansweredNear.
+func (mr *MockReminderExecutionRepositoryMockRecorder) GetUnansweredNear(ctx, reminderID, at, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnansweredNear", reflect.TypeOf((*MockReminderExecutionRepository)(nil).GetUnansweredNear), ctx, reminderID, at, window)
+}
+
+// LockDueDelivery mocks base method.
+func (m *MockReminderExecutionRepository) LockDueDelivery(ctx context.Context, id uuid.UUID, now time.Time) (*entities.ReminderExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockDueDelivery", ctx, id, now)
+	ret0, _ := ret[0].(*entities.ReminderExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockDueDelivery indicates an expected call of LockDueDelivery.
+func (mr *MockReminderExecutionRepositoryMockRecorder) LockDueDelivery(ctx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockDueDelivery", reflect.TypeOf((*MockReminderExecutionRepository)(nil).LockDueDelivery), ctx, id, now)
+}
+
+// MarkDelivered mocks base method.
+func (m *MockReminderExecutionRepository) MarkDelivered(ctx context.Context, id uuid.UUID, messageID int, deliveredAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDelivered", ctx, id, messageID, deliveredAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// MarkDelivered indicates an expected call of MarkDelivered.
+func (mr *MockReminderExecutionRepositoryMockRecorder) MarkDelivered(ctx, id, messageID, deliveredAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDelivered", reflect.TypeOf((*MockReminderExecutionRepository)(nil).MarkDelivered), ctx, id, messageID, deliveredAt)
+}
+
+// MarkDeliveryFailed mocks base method.
+func (m *MockReminderExecutionRepository) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, retryAt *time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDeliveryFailed", ctx, id, retryAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDeliveryFailed indicates an expected call of MarkDeliveryFailed.
+func (mr *MockReminderExecutionRepositoryMockRecorder) MarkDeliveryFailed(ctx, id, retryAt, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDeliveryFailed", reflect.TypeOf((*MockReminderExecutionRepository)(nil).MarkDeliveryFailed), ctx, id, retryAt, reason)
+}
+
+// MarkEscalated mocks base method.
+func (m *MockReminderExecutionRepository) MarkEscalated(ctx context.Context, id uuid.UUID, escalatedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEscalated", ctx, id, escalatedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkEscalated indicates an expected call of MarkEscalated.
+func (mr *MockReminderExecutionRepositoryMockRecorder) MarkEscalated(ctx, id, escalatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEscalated", reflect.TypeOf((*MockReminderExecutionRepository)(nil).MarkEscalated), ctx, id, escalatedAt)
+}
+
+// MissUnanswered mocks base method.
+func (m *MockReminderExecutionRepository) MissUnanswered(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MissUnanswered", ctx, deliveredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MissUnanswered indicates an expected call of MissUnanswered.
+func (mr *MockReminderExecutionRepositoryMockRecorder) MissUnanswered(ctx, deliveredBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MissUnanswered", reflect.TypeOf((*MockReminderExecutionRepository)(nil).MissUnanswered), ctx, deliveredBefore)
+}
+
+// MissUnansweredByReminderID mocks base method.
+func (m *MockReminderExecutionRepository) MissUnansweredByReminderID(ctx context.Context, reminderID uuid.UUID, deliveredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MissUnansweredByReminderID", ctx, reminderID, deliveredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MissUnansweredByReminderID indicates an expected call of MissUnansweredByReminderID.
+func (mr *MockReminderExecutionRepositoryMockRecorder) MissUnansweredByReminderID(ctx, reminderID, deliveredBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MissUnansweredByReminderID", reflect.TypeOf((*MockReminderExecutionRepository)(nil).MissUnansweredByReminderID), ctx, reminderID, deliveredBefore)
+}
+
+// RecordNag mocks base method.
+func (m *MockReminderExecutionRepository) RecordNag(ctx context.Context, id uuid.UUID, messageID int, naggedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordNag", ctx, id, messageID, naggedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordNag indicates an expected call of RecordNag.
+func (mr *MockReminderExecutionRepositoryMockRecorder) RecordNag(ctx, id, messageID, naggedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordNag", reflect.TypeOf((*MockReminderExecutionRepository)(nil).RecordNag), ctx, id, messageID, naggedAt)
+}
+
+// ReleaseNag mocks base method.
+func (m *MockReminderExecutionRepository) ReleaseNag(ctx context.Context, id uuid.UUID, nagCount int, naggedAt *time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseNag", ctx, id, nagCount, naggedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseNag indicates an expected call of ReleaseNag.
+func (mr *MockReminderExecutionRepositoryMockRecorder) ReleaseNag(ctx, id, nagCount, naggedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseNag", reflect.TypeOf((*MockReminderExecutionRepository)(nil).ReleaseNag), ctx, id, nagCount, naggedAt)
+}
+
+// Snooze mocks base method.
+func (m *MockReminderExecutionRepository) Snooze(ctx context.Context, id uuid.UUID, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snooze", ctx, id, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Snooze indicates an expected call of Snooze.
+func (mr *MockReminderExecutionRepositoryMockRecorder) Snooze(ctx, id, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snooze", reflect.TypeOf((*MockReminderExecutionRepository)(nil).Snooze), ctx, id, until)
+}
+
+// StartDeliveryAttempt mocks base method.
+func (m *MockReminderExecutionRepository) StartDeliveryAttempt(ctx context.Context, id uuid.UUID, leaseUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartDeliveryAttempt", ctx, id, leaseUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartDeliveryAttempt indicates an expected call of StartDeliveryAttempt.
+func (mr *MockReminderExecutionRepositoryMockRecorder) StartDeliveryAttempt(ctx, id, leaseUntil any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartDeliveryAttempt", reflect.TypeOf((*MockReminderExecutionRepository)(nil).StartDeliveryAttempt), ctx, id, leaseUntil)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockReminderExecutionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.ExecutionStatus) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, status)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateStatus indicates an expected call of UpdateStatus.
 func (mr *MockReminderExecutionRepositoryMockRecorder) UpdateStatus(ctx, id, status any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
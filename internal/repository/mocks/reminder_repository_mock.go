@@ -131,32 +131,33 @@ func (mr *MockReminderRepositoryMockRecorder) GetDueReminders(ctx any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueReminders", reflect.TypeOf((*MockReminderRepository)(nil).GetDueReminders), ctx)
 }
 
-// Update mocks base method.
-func (m *MockReminderRepository) Update(ctx context.Context, reminder *entities.Reminder) error {
+// LockDue mocks base method.
+func (m *MockReminderRepository) LockDue(ctx context.Context, id uuid.UUID, now time.Time) (*entities.Reminder, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, reminder)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "LockDue", ctx, id, now)
+	ret0, _ := ret[0].(*entities.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Update indicates an expected call of Update.
-func (mr *MockReminderRepositoryMockRecorder) Update(ctx, reminder any) *gomock.Call {
+// LockDue indicates an expected call of LockDue.
+func (mr *MockReminderRepositoryMockRecorder) LockDue(ctx, id, now any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockReminderRepository)(nil).Update), ctx, reminder)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockDue", reflect.TypeOf((*MockReminderRepository)(nil).LockDue), ctx, id, now)
 }
 
-// UpdateLastSentAt mocks base method.
-func (m *MockReminderRepository) UpdateLastSentAt(ctx context.Context, id uuid.UUID, lastSentAt time.Time) error {
+// Update mocks base method.
+func (m *MockReminderRepository) Update(ctx context.Context, reminder *entities.Reminder) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateLastSentAt", ctx, id, lastSentAt)
+	ret := m.ctrl.Call(m, "Update", ctx, reminder)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateLastSentAt indicates an expected call of UpdateLastSentAt.
-func (mr *MockReminderRepositoryMockRecorder) UpdateLastSentAt(ctx, id, lastSentAt any) *gomock.Call {
+// Update indicates an expected call of Update.
+func (mr *MockReminderRepositoryMockRecorder) Update(ctx, reminder any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastSentAt", reflect.TypeOf((*MockReminderRepository)(nil).UpdateLastSentAt), ctx, id, lastSentAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockReminderRepository)(nil).Update), ctx, reminder)
 }
 
 // UpdateNextSendAt mocks base method.
@@ -0,0 +1,160 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stock_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=stock_repository.go -destination=./mocks/stock_repository_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entities "github.com/Helltale/take-your-pills-on-time/internal/entities"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStockRepository is a mock of StockRepository interface.
+type MockStockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockStockRepositoryMockRecorder is the mock recorder for MockStockRepository.
+type MockStockRepositoryMockRecorder struct {
+	mock *MockStockRepository
+}
+
+// NewMockStockRepository creates a new mock instance.
+func NewMockStockRepository(ctrl *gomock.Controller) *MockStockRepository {
+	mock := &MockStockRepository{ctrl: ctrl}
+	mock.recorder = &MockStockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockRepository) EXPECT() *MockStockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockStockRepository) Consume(ctx context.Context, reminderID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, reminderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockStockRepositoryMockRecorder) Consume(ctx, reminderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockStockRepository)(nil).Consume), ctx, reminderID)
+}
+
+// Create mocks base method.
+func (m *MockStockRepository) Create(ctx context.Context, stock *entities.Stock) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, stock)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockStockRepositoryMockRecorder) Create(ctx, stock any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStockRepository)(nil).Create), ctx, stock)
+}
+
+// Delete mocks base method.
+func (m *MockStockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockStockRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStockRepository)(nil).Delete), ctx, id)
+}
+
+// GetByReminderID mocks base method.
+func (m *MockStockRepository) GetByReminderID(ctx context.Context, reminderID uuid.UUID) (*entities.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByReminderID", ctx, reminderID)
+	ret0, _ := ret[0].(*entities.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByReminderID indicates an expected call of GetByReminderID.
+func (mr *MockStockRepositoryMockRecorder) GetByReminderID(ctx, reminderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByReminderID", reflect.TypeOf((*MockStockRepository)(nil).GetByReminderID), ctx, reminderID)
+}
+
+// GetByUserID mocks base method.
+func (m *MockStockRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*entities.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockStockRepositoryMockRecorder) GetByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockStockRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// GetUnwarned mocks base method.
+func (m *MockStockRepository) GetUnwarned(ctx context.Context) ([]*entities.Stock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnwarned", ctx)
+	ret0, _ := ret[0].([]*entities.Stock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnwarned indicates an expected call of GetUnwarned.
+func (mr *MockStockRepositoryMockRecorder) GetUnwarned(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnwarned", reflect.TypeOf((*MockStockRepository)(nil).GetUnwarned), ctx)
+}
+
+// MarkWarned mocks base method.
+func (m *MockStockRepository) MarkWarned(ctx context.Context, id uuid.UUID, warnedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWarned", ctx, id, warnedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkWarned indicates an expected call of MarkWarned.
+func (mr *MockStockRepositoryMockRecorder) MarkWarned(ctx, id, warnedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWarned", reflect.TypeOf((*MockStockRepository)(nil).MarkWarned), ctx, id, warnedAt)
+}
+
+// Update mocks base method.
+func (m *MockStockRepository) Update(ctx context.Context, stock *entities.Stock) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, stock)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockStockRepositoryMockRecorder) Update(ctx, stock any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockStockRepository)(nil).Update), ctx, stock)
+}
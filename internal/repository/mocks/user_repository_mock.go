@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entities "github.com/Helltale/take-your-pills-on-time/internal/entities"
 	uuid "github.com/google/uuid"
@@ -42,6 +43,21 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimDigest mocks base method.
+func (m *MockUserRepository) ClaimDigest(ctx context.Context, id uuid.UUID, now, next time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDigest", ctx, id, now, next)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDigest indicates an expected call of ClaimDigest.
+func (mr *MockUserRepositoryMockRecorder) ClaimDigest(ctx, id, now, next any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDigest", reflect.TypeOf((*MockUserRepository)(nil).ClaimDigest), ctx, id, now, next)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(ctx context.Context, user *entities.User) error {
 	m.ctrl.T.Helper()
@@ -86,6 +102,21 @@ func (mr *MockUserRepositoryMockRecorder) GetByTelegramID(ctx, telegramID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTelegramID", reflect.TypeOf((*MockUserRepository)(nil).GetByTelegramID), ctx, telegramID)
 }
 
+// GetDueDigests mocks base method.
+func (m *MockUserRepository) GetDueDigests(ctx context.Context, now time.Time) ([]*entities.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueDigests", ctx, now)
+	ret0, _ := ret[0].([]*entities.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueDigests indicates an expected call of GetDueDigests.
+func (mr *MockUserRepositoryMockRecorder) GetDueDigests(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueDigests", reflect.TypeOf((*MockUserRepository)(nil).GetDueDigests), ctx, now)
+}
+
 // SetActive mocks base method.
 func (m *MockUserRepository) SetActive(ctx context.Context, telegramID int64, isActive bool) error {
 	m.ctrl.T.Helper()
@@ -113,3 +144,17 @@ func (mr *MockUserRepositoryMockRecorder) Update(ctx, user any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepository)(nil).Update), ctx, user)
 }
+
+// UpdateDigestNextAt mocks base method.
+func (m *MockUserRepository) UpdateDigestNextAt(ctx context.Context, id uuid.UUID, next time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDigestNextAt", ctx, id, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDigestNextAt indicates an expected call of UpdateDigestNextAt.
+func (mr *MockUserRepositoryMockRecorder) UpdateDigestNextAt(ctx, id, next any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDigestNextAt", reflect.TypeOf((*MockUserRepository)(nil).UpdateDigestNextAt), ctx, id, next)
+}
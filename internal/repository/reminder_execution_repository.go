@@ -6,24 +6,96 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Helltale/take-your-pills-on-time/internal/entities"
 )
 
 type ReminderExecutionRepository interface {
 	Create(ctx context.Context, execution *entities.ReminderExecution) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.ReminderExecution, error)
 	GetByReminderID(ctx context.Context, reminderID uuid.UUID, limit int) ([]*entities.ReminderExecution, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.ReminderExecution, error)
+	CountByReminderID(ctx context.Context, reminderID uuid.UUID) (int64, error)
 	GetStatisticsByUserID(ctx context.Context, userID uuid.UUID, fromDate, toDate time.Time) (*ExecutionStatistics, error)
 	GetStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (*ExecutionStatistics, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status entities.ExecutionStatus) error
+	GetSlotStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) ([]*SlotStatistics, error)
+	CountAsNeededByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (int64, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status entities.ExecutionStatus) (bool, error)
+	Confirm(ctx context.Context, id uuid.UUID, takenAt time.Time) (bool, error)
+	MissUnanswered(ctx context.Context, deliveredBefore time.Time) (int64, error)
+	MissUnansweredByReminderID(ctx context.Context, reminderID uuid.UUID, deliveredBefore time.Time) (int64, error)
+	GetUnansweredNear(ctx context.Context, reminderID uuid.UUID, at time.Time, window time.Duration) (*entities.ReminderExecution, error)
+	GetTakenBetween(ctx context.Context, reminderID uuid.UUID, from, to time.Time) ([]*entities.ReminderExecution, error)
+	GetSentBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.ReminderExecution, error)
+	Snooze(ctx context.Context, id uuid.UUID, until time.Time) error
+	GetDueSnoozed(ctx context.Context) ([]*entities.ReminderExecution, error)
+	ClaimSnoozed(ctx context.Context, id uuid.UUID, now, leaseUntil time.Time) (bool, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, messageID int, deliveredAt time.Time) error
+	GetDueDeliveries(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error)
+	LockDueDelivery(ctx context.Context, id uuid.UUID, now time.Time) (*entities.ReminderExecution, error)
+	StartDeliveryAttempt(ctx context.Context, id uuid.UUID, leaseUntil time.Time) error
+	MarkDeliveryFailed(ctx context.Context, id uuid.UUID, retryAt *time.Time, reason string) error
+	ClaimNag(ctx context.Context, id uuid.UUID, nagCount int, naggedAt time.Time) (bool, error)
+	ReleaseNag(ctx context.Context, id uuid.UUID, nagCount int, naggedAt *time.Time) (bool, error)
+	RecordNag(ctx context.Context, id uuid.UUID, messageID int, naggedAt time.Time) error
+	GetDueNags(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error)
+	GetDueEscalations(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error)
+	MarkEscalated(ctx context.Context, id uuid.UUID, escalatedAt time.Time) (bool, error)
 }
 
+// ExecutionStatistics counts the scheduled doses of a period by their
+// outcome. Every delivered dose is exactly one of pending, confirmed, skipped
+// or missed; pending doses are still waiting for an answer. Doses the user
+// logged without a reminder are not counted.
 type ExecutionStatistics struct {
-	TotalSent        int     `json:"total_sent"`
-	TotalConfirmed   int     `json:"total_confirmed"`
-	TotalSkipped     int     `json:"total_skipped"`
-	ConfirmationRate float64 `json:"confirmation_rate"`
+	TotalDelivered int     `json:"total_delivered"`
+	TotalPending   int     `json:"total_pending"`
+	TotalConfirmed int     `json:"total_confirmed"`
+	TotalSkipped   int     `json:"total_skipped"`
+	TotalMissed    int     `json:"total_missed"`
+	TotalSnoozed   int     `json:"total_snoozed"`
+	TotalOnTime    int     `json:"total_on_time"`
+	TotalLate      int     `json:"total_late"`
+	AdherenceRate  float64 `json:"adherence_rate"`
+	OnTimeRate     float64 `json:"on_time_rate"`
+	LateRate       float64 `json:"late_rate"`
+}
+
+// executionStatisticsColumns aggregates executions into ExecutionStatistics.
+// Its parameter is entities.OnTimeWindow in minutes; doses confirmed before
+// intake times were recorded are judged by their confirmation time.
+const executionStatisticsColumns = `
+	COUNT(*) as total_delivered,
+	COUNT(*) FILTER (WHERE status IN ('sent', 'snoozed')) as total_pending,
+	COUNT(*) FILTER (WHERE status = 'confirmed') as total_confirmed,
+	COUNT(*) FILTER (WHERE status = 'skipped') as total_skipped,
+	COUNT(*) FILTER (WHERE status = 'missed') as total_missed,
+	COUNT(*) FILTER (WHERE status = 'confirmed' AND COALESCE(taken_at, confirmed_at) <= sent_at + ? * INTERVAL '1 minute') as total_on_time,
+	COUNT(*) FILTER (WHERE status = 'confirmed' AND COALESCE(taken_at, confirmed_at) > sent_at + ? * INTERVAL '1 minute') as total_late,
+	COALESCE(SUM(snooze_count), 0) as total_snoozed
+`
+
+func onTimeWindowMinutes() int {
+	return int(entities.OnTimeWindow / time.Minute)
+}
+
+// calculateRates fills in the rates: adherence is the share of delivered
+// doses that were taken, so a dose still pending counts against it until it
+// is answered. On-time and late rates are shares of the taken doses.
+func (s *ExecutionStatistics) calculateRates() {
+	if s.TotalDelivered > 0 {
+		s.AdherenceRate = float64(s.TotalConfirmed) / float64(s.TotalDelivered) * 100
+	}
+	if s.TotalConfirmed > 0 {
+		s.OnTimeRate = float64(s.TotalOnTime) / float64(s.TotalConfirmed) * 100
+		s.LateRate = float64(s.TotalLate) / float64(s.TotalConfirmed) * 100
+	}
+}
+
+type SlotStatistics struct {
+	Slot string `json:"slot"`
+	ExecutionStatistics
 }
 
 type reminderExecutionRepository struct {
@@ -44,10 +116,25 @@ func (r *reminderExecutionRepository) Create(ctx context.Context, execution *ent
 	return r.db.WithContext(ctx).Create(execution).Error
 }
 
+func (r *reminderExecutionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.ReminderExecution, error) {
+	var execution entities.ReminderExecution
+	err := r.db.WithContext(ctx).First(&execution, "id = ?", id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &execution, nil
+}
+
+// GetByReminderID returns the reminder's history, newest first. Doses still
+// being delivered or given up on are left out: the user never saw them.
 func (r *reminderExecutionRepository) GetByReminderID(ctx context.Context, reminderID uuid.UUID, limit int) ([]*entities.ReminderExecution, error) {
 	var executions []*entities.ReminderExecution
 	query := r.db.WithContext(ctx).
-		Where("reminder_id = ?", reminderID).
+		Where("reminder_id = ? AND delivery_status = ?", reminderID, entities.DeliveryStatusDelivered).
 		Order("sent_at DESC")
 
 	if limit > 0 {
@@ -62,10 +149,11 @@ func (r *reminderExecutionRepository) GetByReminderID(ctx context.Context, remin
 	return executions, nil
 }
 
+// GetByUserID is GetByReminderID for all of the user's reminders.
 func (r *reminderExecutionRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.ReminderExecution, error) {
 	var executions []*entities.ReminderExecution
 	query := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+		Where("user_id = ? AND delivery_status = ?", userID, entities.DeliveryStatusDelivered).
 		Order("sent_at DESC")
 
 	if limit > 0 {
@@ -80,25 +168,37 @@ func (r *reminderExecutionRepository) GetByUserID(ctx context.Context, userID uu
 	return executions, nil
 }
 
+// CountByReminderID counts the reminder's scheduled doses that were
+// delivered or are still being delivered; doses given up as undeliverable
+// are left out.
+func (r *reminderExecutionRepository) CountByReminderID(ctx context.Context, reminderID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entities.ReminderExecution{}).
+		Where("reminder_id = ? AND source = ?", reminderID, entities.ExecutionSourceScheduled).
+		Where("delivery_status <> ?", entities.DeliveryStatusFailed).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (r *reminderExecutionRepository) GetStatisticsByUserID(ctx context.Context, userID uuid.UUID, fromDate, toDate time.Time) (*ExecutionStatistics, error) {
 	var stats ExecutionStatistics
 
 	err := r.db.WithContext(ctx).
 		Model(&entities.ReminderExecution{}).
-		Select(`
-			COUNT(*) FILTER (WHERE status = 'sent') as total_sent,
-			COUNT(*) FILTER (WHERE status = 'confirmed') as total_confirmed,
-			COUNT(*) FILTER (WHERE status = 'skipped') as total_skipped
-		`).
+		Select(executionStatisticsColumns, onTimeWindowMinutes(), onTimeWindowMinutes()).
 		Where("user_id = ? AND sent_at >= ? AND sent_at <= ?", userID, fromDate, toDate).
+		Scopes(scheduledDoses).
 		Scan(&stats).Error
 	if err != nil {
 		return nil, err
 	}
 
-	if stats.TotalSent > 0 {
-		stats.ConfirmationRate = float64(stats.TotalConfirmed) / float64(stats.TotalSent) * 100
-	}
+	stats.calculateRates()
 
 	return &stats, nil
 }
@@ -108,34 +208,393 @@ func (r *reminderExecutionRepository) GetStatisticsByReminderID(ctx context.Cont
 
 	err := r.db.WithContext(ctx).
 		Model(&entities.ReminderExecution{}).
-		Select(`
-			COUNT(*) FILTER (WHERE status = 'sent') as total_sent,
-			COUNT(*) FILTER (WHERE status = 'confirmed') as total_confirmed,
-			COUNT(*) FILTER (WHERE status = 'skipped') as total_skipped
-		`).
+		Select(executionStatisticsColumns, onTimeWindowMinutes(), onTimeWindowMinutes()).
 		Where("reminder_id = ? AND sent_at >= ? AND sent_at <= ?", reminderID, fromDate, toDate).
+		Scopes(scheduledDoses).
 		Scan(&stats).Error
 	if err != nil {
 		return nil, err
 	}
 
-	if stats.TotalSent > 0 {
-		stats.ConfirmationRate = float64(stats.TotalConfirmed) / float64(stats.TotalSent) * 100
-	}
+	stats.calculateRates()
 
 	return &stats, nil
 }
 
-func (r *reminderExecutionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.ExecutionStatus) error {
-	updates := map[string]interface{}{
+func (r *reminderExecutionRepository) GetSlotStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) ([]*SlotStatistics, error) {
+	var stats []*SlotStatistics
+
+	err := r.db.WithContext(ctx).
+		Model(&entities.ReminderExecution{}).
+		Select("slot,"+executionStatisticsColumns, onTimeWindowMinutes(), onTimeWindowMinutes()).
+		Where("reminder_id = ? AND slot IS NOT NULL AND sent_at >= ? AND sent_at <= ?", reminderID, fromDate, toDate).
+		Scopes(scheduledDoses).
+		Group("slot").
+		Order("slot ASC").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slot := range stats {
+		slot.calculateRates()
+	}
+
+	return stats, nil
+}
+
+// CountAsNeededByReminderID counts the as-needed intakes of the reminder
+// taken between fromDate and toDate.
+func (r *reminderExecutionRepository) CountAsNeededByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entities.ReminderExecution{}).
+		Where("reminder_id = ? AND source = ? AND status = ?", reminderID, entities.ExecutionSourceAsNeeded, entities.ExecutionStatusConfirmed).
+		Where("COALESCE(taken_at, confirmed_at) >= ? AND COALESCE(taken_at, confirmed_at) <= ?", fromDate, toDate).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateStatus moves an unanswered (sent or snoozed) execution to status and
+// reports whether it did. Executions that are already answered or missed are
+// left as they are.
+func (r *reminderExecutionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.ExecutionStatus) (bool, error) {
+	return r.answer(ctx, id, map[string]interface{}{
 		"status": status,
+	})
+}
+
+// Confirm marks an unanswered execution as taken at takenAt, recording the
+// confirmation time separately, and reports whether it did.
+func (r *reminderExecutionRepository) Confirm(ctx context.Context, id uuid.UUID, takenAt time.Time) (bool, error) {
+	return r.answer(ctx, id, map[string]interface{}{
+		"status":       entities.ExecutionStatusConfirmed,
+		"confirmed_at": time.Now(),
+		"taken_at":     takenAt,
+	})
+}
+
+// MissUnanswered marks delivered executions that are still unanswered and
+// were last delivered before deliveredBefore as missed, and returns how many
+// it marked.
+func (r *reminderExecutionRepository) MissUnanswered(ctx context.Context, deliveredBefore time.Time) (int64, error) {
+	return r.missUnanswered(r.db.WithContext(ctx), deliveredBefore)
+}
+
+// MissUnansweredByReminderID is MissUnanswered for the doses of one reminder.
+func (r *reminderExecutionRepository) MissUnansweredByReminderID(ctx context.Context, reminderID uuid.UUID, deliveredBefore time.Time) (int64, error) {
+	return r.missUnanswered(r.db.WithContext(ctx).Where("reminder_id = ?", reminderID), deliveredBefore)
+}
+
+func (r *reminderExecutionRepository) missUnanswered(db *gorm.DB, deliveredBefore time.Time) (int64, error) {
+	result := db.Model(&entities.ReminderExecution{}).
+		Where("status IN ? AND delivery_status = ?", unansweredStatuses, entities.DeliveryStatusDelivered).
+		Where("COALESCE(delivered_at, sent_at) < ?", deliveredBefore).
+		Update("status", entities.ExecutionStatusMissed)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// unansweredStatuses are the statuses of executions waiting for an answer.
+var unansweredStatuses = []entities.ExecutionStatus{entities.ExecutionStatusSent, entities.ExecutionStatusSnoozed}
+
+func (r *reminderExecutionRepository) answer(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ? AND status IN ?", id, unansweredStatuses).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetUnansweredNear returns the unanswered execution of the reminder sent
+// closest to at, no further than window away from it.
+func (r *reminderExecutionRepository) GetUnansweredNear(ctx context.Context, reminderID uuid.UUID, at time.Time, window time.Duration) (*entities.ReminderExecution, error) {
+	var executions []*entities.ReminderExecution
+	err := r.db.WithContext(ctx).
+		Where("reminder_id = ? AND status IN ?", reminderID, []entities.ExecutionStatus{entities.ExecutionStatusSent, entities.ExecutionStatusSnoozed}).
+		Where("sent_at BETWEEN ? AND ?", at.Add(-window), at.Add(window)).
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var closest *entities.ReminderExecution
+	for _, execution := range executions {
+		if closest == nil || absDuration(execution.SentAt.Sub(at)) < absDuration(closest.SentAt.Sub(at)) {
+			closest = execution
+		}
+	}
+	return closest, nil
+}
+
+// GetTakenBetween returns the reminder's doses taken after from and no later
+// than to, latest first.
+func (r *reminderExecutionRepository) GetTakenBetween(ctx context.Context, reminderID uuid.UUID, from, to time.Time) ([]*entities.ReminderExecution, error) {
+	var executions []*entities.ReminderExecution
+	err := r.db.WithContext(ctx).
+		Where("reminder_id = ? AND status = ?", reminderID, entities.ExecutionStatusConfirmed).
+		Where("COALESCE(taken_at, confirmed_at) > ? AND COALESCE(taken_at, confirmed_at) <= ?", from, to).
+		Order("COALESCE(taken_at, confirmed_at) DESC").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// GetSentBetween returns the user's scheduled executions sent between from
+// and to that reached them, oldest first.
+func (r *reminderExecutionRepository) GetSentBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.ReminderExecution, error) {
+	var executions []*entities.ReminderExecution
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND sent_at >= ? AND sent_at <= ?", userID, from, to).
+		Scopes(scheduledDoses).
+		Order("sent_at ASC").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// scheduledDoses limits a query to doses of scheduled reminders that reached
+// the user, the ones statistics are about.
+func scheduledDoses(db *gorm.DB) *gorm.DB {
+	return db.Where("source = ? AND delivery_status = ?", entities.ExecutionSourceScheduled, entities.DeliveryStatusDelivered)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func (r *reminderExecutionRepository) Snooze(ctx context.Context, id uuid.UUID, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":        entities.ExecutionStatusSnoozed,
+			"snoozed_until": until,
+			"snooze_count":  gorm.Expr("snooze_count + 1"),
+		}).Error
+}
+
+func (r *reminderExecutionRepository) GetDueSnoozed(ctx context.Context) ([]*entities.ReminderExecution, error) {
+	var executions []*entities.ReminderExecution
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND snoozed_until <= ?", entities.ExecutionStatusSnoozed, time.Now()).
+		Order("snoozed_until ASC").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
 	}
-	if status == entities.ExecutionStatusConfirmed {
-		now := time.Now()
-		updates["confirmed_at"] = &now
+
+	return executions, nil
+}
+
+// ClaimSnoozed takes a snoozed execution whose snooze is over for
+// re-delivery by moving its snooze to leaseUntil, so other instances skip it
+// and retry it only if this delivery never reports back. It reports whether
+// the execution was still due.
+func (r *reminderExecutionRepository) ClaimSnoozed(ctx context.Context, id uuid.UUID, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ? AND status = ? AND snoozed_until <= ?", id, entities.ExecutionStatusSnoozed, now).
+		Update("snoozed_until", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
 	}
+	return result.RowsAffected > 0, nil
+}
 
+// MarkDelivered records the Telegram message an execution was (re)delivered
+// in, completes its delivery and puts a snoozed execution back into the sent
+// state. An answer given in the meantime is kept.
+func (r *reminderExecutionRepository) MarkDelivered(ctx context.Context, id uuid.UUID, messageID int, deliveredAt time.Time) error {
 	return r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
 		Where("id = ?", id).
-		Updates(updates).Error
+		Updates(map[string]interface{}{
+			"status":          gorm.Expr("CASE WHEN status = ? THEN ? ELSE status END", entities.ExecutionStatusSnoozed, entities.ExecutionStatusSent),
+			"message_id":      messageID,
+			"delivered_at":    deliveredAt,
+			"delivery_status": entities.DeliveryStatusDelivered,
+			"next_attempt_at": nil,
+			"delivery_error":  nil,
+		}).Error
+}
+
+// GetDueDeliveries returns executions whose delivery is pending and whose
+// next attempt is due.
+func (r *reminderExecutionRepository) GetDueDeliveries(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error) {
+	var executions []*entities.ReminderExecution
+	err := r.db.WithContext(ctx).
+		Where("delivery_status = ? AND next_attempt_at <= ?", entities.DeliveryStatusPending, now).
+		Order("next_attempt_at ASC").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// LockDueDelivery locks the execution if its delivery attempt is still due
+// and no other transaction holds it, and returns nil otherwise. Like
+// ReminderRepository.LockDue it is meant for Repository.Transaction.
+func (r *reminderExecutionRepository) LockDueDelivery(ctx context.Context, id uuid.UUID, now time.Time) (*entities.ReminderExecution, error) {
+	var execution entities.ReminderExecution
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND delivery_status = ? AND next_attempt_at <= ?", id, entities.DeliveryStatusPending, now).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Limit(1).
+		Find(&execution)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &execution, nil
+}
+
+// StartDeliveryAttempt counts an attempt and holds the delivery until
+// leaseUntil, so other instances retry it only if this attempt never
+// reports back.
+func (r *reminderExecutionRepository) StartDeliveryAttempt(ctx context.Context, id uuid.UUID, leaseUntil time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"delivery_attempts": gorm.Expr("delivery_attempts + 1"),
+			"next_attempt_at":   leaseUntil,
+		}).Error
+}
+
+// MarkDeliveryFailed records a failed attempt. The delivery is retried at
+// retryAt or, when it is nil, given up as failed.
+func (r *reminderExecutionRepository) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, retryAt *time.Time, reason string) error {
+	status := entities.DeliveryStatusPending
+	if retryAt == nil {
+		status = entities.DeliveryStatusFailed
+	}
+
+	return r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ? AND delivery_status = ?", id, entities.DeliveryStatusPending).
+		Updates(map[string]interface{}{
+			"delivery_status": status,
+			"next_attempt_at": retryAt,
+			"delivery_error":  reason,
+		}).Error
+}
+
+// ClaimNag counts the next repeat of an unanswered execution repeated
+// nagCount times so far and restarts its nag interval at naggedAt. The
+// delivery time is kept, so repeats do not put off the caregiver alert. It
+// reports whether the execution was still waiting for that repeat.
+func (r *reminderExecutionRepository) ClaimNag(ctx context.Context, id uuid.UUID, nagCount int, naggedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ? AND status = ? AND nag_count = ?", id, entities.ExecutionStatusSent, nagCount).
+		Updates(map[string]interface{}{
+			"nagged_at": naggedAt,
+			"nag_count": gorm.Expr("nag_count + 1"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseNag takes back a repeat claimed by ClaimNag from an execution
+// repeated nagCount times before it, restoring its previous repeat time so
+// that the repeat is due again. It reports whether the claim was still there
+// to take back.
+func (r *reminderExecutionRepository) ReleaseNag(ctx context.Context, id uuid.UUID, nagCount int, naggedAt *time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ? AND nag_count = ?", id, nagCount+1).
+		Updates(map[string]interface{}{
+			"nagged_at": naggedAt,
+			"nag_count": nagCount,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecordNag stores the Telegram message a claimed repeat was sent in.
+func (r *reminderExecutionRepository) RecordNag(ctx context.Context, id uuid.UUID, messageID int, naggedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"message_id": messageID,
+			"nagged_at":  naggedAt,
+		}).Error
+}
+
+// GetDueNags returns unanswered executions of nagging reminders whose last
+// delivery or repeat is at least one nag interval old.
+func (r *reminderExecutionRepository) GetDueNags(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error) {
+	var executions []*entities.ReminderExecution
+	err := r.db.WithContext(ctx).
+		Joins("INNER JOIN reminders ON reminder_executions.reminder_id = reminders.id").
+		Where("reminder_executions.status = ? AND reminder_executions.delivery_status = ?", entities.ExecutionStatusSent, entities.DeliveryStatusDelivered).
+		Where("reminders.nag_interval_minutes > 0 AND reminders.nag_max_repeats IS NOT NULL").
+		Where("COALESCE(reminder_executions.nagged_at, reminder_executions.delivered_at, reminder_executions.sent_at) + reminders.nag_interval_minutes * INTERVAL '1 minute' <= ?", now).
+		Order("reminder_executions.sent_at ASC").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// GetDueEscalations returns delivered but unconfirmed executions of users
+// with at least one caregiver that have been pending longer than the user's
+// caregiver delay and were not escalated yet. Only doses delivered after a
+// caregiver accepted the invite are escalated, so a new caregiver is not
+// told about the patient's whole past.
+func (r *reminderExecutionRepository) GetDueEscalations(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error) {
+	var executions []*entities.ReminderExecution
+	err := r.db.WithContext(ctx).
+		Joins("INNER JOIN users ON reminder_executions.user_id = users.id").
+		Where("reminder_executions.status IN ?", []entities.ExecutionStatus{
+			entities.ExecutionStatusSent,
+			entities.ExecutionStatusSnoozed,
+			entities.ExecutionStatusMissed,
+		}).
+		Where("reminder_executions.delivery_status = ?", entities.DeliveryStatusDelivered).
+		Where("reminder_executions.escalated_at IS NULL").
+		Where("COALESCE(reminder_executions.delivered_at, reminder_executions.sent_at) + COALESCE(users.caregiver_delay_minutes, ?) * INTERVAL '1 minute' <= ?", int(entities.DefaultCaregiverDelay/time.Minute), now).
+		Where(`EXISTS (SELECT 1 FROM caregivers WHERE caregivers.patient_id = reminder_executions.user_id AND caregivers.status = ?
+			AND caregivers.accepted_at <= COALESCE(reminder_executions.delivered_at, reminder_executions.sent_at))`, entities.CaregiverStatusAccepted).
+		Order("reminder_executions.sent_at ASC").
+		Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// MarkEscalated records that the execution was escalated and reports whether
+// it had not been yet.
+func (r *reminderExecutionRepository) MarkEscalated(ctx context.Context, id uuid.UUID, escalatedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.ReminderExecution{}).
+		Where("id = ? AND escalated_at IS NULL", id).
+		Update("escalated_at", escalatedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
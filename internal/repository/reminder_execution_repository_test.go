@@ -0,0 +1,439 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+)
+
+// executionFixture inserts executions of one user and reminder and removes
+// them when the test ends.
+type executionFixture struct {
+	t          *testing.T
+	repo       ReminderExecutionRepository
+	userID     uuid.UUID
+	reminderID uuid.UUID
+}
+
+func newExecutionFixture(t *testing.T, db *gorm.DB) *executionFixture {
+	f := &executionFixture{
+		t:          t,
+		repo:       NewReminderExecutionRepository(db),
+		userID:     uuid.New(),
+		reminderID: uuid.New(),
+	}
+	t.Cleanup(func() {
+		db.Where("user_id = ?", f.userID).Delete(&entities.ReminderExecution{})
+	})
+	return f
+}
+
+func (f *executionFixture) add(status entities.ExecutionStatus, sentAt time.Time, takenAfter time.Duration, slot *string) *entities.ReminderExecution {
+	f.t.Helper()
+
+	execution := &entities.ReminderExecution{
+		ReminderID: f.reminderID,
+		UserID:     f.userID,
+		Status:     status,
+		Slot:       slot,
+		SentAt:     sentAt,
+	}
+	if status == entities.ExecutionStatusConfirmed {
+		takenAt := sentAt.Add(takenAfter)
+		execution.TakenAt = &takenAt
+		execution.ConfirmedAt = &takenAt
+	}
+	if status == entities.ExecutionStatusSnoozed {
+		execution.SnoozeCount = 2
+	}
+
+	require.NoError(f.t, f.repo.Create(context.Background(), execution))
+	return execution
+}
+
+func TestReminderExecutionRepository_Statistics(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	fromDate := now.AddDate(0, 0, -7)
+
+	t.Run("counts every outcome separately", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		f.add(entities.ExecutionStatusConfirmed, now.Add(-6*time.Hour), 10*time.Minute, nil)
+		f.add(entities.ExecutionStatusConfirmed, now.Add(-5*time.Hour), 2*time.Hour, nil)
+		f.add(entities.ExecutionStatusSkipped, now.Add(-4*time.Hour), 0, nil)
+		f.add(entities.ExecutionStatusMissed, now.Add(-3*time.Hour), 0, nil)
+		f.add(entities.ExecutionStatusSent, now.Add(-2*time.Hour), 0, nil)
+		f.add(entities.ExecutionStatusSnoozed, now.Add(-time.Hour), 0, nil)
+		f.add(entities.ExecutionStatusConfirmed, now.AddDate(0, 0, -10), 0, nil)
+
+		stats, err := f.repo.GetStatisticsByUserID(ctx, f.userID, fromDate, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, 6, stats.TotalDelivered)
+		assert.Equal(t, 2, stats.TotalPending)
+		assert.Equal(t, 2, stats.TotalConfirmed)
+		assert.Equal(t, 1, stats.TotalSkipped)
+		assert.Equal(t, 1, stats.TotalMissed)
+		assert.Equal(t, 2, stats.TotalSnoozed)
+		assert.Equal(t, 1, stats.TotalOnTime)
+		assert.Equal(t, 1, stats.TotalLate)
+		assert.InDelta(t, 100.0/3, stats.AdherenceRate, 0.01)
+		assert.InDelta(t, 50.0, stats.OnTimeRate, 0.01)
+		assert.InDelta(t, 50.0, stats.LateRate, 0.01)
+	})
+
+	t.Run("everything confirmed is full adherence", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		for i := 1; i <= 3; i++ {
+			f.add(entities.ExecutionStatusConfirmed, now.Add(-time.Duration(i)*time.Hour), time.Minute, nil)
+		}
+
+		stats, err := f.repo.GetStatisticsByReminderID(ctx, f.reminderID, fromDate, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, stats.TotalDelivered)
+		assert.Zero(t, stats.TotalPending)
+		assert.Equal(t, 100.0, stats.AdherenceRate)
+	})
+
+	t.Run("leaves out logged doses", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		f.add(entities.ExecutionStatusSkipped, now.Add(-3*time.Hour), 0, nil)
+		takenAt := now.Add(-time.Hour)
+		require.NoError(t, f.repo.Create(ctx, &entities.ReminderExecution{
+			ReminderID:  f.reminderID,
+			UserID:      f.userID,
+			Status:      entities.ExecutionStatusConfirmed,
+			Source:      entities.ExecutionSourceLogged,
+			SentAt:      takenAt,
+			ConfirmedAt: &takenAt,
+			TakenAt:     &takenAt,
+		}))
+
+		stats, err := f.repo.GetStatisticsByUserID(ctx, f.userID, fromDate, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.TotalDelivered)
+		assert.Zero(t, stats.TotalConfirmed)
+
+		count, err := f.repo.CountByReminderID(ctx, f.reminderID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("counts as-needed intakes separately", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		f.add(entities.ExecutionStatusConfirmed, now.Add(-3*time.Hour), time.Minute, nil)
+		for _, takenAt := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Hour)} {
+			require.NoError(t, f.repo.Create(ctx, &entities.ReminderExecution{
+				ReminderID:  f.reminderID,
+				UserID:      f.userID,
+				Status:      entities.ExecutionStatusConfirmed,
+				Source:      entities.ExecutionSourceAsNeeded,
+				SentAt:      takenAt,
+				ConfirmedAt: &takenAt,
+				TakenAt:     &takenAt,
+			}))
+		}
+
+		stats, err := f.repo.GetStatisticsByUserID(ctx, f.userID, fromDate, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.TotalDelivered)
+		assert.Equal(t, 1, stats.TotalConfirmed)
+
+		intakes, err := f.repo.CountAsNeededByReminderID(ctx, f.reminderID, fromDate, now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), intakes)
+	})
+
+	t.Run("no doses", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+
+		stats, err := f.repo.GetStatisticsByUserID(ctx, f.userID, fromDate, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, ExecutionStatistics{}, *stats)
+	})
+
+	t.Run("groups by time of intake", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		morning, evening := "08:00", "20:00"
+		f.add(entities.ExecutionStatusConfirmed, now.Add(-30*time.Hour), time.Minute, &morning)
+		f.add(entities.ExecutionStatusConfirmed, now.Add(-6*time.Hour), time.Minute, &morning)
+		f.add(entities.ExecutionStatusSkipped, now.Add(-18*time.Hour), 0, &evening)
+		f.add(entities.ExecutionStatusConfirmed, now.Add(-2*time.Hour), time.Minute, nil)
+
+		slots, err := f.repo.GetSlotStatisticsByReminderID(ctx, f.reminderID, fromDate, now)
+		require.NoError(t, err)
+		require.Len(t, slots, 2)
+
+		assert.Equal(t, morning, slots[0].Slot)
+		assert.Equal(t, 2, slots[0].TotalDelivered)
+		assert.Equal(t, 100.0, slots[0].AdherenceRate)
+		assert.Equal(t, evening, slots[1].Slot)
+		assert.Equal(t, 1, slots[1].TotalSkipped)
+		assert.Zero(t, slots[1].AdherenceRate)
+	})
+}
+
+func TestReminderExecutionRepository_Delivery(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	pending := func(f *executionFixture, nextAttemptAt time.Time) *entities.ReminderExecution {
+		t.Helper()
+
+		execution := &entities.ReminderExecution{
+			ReminderID:       f.reminderID,
+			UserID:           f.userID,
+			Status:           entities.ExecutionStatusSent,
+			SentAt:           now.Add(-time.Hour),
+			DeliveryStatus:   entities.DeliveryStatusPending,
+			DeliveryAttempts: 1,
+			NextAttemptAt:    &nextAttemptAt,
+		}
+		require.NoError(t, f.repo.Create(ctx, execution))
+		return execution
+	}
+
+	t.Run("returns pending deliveries that are due", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		due := pending(f, now.Add(-time.Minute))
+		pending(f, now.Add(time.Minute))
+		f.add(entities.ExecutionStatusSent, now.Add(-time.Hour), 0, nil)
+
+		executions, err := f.repo.GetDueDeliveries(ctx, now)
+		require.NoError(t, err)
+
+		var ids []uuid.UUID
+		for _, execution := range executions {
+			if execution.UserID == f.userID {
+				ids = append(ids, execution.ID)
+			}
+		}
+		assert.Equal(t, []uuid.UUID{due.ID}, ids)
+	})
+
+	t.Run("retries a failed attempt until it is given up", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		execution := pending(f, now.Add(-time.Minute))
+
+		locked, err := f.repo.LockDueDelivery(ctx, execution.ID, now)
+		require.NoError(t, err)
+		require.NotNil(t, locked)
+		require.NoError(t, f.repo.StartDeliveryAttempt(ctx, execution.ID, now.Add(5*time.Minute)))
+
+		locked, err = f.repo.LockDueDelivery(ctx, execution.ID, now)
+		require.NoError(t, err)
+		assert.Nil(t, locked, "an attempt in progress is not due")
+
+		retryAt := now.Add(2 * time.Minute)
+		require.NoError(t, f.repo.MarkDeliveryFailed(ctx, execution.ID, &retryAt, "Bad Gateway"))
+
+		stored, err := f.repo.GetByID(ctx, execution.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.DeliveryStatusPending, stored.DeliveryStatus)
+		assert.Equal(t, 2, stored.DeliveryAttempts)
+		assert.WithinDuration(t, retryAt, *stored.NextAttemptAt, time.Second)
+		assert.Equal(t, "Bad Gateway", *stored.DeliveryError)
+
+		require.NoError(t, f.repo.MarkDeliveryFailed(ctx, execution.ID, nil, "Forbidden: bot was blocked by the user"))
+
+		stored, err = f.repo.GetByID(ctx, execution.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.DeliveryStatusFailed, stored.DeliveryStatus)
+		assert.Nil(t, stored.NextAttemptAt)
+	})
+
+	t.Run("delivery keeps an answer given meanwhile", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		execution := pending(f, now.Add(time.Minute))
+
+		answered, err := f.repo.Confirm(ctx, execution.ID, now)
+		require.NoError(t, err)
+		require.True(t, answered)
+		require.NoError(t, f.repo.MarkDelivered(ctx, execution.ID, 42, now))
+
+		stored, err := f.repo.GetByID(ctx, execution.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entities.ExecutionStatusConfirmed, stored.Status)
+		assert.Equal(t, entities.DeliveryStatusDelivered, stored.DeliveryStatus)
+		assert.Nil(t, stored.NextAttemptAt)
+	})
+
+	t.Run("statistics count delivered doses only", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		f.add(entities.ExecutionStatusConfirmed, now.Add(-2*time.Hour), time.Minute, nil)
+		pending(f, now.Add(time.Minute))
+		failed := pending(f, now.Add(-time.Minute))
+		require.NoError(t, f.repo.MarkDeliveryFailed(ctx, failed.ID, nil, "Forbidden"))
+
+		stats, err := f.repo.GetStatisticsByUserID(ctx, f.userID, now.AddDate(0, 0, -7), now)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, stats.TotalDelivered)
+		assert.Zero(t, stats.TotalPending)
+		assert.Equal(t, 100.0, stats.AdherenceRate)
+
+		count, err := f.repo.CountByReminderID(ctx, f.reminderID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count, "a dose still being delivered counts towards the course")
+	})
+
+	t.Run("history shows delivered doses only", func(t *testing.T) {
+		f := newExecutionFixture(t, db)
+		delivered := f.add(entities.ExecutionStatusSent, now.Add(-2*time.Hour), 0, nil)
+		pending(f, now.Add(time.Minute))
+		failed := pending(f, now.Add(-time.Minute))
+		require.NoError(t, f.repo.MarkDeliveryFailed(ctx, failed.ID, nil, "Forbidden"))
+
+		executions, err := f.repo.GetByReminderID(ctx, f.reminderID, 10)
+		require.NoError(t, err)
+		require.Len(t, executions, 1)
+		assert.Equal(t, delivered.ID, executions[0].ID)
+
+		executions, err = f.repo.GetByUserID(ctx, f.userID, 10)
+		require.NoError(t, err)
+		require.Len(t, executions, 1)
+		assert.Equal(t, delivered.ID, executions[0].ID)
+	})
+}
+
+func TestReminderExecutionRepository_MissUnanswered(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	f := newExecutionFixture(t, db)
+	stale := f.add(entities.ExecutionStatusSent, now.Add(-3*time.Hour), 0, nil)
+	snoozed := f.add(entities.ExecutionStatusSnoozed, now.Add(-3*time.Hour), 0, nil)
+	recent := f.add(entities.ExecutionStatusSent, now.Add(-time.Minute), 0, nil)
+	answered := f.add(entities.ExecutionStatusConfirmed, now.Add(-3*time.Hour), time.Minute, nil)
+
+	other := newExecutionFixture(t, db)
+	otherStale := other.add(entities.ExecutionStatusSent, now.Add(-3*time.Hour), 0, nil)
+
+	count, err := f.repo.MissUnansweredByReminderID(ctx, f.reminderID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	want := map[uuid.UUID]entities.ExecutionStatus{
+		stale.ID:      entities.ExecutionStatusMissed,
+		snoozed.ID:    entities.ExecutionStatusMissed,
+		recent.ID:     entities.ExecutionStatusSent,
+		answered.ID:   entities.ExecutionStatusConfirmed,
+		otherStale.ID: entities.ExecutionStatusSent,
+	}
+	for id, status := range want {
+		stored, err := f.repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, status, stored.Status, "execution %s", id)
+	}
+
+	_, err = f.repo.MissUnanswered(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+
+	stored, err := f.repo.GetByID(ctx, otherStale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ExecutionStatusMissed, stored.Status)
+}
+
+func TestReminderExecutionRepository_GetDueEscalations(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	user := newTestUser(t, db)
+	caregiverID := uuid.New()
+	acceptedAt := now.Add(-3 * time.Hour)
+	caregiver := &entities.Caregiver{
+		PatientID:   user.ID,
+		CaregiverID: &caregiverID,
+		InviteToken: uuid.NewString(),
+		Status:      entities.CaregiverStatusAccepted,
+		AcceptedAt:  &acceptedAt,
+	}
+	require.NoError(t, db.Create(caregiver).Error)
+	t.Cleanup(func() {
+		db.Delete(&entities.Caregiver{}, "id = ?", caregiver.ID)
+	})
+
+	f := newExecutionFixture(t, db)
+	f.userID = user.ID
+
+	due := f.add(entities.ExecutionStatusSent, now.Add(-2*time.Hour), 0, nil)
+
+	// Left unanswered months before the caregiver joined.
+	f.add(entities.ExecutionStatusMissed, now.AddDate(0, -3, 0), 0, nil)
+
+	pending := f.add(entities.ExecutionStatusSent, now.Add(-2*time.Hour), 0, nil)
+	require.NoError(t, db.Model(pending).Update("delivery_status", entities.DeliveryStatusPending).Error)
+
+	failed := f.add(entities.ExecutionStatusSent, now.Add(-2*time.Hour), 0, nil)
+	require.NoError(t, db.Model(failed).Update("delivery_status", entities.DeliveryStatusFailed).Error)
+
+	// Scheduled two hours ago but delivered only ten minutes ago after
+	// retries: the caregiver delay has not passed since the patient saw it.
+	late := f.add(entities.ExecutionStatusSent, now.Add(-2*time.Hour), 0, nil)
+	require.NoError(t, db.Model(late).Update("delivered_at", now.Add(-10*time.Minute)).Error)
+
+	executions, err := f.repo.GetDueEscalations(ctx, now)
+	require.NoError(t, err)
+
+	var ids []uuid.UUID
+	for _, execution := range executions {
+		ids = append(ids, execution.ID)
+	}
+	assert.Equal(t, []uuid.UUID{due.ID}, ids)
+}
+
+func TestReminderExecutionRepository_ClaimNag(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	f := newExecutionFixture(t, db)
+	deliveredAt := now.Add(-2 * time.Hour)
+	execution := f.add(entities.ExecutionStatusSent, deliveredAt, 0, nil)
+	require.NoError(t, db.Model(execution).Update("delivered_at", deliveredAt).Error)
+
+	claimed, err := f.repo.ClaimNag(ctx, execution.ID, 0, now)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = f.repo.ClaimNag(ctx, execution.ID, 0, now)
+	require.NoError(t, err)
+	assert.False(t, claimed, "the repeat is claimed once")
+
+	stored, err := f.repo.GetByID(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.NagCount)
+	assert.True(t, stored.NaggedAt.Equal(now))
+	assert.True(t, stored.DeliveredAt.Equal(deliveredAt), "a repeat keeps the delivery time the caregiver delay is measured from")
+
+	released, err := f.repo.ReleaseNag(ctx, execution.ID, 0, nil)
+	require.NoError(t, err)
+	assert.True(t, released)
+
+	released, err = f.repo.ReleaseNag(ctx, execution.ID, 0, nil)
+	require.NoError(t, err)
+	assert.False(t, released, "a repeat is released once")
+
+	stored, err = f.repo.GetByID(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.NagCount)
+	assert.Nil(t, stored.NaggedAt)
+}
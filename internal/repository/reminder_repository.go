@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Helltale/take-your-pills-on-time/internal/entities"
 )
@@ -16,10 +17,10 @@ type ReminderRepository interface {
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Reminder, error)
 	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Reminder, error)
 	GetDueReminders(ctx context.Context) ([]*entities.Reminder, error)
+	LockDue(ctx context.Context, id uuid.UUID, now time.Time) (*entities.Reminder, error)
 	Update(ctx context.Context, reminder *entities.Reminder) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdateNextSendAt(ctx context.Context, id uuid.UUID, nextSendAt time.Time) error
-	UpdateLastSentAt(ctx context.Context, id uuid.UUID, lastSentAt time.Time) error
 }
 
 type reminderRepository struct {
@@ -78,13 +79,13 @@ func (r *reminderRepository) GetActiveByUserID(ctx context.Context, userID uuid.
 	return reminders, nil
 }
 
+// GetDueReminders returns active reminders whose send time has come.
+// As-needed reminders are never due.
 func (r *reminderRepository) GetDueReminders(ctx context.Context) ([]*entities.Reminder, error) {
 	var reminders []*entities.Reminder
-	now := time.Now()
 
 	err := r.db.WithContext(ctx).
-		Joins("INNER JOIN users ON reminders.user_id = users.id").
-		Where("reminders.is_active = ? AND users.is_active = ? AND (reminders.next_send_at IS NULL OR reminders.next_send_at <= ?)", true, true, now).
+		Scopes(dueReminders(time.Now())).
 		Order("reminders.next_send_at ASC NULLS LAST").
 		Find(&reminders).Error
 	if err != nil {
@@ -94,6 +95,44 @@ func (r *reminderRepository) GetDueReminders(ctx context.Context) ([]*entities.R
 	return reminders, nil
 }
 
+// LockDue locks the reminder if it is still due and no other transaction
+// holds it, and returns nil otherwise, so each occurrence is claimed by one
+// instance only. The lock lasts until the end of the transaction and is
+// meant to be taken inside Repository.Transaction.
+func (r *reminderRepository) LockDue(ctx context.Context, id uuid.UUID, now time.Time) (*entities.Reminder, error) {
+	var reminder entities.Reminder
+	result := r.db.WithContext(ctx).
+		Scopes(dueReminders(now)).
+		Where("reminders.id = ?", id).
+		Clauses(clause.Locking{
+			Strength: "UPDATE",
+			Table:    clause.Table{Name: "reminders"},
+			Options:  "SKIP LOCKED",
+		}).
+		Limit(1).
+		Find(&reminder)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &reminder, nil
+}
+
+// dueReminders selects active reminders of active users whose send time has
+// come. As-needed reminders are never due.
+func dueReminders(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("INNER JOIN users ON reminders.user_id = users.id").
+			Where("reminders.is_active = ? AND users.is_active = ? AND (reminders.next_send_at IS NULL OR reminders.next_send_at <= ?)", true, true, now).
+			Where("reminders.starts_at IS NULL OR reminders.starts_at <= ?", now).
+			Where("reminders.type <> ?", entities.ReminderTypeAsNeeded)
+	}
+}
+
 func (r *reminderRepository) Update(ctx context.Context, reminder *entities.Reminder) error {
 	reminder.UpdatedAt = time.Now()
 	return r.db.WithContext(ctx).Save(reminder).Error
@@ -111,12 +150,3 @@ func (r *reminderRepository) UpdateNextSendAt(ctx context.Context, id uuid.UUID,
 			"updated_at":   time.Now(),
 		}).Error
 }
-
-func (r *reminderRepository) UpdateLastSentAt(ctx context.Context, id uuid.UUID, lastSentAt time.Time) error {
-	return r.db.WithContext(ctx).Model(&entities.Reminder{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"last_sent_at": lastSentAt,
-			"updated_at":   time.Now(),
-		}).Error
-}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+)
+
+// newTestUser inserts an active user and removes them with their reminders
+// and executions when the test ends.
+func newTestUser(t *testing.T, db *gorm.DB) *entities.User {
+	t.Helper()
+
+	user := &entities.User{
+		TelegramID: rand.Int63n(1 << 40),
+		FirstName:  "Test",
+		IsActive:   true,
+	}
+	require.NoError(t, NewUserRepository(db).Create(context.Background(), user))
+
+	t.Cleanup(func() {
+		db.Where("user_id = ?", user.ID).Delete(&entities.ReminderExecution{})
+		db.Where("user_id = ?", user.ID).Delete(&entities.Reminder{})
+		db.Delete(&entities.User{}, "id = ?", user.ID)
+	})
+	return user
+}
+
+func newDueReminder(t *testing.T, db *gorm.DB, user *entities.User, nextSendAt time.Time) *entities.Reminder {
+	t.Helper()
+
+	reminder := &entities.Reminder{
+		UserID:     user.ID,
+		Title:      "Витамин D",
+		Type:       entities.ReminderTypeDaily,
+		TimesOfDay: entities.TimesOfDay{"09:00"},
+		IsActive:   true,
+		NextSendAt: &nextSendAt,
+	}
+	require.NoError(t, NewReminderRepository(db).Create(context.Background(), reminder))
+	return reminder
+}
+
+// claimDue records a dose of the reminder and moves it a day ahead if it is
+// still due and not locked, the way the scheduler claims an occurrence. It
+// reports whether the reminder was claimed.
+func claimDue(ctx context.Context, repo *Repository, id uuid.UUID, now time.Time) (bool, error) {
+	claimed := false
+	err := repo.Transaction(ctx, func(tx *Repository) error {
+		reminder, err := tx.Reminder.LockDue(ctx, id, now)
+		if err != nil || reminder == nil {
+			return err
+		}
+
+		execution := &entities.ReminderExecution{
+			ReminderID: reminder.ID,
+			UserID:     reminder.UserID,
+			Status:     entities.ExecutionStatusSent,
+			SentAt:     time.Now(),
+		}
+		if err := tx.ReminderExecution.Create(ctx, execution); err != nil {
+			return err
+		}
+		if err := tx.Reminder.UpdateNextSendAt(ctx, reminder.ID, reminder.NextSendAt.Add(24*time.Hour)); err != nil {
+			return err
+		}
+
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+func TestReminderRepository_LockDue(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("claims a due reminder once", func(t *testing.T) {
+		user := newTestUser(t, db)
+		due := newDueReminder(t, db, user, now.Add(-time.Minute))
+
+		claimed, err := claimDue(ctx, repo, due.ID, now)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+
+		stored, err := repo.Reminder.GetByID(ctx, due.ID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, due.NextSendAt.Add(24*time.Hour), *stored.NextSendAt, time.Second)
+
+		claimed, err = claimDue(ctx, repo, due.ID, now)
+		require.NoError(t, err)
+		assert.False(t, claimed, "a reminder that is no longer due is not claimed")
+
+		count, err := repo.ReminderExecution.CountByReminderID(ctx, due.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("rolls back the transaction when it fails", func(t *testing.T) {
+		user := newTestUser(t, db)
+		due := newDueReminder(t, db, user, now.Add(-time.Minute))
+		txErr := errors.New("user not found")
+
+		err := repo.Transaction(ctx, func(tx *Repository) error {
+			reminder, err := tx.Reminder.LockDue(ctx, due.ID, now)
+			require.NoError(t, err)
+			require.NotNil(t, reminder)
+
+			require.NoError(t, tx.ReminderExecution.Create(ctx, &entities.ReminderExecution{
+				ReminderID: reminder.ID,
+				UserID:     reminder.UserID,
+				Status:     entities.ExecutionStatusSent,
+			}))
+			require.NoError(t, tx.Reminder.UpdateNextSendAt(ctx, reminder.ID, now.Add(time.Hour)))
+			return txErr
+		})
+		assert.ErrorIs(t, err, txErr)
+
+		stored, err := repo.Reminder.GetByID(ctx, due.ID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, *due.NextSendAt, *stored.NextSendAt, time.Second)
+
+		count, err := repo.ReminderExecution.CountByReminderID(ctx, due.ID)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("skips a reminder locked by another transaction", func(t *testing.T) {
+		user := newTestUser(t, db)
+		due := newDueReminder(t, db, user, now.Add(-time.Minute))
+
+		tx := db.Begin()
+		defer tx.Rollback()
+		var locked entities.Reminder
+		require.NoError(t, tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", due.ID).Error)
+
+		lockCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		claimed, err := claimDue(lockCtx, repo, due.ID, now)
+		require.NoError(t, err, "claiming must not wait for the lock")
+		assert.False(t, claimed)
+	})
+
+	t.Run("does not lock other reminders of the same user", func(t *testing.T) {
+		user := newTestUser(t, db)
+		first := newDueReminder(t, db, user, now.Add(-time.Minute))
+		second := newDueReminder(t, db, user, now.Add(-time.Minute))
+
+		err := repo.Transaction(ctx, func(tx *Repository) error {
+			reminder, err := tx.Reminder.LockDue(ctx, first.ID, now)
+			require.NoError(t, err)
+			require.NotNil(t, reminder)
+
+			claimed, err := claimDue(ctx, repo, second.ID, now)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("claims every reminder once across parallel instances", func(t *testing.T) {
+		const instances = 8
+
+		user := newTestUser(t, db)
+		var due []*entities.Reminder
+		for i := 0; i < 20; i++ {
+			due = append(due, newDueReminder(t, db, user, now.Add(-time.Duration(i)*time.Minute)))
+		}
+
+		var claimed atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < instances; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, reminder := range due {
+					ok, err := claimDue(ctx, repo, reminder.ID, now)
+					if !assert.NoError(t, err) {
+						return
+					}
+					if ok {
+						claimed.Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(len(due)), claimed.Load())
+		for _, reminder := range due {
+			count, err := repo.ReminderExecution.CountByReminderID(ctx, reminder.ID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count, "reminder %s", reminder.ID)
+		}
+	})
+}
+
+func TestReminderRepository_GetDueReminders(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewReminderRepository(db)
+	ctx := context.Background()
+
+	user := newTestUser(t, db)
+	due := newDueReminder(t, db, user, time.Now().Add(-time.Minute))
+	later := newDueReminder(t, db, user, time.Now().Add(time.Hour))
+
+	reminders, err := repo.GetDueReminders(ctx)
+	require.NoError(t, err)
+
+	ids := map[uuid.UUID]bool{}
+	for _, reminder := range reminders {
+		ids[reminder.ID] = true
+	}
+	assert.True(t, ids[due.ID])
+	assert.False(t, ids[later.ID])
+}
@@ -1,17 +1,43 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork runs a group of repository calls in one database transaction.
+type UnitOfWork interface {
+	Transaction(ctx context.Context, fn func(tx *Repository) error) error
+}
 
 type Repository struct {
+	db                *gorm.DB
 	User              UserRepository
 	Reminder          ReminderRepository
 	ReminderExecution ReminderExecutionRepository
+	Caregiver         CaregiverRepository
+	Stock             StockRepository
+	Conversation      ConversationRepository
 }
 
 func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{
+		db:                db,
 		User:              NewUserRepository(db),
 		Reminder:          NewReminderRepository(db),
 		ReminderExecution: NewReminderExecutionRepository(db),
+		Caregiver:         NewCaregiverRepository(db),
+		Stock:             NewStockRepository(db),
+		Conversation:      NewConversationRepository(db),
 	}
 }
+
+// Transaction calls fn with repositories bound to one transaction. What fn
+// writes through tx is committed when it returns nil and rolled back when it
+// returns an error.
+func (r *Repository) Transaction(ctx context.Context, fn func(tx *Repository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewRepository(tx))
+	})
+}
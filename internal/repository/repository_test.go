@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/migrations"
+)
+
+// testDatabaseEnv names the PostgreSQL DSN the repository tests run
+// against, e.g. "host=localhost user=postgres password=postgres
+// dbname=pills_bot_test sslmode=disable". Without it they are skipped.
+const testDatabaseEnv = "TEST_DATABASE_DSN"
+
+// openTestDB connects to the test database and migrates its schema. Tests
+// keep to rows of their own random users, so they can share the database.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, migrations.NewMigrator(db, zap.NewNop()).Run())
+
+	return db
+}
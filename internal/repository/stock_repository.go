@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+)
+
+type StockRepository interface {
+	Create(ctx context.Context, stock *entities.Stock) error
+	GetByReminderID(ctx context.Context, reminderID uuid.UUID) (*entities.Stock, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Stock, error)
+	GetUnwarned(ctx context.Context) ([]*entities.Stock, error)
+	Update(ctx context.Context, stock *entities.Stock) error
+	Consume(ctx context.Context, reminderID uuid.UUID) error
+	MarkWarned(ctx context.Context, id uuid.UUID, warnedAt time.Time) (bool, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type stockRepository struct {
+	db *gorm.DB
+}
+
+func NewStockRepository(db *gorm.DB) StockRepository {
+	return &stockRepository{db: db}
+}
+
+func (r *stockRepository) Create(ctx context.Context, stock *entities.Stock) error {
+	now := time.Now()
+	stock.ID = uuid.New()
+	stock.CreatedAt = now
+	stock.UpdatedAt = now
+
+	return r.db.WithContext(ctx).Create(stock).Error
+}
+
+func (r *stockRepository) GetByReminderID(ctx context.Context, reminderID uuid.UUID) (*entities.Stock, error) {
+	var stock entities.Stock
+	err := r.db.WithContext(ctx).First(&stock, "reminder_id = ?", reminderID).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &stock, nil
+}
+
+func (r *stockRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Stock, error) {
+	var stocks []*entities.Stock
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+// GetUnwarned returns stocks of active reminders that have not been
+// reported as running low since they were last topped up.
+func (r *stockRepository) GetUnwarned(ctx context.Context) ([]*entities.Stock, error) {
+	var stocks []*entities.Stock
+	err := r.db.WithContext(ctx).
+		Joins("JOIN reminders ON reminders.id = stocks.reminder_id").
+		Where("stocks.low_warned_at IS NULL").
+		Where("reminders.is_active = ?", true).
+		Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+func (r *stockRepository) Update(ctx context.Context, stock *entities.Stock) error {
+	stock.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Save(stock).Error
+}
+
+// Consume takes one dose out of the reminder's stock, if it is tracked. The
+// amount never goes below zero.
+func (r *stockRepository) Consume(ctx context.Context, reminderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&entities.Stock{}).
+		Where("reminder_id = ?", reminderID).
+		Updates(map[string]interface{}{
+			"on_hand":    gorm.Expr("GREATEST(on_hand - per_dose, 0)"),
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// MarkWarned records that the stock was reported as running low and reports
+// whether it had not been since it was last topped up.
+func (r *stockRepository) MarkWarned(ctx context.Context, id uuid.UUID, warnedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.Stock{}).
+		Where("id = ? AND low_warned_at IS NULL", id).
+		Update("low_warned_at", warnedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *stockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&entities.Stock{}, "id = ?", id).Error
+}
@@ -16,6 +16,9 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
 	Update(ctx context.Context, user *entities.User) error
 	SetActive(ctx context.Context, telegramID int64, isActive bool) error
+	GetDueDigests(ctx context.Context, now time.Time) ([]*entities.User, error)
+	UpdateDigestNextAt(ctx context.Context, id uuid.UUID, next time.Time) error
+	ClaimDigest(ctx context.Context, id uuid.UUID, now, next time.Time) (bool, error)
 }
 
 type userRepository struct {
@@ -74,3 +77,40 @@ func (r *userRepository) SetActive(ctx context.Context, telegramID int64, isActi
 			"updated_at": time.Now(),
 		}).Error
 }
+
+// GetDueDigests returns the active users whose weekly digest is due.
+func (r *userRepository) GetDueDigests(ctx context.Context, now time.Time) ([]*entities.User, error) {
+	var users []*entities.User
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND digest_next_at IS NOT NULL AND digest_next_at <= ?", true, now).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) UpdateDigestNextAt(ctx context.Context, id uuid.UUID, next time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"digest_next_at": next,
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+// ClaimDigest moves a digest that is due at now to next and reports whether
+// it was still due, so only one instance sends it.
+func (r *userRepository) ClaimDigest(ctx context.Context, id uuid.UUID, now, next time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ? AND digest_next_at <= ?", id, now).
+		Updates(map[string]interface{}{
+			"digest_next_at": next,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
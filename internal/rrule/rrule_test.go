@@ -0,0 +1,490 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func formatAll(times []time.Time) []string {
+	result := make([]string, len(times))
+	for i, t := range times {
+		result[i] = t.Format("2006-01-02 15:04 Mon")
+	}
+	return result
+}
+
+func TestSet_Between(t *testing.T) {
+	utc := time.UTC
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, utc)
+
+	tests := []struct {
+		name  string
+		value string
+		from  time.Time
+		to    time.Time
+		want  []string
+	}{
+		{
+			name:  "every other day",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;INTERVAL=2",
+			from:  from,
+			to:    from.AddDate(0, 0, 7),
+			want: []string{
+				"2026-01-01 09:00 Thu",
+				"2026-01-03 09:00 Sat",
+				"2026-01-05 09:00 Mon",
+				"2026-01-07 09:00 Wed",
+			},
+		},
+		{
+			name:  "several times a day",
+			value: "DTSTART:20260101T080000\nRRULE:FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0,30",
+			from:  from,
+			to:    from.AddDate(0, 0, 1),
+			want: []string{
+				"2026-01-01 08:00 Thu",
+				"2026-01-01 08:30 Thu",
+				"2026-01-01 20:00 Thu",
+				"2026-01-01 20:30 Thu",
+			},
+		},
+		{
+			name:  "weekly on given days",
+			value: "DTSTART:20260105T080000\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			from:  from,
+			to:    from.AddDate(0, 0, 14),
+			want: []string{
+				"2026-01-05 08:00 Mon",
+				"2026-01-07 08:00 Wed",
+				"2026-01-09 08:00 Fri",
+				"2026-01-12 08:00 Mon",
+				"2026-01-14 08:00 Wed",
+			},
+		},
+		{
+			name:  "weekly defaults to DTSTART weekday",
+			value: "DTSTART:20260106T080000\nRRULE:FREQ=WEEKLY;INTERVAL=2",
+			from:  from,
+			to:    from.AddDate(0, 0, 35),
+			want: []string{
+				"2026-01-06 08:00 Tue",
+				"2026-01-20 08:00 Tue",
+				"2026-02-03 08:00 Tue",
+			},
+		},
+		{
+			name:  "first Monday of the month",
+			value: "DTSTART:20260101T100000\nRRULE:FREQ=MONTHLY;BYDAY=1MO",
+			from:  from,
+			to:    from.AddDate(0, 4, 0),
+			want: []string{
+				"2026-01-05 10:00 Mon",
+				"2026-02-02 10:00 Mon",
+				"2026-03-02 10:00 Mon",
+				"2026-04-06 10:00 Mon",
+			},
+		},
+		{
+			name:  "last Friday of the month",
+			value: "DTSTART:20260101T100000\nRRULE:FREQ=MONTHLY;BYDAY=-1FR",
+			from:  from,
+			to:    from.AddDate(0, 3, 0),
+			want: []string{
+				"2026-01-30 10:00 Fri",
+				"2026-02-27 10:00 Fri",
+				"2026-03-27 10:00 Fri",
+			},
+		},
+		{
+			name:  "last weekday of the month via BYSETPOS",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			from:  from,
+			to:    from.AddDate(0, 3, 0),
+			want: []string{
+				"2026-01-30 09:00 Fri",
+				"2026-02-27 09:00 Fri",
+				"2026-03-31 09:00 Tue",
+			},
+		},
+		{
+			name:  "monthly on the last day",
+			value: "DTSTART:20260131T090000\nRRULE:FREQ=MONTHLY;BYMONTHDAY=-1",
+			from:  from,
+			to:    from.AddDate(0, 3, 0),
+			want: []string{
+				"2026-01-31 09:00 Sat",
+				"2026-02-28 09:00 Sat",
+				"2026-03-31 09:00 Tue",
+			},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			value: "DTSTART:20260131T090000\nRRULE:FREQ=MONTHLY",
+			from:  from,
+			to:    from.AddDate(0, 5, 0),
+			want: []string{
+				"2026-01-31 09:00 Sat",
+				"2026-03-31 09:00 Tue",
+				"2026-05-31 09:00 Sun",
+			},
+		},
+		{
+			name:  "yearly on leap day",
+			value: "DTSTART:20240229T090000\nRRULE:FREQ=YEARLY",
+			from:  time.Date(2024, 1, 1, 0, 0, 0, 0, utc),
+			to:    time.Date(2033, 1, 1, 0, 0, 0, 0, utc),
+			want: []string{
+				"2024-02-29 09:00 Thu",
+				"2028-02-29 09:00 Tue",
+				"2032-02-29 09:00 Sun",
+			},
+		},
+		{
+			name:  "yearly by month and weekday ordinal",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU",
+			from:  from,
+			to:    from.AddDate(2, 0, 0),
+			want: []string{
+				"2026-03-08 09:00 Sun",
+				"2027-03-14 09:00 Sun",
+			},
+		},
+		{
+			name:  "count limits occurrences",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;COUNT=3",
+			from:  from,
+			to:    from.AddDate(0, 1, 0),
+			want: []string{
+				"2026-01-01 09:00 Thu",
+				"2026-01-02 09:00 Fri",
+				"2026-01-03 09:00 Sat",
+			},
+		},
+		{
+			name:  "until is inclusive",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;UNTIL=20260103T090000",
+			from:  from,
+			to:    from.AddDate(0, 1, 0),
+			want: []string{
+				"2026-01-01 09:00 Thu",
+				"2026-01-02 09:00 Fri",
+				"2026-01-03 09:00 Sat",
+			},
+		},
+		{
+			name:  "exdate removes a single occurrence",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;COUNT=4\nEXDATE:20260102T090000",
+			from:  from,
+			to:    from.AddDate(0, 1, 0),
+			want: []string{
+				"2026-01-01 09:00 Thu",
+				"2026-01-03 09:00 Sat",
+				"2026-01-04 09:00 Sun",
+			},
+		},
+		{
+			name:  "exdate date form removes the whole day",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;BYHOUR=9,21;COUNT=4\nEXDATE;VALUE=DATE:20260101",
+			from:  from,
+			to:    from.AddDate(0, 1, 0),
+			want: []string{
+				"2026-01-02 09:00 Fri",
+				"2026-01-02 21:00 Fri",
+			},
+		},
+		{
+			name:  "rdate adds an extra occurrence",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=WEEKLY;COUNT=2\nRDATE:20260103T120000",
+			from:  from,
+			to:    from.AddDate(0, 1, 0),
+			want: []string{
+				"2026-01-01 09:00 Thu",
+				"2026-01-03 12:00 Sat",
+				"2026-01-08 09:00 Thu",
+			},
+		},
+		{
+			name:  "21 days on, 7 days off",
+			value: "DTSTART:20260122T090000\nRRULE:FREQ=DAILY\nEXRULE:FREQ=WEEKLY;INTERVAL=4;BYDAY=MO,TU,WE,TH,FR,SA,SU;WKST=TH",
+			from:  time.Date(2026, 1, 27, 0, 0, 0, 0, utc),
+			to:    time.Date(2026, 1, 31, 0, 0, 0, 0, utc),
+			want: []string{
+				"2026-01-29 09:00 Thu",
+				"2026-01-30 09:00 Fri",
+			},
+		},
+		{
+			name:  "dtstart not matching the rule is skipped",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=2",
+			from:  from,
+			to:    from.AddDate(0, 1, 0),
+			want: []string{
+				"2026-01-05 09:00 Mon",
+				"2026-01-12 09:00 Mon",
+			},
+		},
+		{
+			name:  "impossible rule yields nothing",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			from:  from,
+			to:    from.AddDate(20, 0, 0),
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Parse(tt.value, utc)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, formatAll(set.Between(tt.from, tt.to)))
+		})
+	}
+}
+
+func TestSet_21On7Off(t *testing.T) {
+	set, err := Parse("DTSTART:20260122T090000\nRRULE:FREQ=DAILY\nEXRULE:FREQ=WEEKLY;INTERVAL=4;BYDAY=MO,TU,WE,TH,FR,SA,SU;WKST=TH", time.UTC)
+	require.NoError(t, err)
+
+	from := time.Date(2026, 1, 22, 0, 0, 0, 0, time.UTC)
+	occurrences := set.Between(from, from.AddDate(0, 0, 56))
+
+	// Two full cycles: the break week comes first, then 21 days of doses.
+	assert.Len(t, occurrences, 42)
+	assert.Equal(t, "2026-01-29 09:00 Thu", formatAll(occurrences)[0])
+	assert.Equal(t, "2026-02-18 09:00 Wed", formatAll(occurrences)[20])
+	assert.Equal(t, "2026-02-26 09:00 Thu", formatAll(occurrences)[21])
+}
+
+func TestSet_After(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		after time.Time
+		want  string
+	}{
+		{
+			name:  "next occurrence later the same day",
+			value: "DTSTART:20260101T080000\nRRULE:FREQ=DAILY;BYHOUR=8,20",
+			after: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+			want:  "2026-03-10 20:00 Tue",
+		},
+		{
+			name:  "strictly after an occurrence",
+			value: "DTSTART:20260101T080000\nRRULE:FREQ=DAILY;INTERVAL=2",
+			after: time.Date(2026, 1, 3, 8, 0, 0, 0, time.UTC),
+			want:  "2026-01-05 08:00 Mon",
+		},
+		{
+			name:  "before dtstart",
+			value: "DTSTART:20260601T080000\nRRULE:FREQ=DAILY",
+			after: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:  "2026-06-01 08:00 Mon",
+		},
+		{
+			name:  "skips excluded occurrence",
+			value: "DTSTART:20260101T080000\nRRULE:FREQ=DAILY\nEXDATE:20260102T080000,20260103T080000",
+			after: time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC),
+			want:  "2026-01-04 08:00 Sun",
+		},
+		{
+			name:  "earliest of several rules",
+			value: "DTSTART:20260101T080000\nRRULE:FREQ=MONTHLY;BYMONTHDAY=15\nRRULE:FREQ=MONTHLY;BYDAY=1MO",
+			after: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC),
+			want:  "2026-01-15 08:00 Thu",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Parse(tt.value, time.UTC)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, set.After(tt.after).Format("2006-01-02 15:04 Mon"))
+		})
+	}
+
+	t.Run("exhausted rule returns zero", func(t *testing.T) {
+		set, err := Parse("DTSTART:20260101T080000\nRRULE:FREQ=DAILY;COUNT=2", time.UTC)
+		require.NoError(t, err)
+
+		assert.True(t, set.After(time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)).IsZero())
+	})
+
+	t.Run("impossible rule returns zero", func(t *testing.T) {
+		set, err := Parse("DTSTART:20260101T080000\nRRULE:FREQ=MONTHLY;BYMONTHDAY=31;BYMONTH=4", time.UTC)
+		require.NoError(t, err)
+
+		assert.True(t, set.After(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero())
+	})
+
+	t.Run("everything excluded returns zero", func(t *testing.T) {
+		set, err := Parse("DTSTART:20260101T090000\nRRULE:FREQ=DAILY\nEXRULE:FREQ=DAILY", time.UTC)
+		require.NoError(t, err)
+
+		done := make(chan time.Time, 1)
+		go func() { done <- set.After(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) }()
+
+		select {
+		case next := <-done:
+			assert.True(t, next.IsZero())
+		case <-time.After(5 * time.Second):
+			t.Fatal("After did not return")
+		}
+	})
+
+	t.Run("occurrence after a long excluded stretch", func(t *testing.T) {
+		set, err := Parse("DTSTART:20260101T090000\nRRULE:FREQ=DAILY\nEXRULE:FREQ=DAILY;UNTIL=20270101T000000", time.UTC)
+		require.NoError(t, err)
+
+		assert.Equal(t, "2027-01-01 09:00 Fri", set.After(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)).Format("2006-01-02 15:04 Mon"))
+	})
+}
+
+func TestSet_TimeZones(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+
+	t.Run("floating dtstart keeps wall clock across DST", func(t *testing.T) {
+		set, err := Parse("DTSTART:20260328T090000\nRRULE:FREQ=DAILY;COUNT=3", berlin)
+		require.NoError(t, err)
+		assert.True(t, set.Floating)
+
+		occurrences := set.Between(time.Date(2026, 3, 28, 0, 0, 0, 0, berlin), time.Date(2026, 4, 1, 0, 0, 0, 0, berlin))
+		require.Len(t, occurrences, 3)
+		for _, occurrence := range occurrences {
+			assert.Equal(t, 9, occurrence.In(berlin).Hour())
+		}
+		assert.Equal(t, 23*time.Hour, occurrences[1].Sub(occurrences[0]))
+	})
+
+	t.Run("TZID overrides the default zone", func(t *testing.T) {
+		set, err := Parse("DTSTART;TZID=Asia/Tokyo:20260101T090000\nRRULE:FREQ=DAILY", berlin)
+		require.NoError(t, err)
+		assert.False(t, set.Floating)
+
+		next := set.After(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("UTC dtstart", func(t *testing.T) {
+		set, err := Parse("DTSTART:20260101T090000Z\nRRULE:FREQ=DAILY", berlin)
+		require.NoError(t, err)
+
+		next := set.After(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC), next)
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Run("bare rule without dtstart", func(t *testing.T) {
+		set, err := Parse("FREQ=DAILY;INTERVAL=2", time.UTC)
+		require.NoError(t, err)
+
+		assert.True(t, set.DTStart.IsZero())
+		require.Len(t, set.RRules, 1)
+		assert.Equal(t, Daily, set.RRules[0].Freq)
+		assert.Equal(t, 2, set.RRules[0].Interval)
+	})
+
+	t.Run("properties separated by spaces", func(t *testing.T) {
+		set, err := Parse("DTSTART:20260101T090000 RRULE:FREQ=WEEKLY;BYDAY=MO", time.UTC)
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), set.DTStart)
+		require.Len(t, set.RRules, 1)
+		assert.Equal(t, []WeekdayNum{{Weekday: time.Monday}}, set.RRules[0].ByDay)
+	})
+
+	t.Run("lower case names", func(t *testing.T) {
+		set, err := Parse("rrule:freq=monthly;byday=-1fr", time.UTC)
+		require.NoError(t, err)
+
+		assert.Equal(t, []WeekdayNum{{Weekday: time.Friday, N: -1}}, set.RRules[0].ByDay)
+	})
+
+	errorCases := []struct {
+		name  string
+		value string
+	}{
+		{name: "empty", value: ""},
+		{name: "dtstart only", value: "DTSTART:20260101T090000"},
+		{name: "missing FREQ", value: "RRULE:INTERVAL=2"},
+		{name: "unknown FREQ", value: "RRULE:FREQ=HOURLY"},
+		{name: "unknown part", value: "RRULE:FREQ=DAILY;BYWEEKNO=1"},
+		{name: "zero interval", value: "RRULE:FREQ=DAILY;INTERVAL=0"},
+		{name: "negative count", value: "RRULE:FREQ=DAILY;COUNT=-1"},
+		{name: "count and until", value: "RRULE:FREQ=DAILY;COUNT=2;UNTIL=20260101T000000Z"},
+		{name: "bad until", value: "RRULE:FREQ=DAILY;UNTIL=tomorrow"},
+		{name: "month out of range", value: "RRULE:FREQ=YEARLY;BYMONTH=13"},
+		{name: "month day out of range", value: "RRULE:FREQ=MONTHLY;BYMONTHDAY=32"},
+		{name: "hour out of range", value: "RRULE:FREQ=DAILY;BYHOUR=24"},
+		{name: "unknown weekday", value: "RRULE:FREQ=WEEKLY;BYDAY=XX"},
+		{name: "ordinal with weekly", value: "RRULE:FREQ=WEEKLY;BYDAY=1MO"},
+		{name: "monthly ordinal out of range", value: "RRULE:FREQ=MONTHLY;BYDAY=6MO"},
+		{name: "month day with weekly", value: "RRULE:FREQ=WEEKLY;BYMONTHDAY=1"},
+		{name: "empty part value", value: "RRULE:FREQ="},
+		{name: "repeated dtstart", value: "DTSTART:20260101T090000\nDTSTART:20260102T090000\nRRULE:FREQ=DAILY"},
+		{name: "bad dtstart", value: "DTSTART:2026-01-01\nRRULE:FREQ=DAILY"},
+		{name: "unknown TZID", value: "DTSTART;TZID=Mars/Olympus:20260101T090000\nRRULE:FREQ=DAILY"},
+		{name: "bad exdate", value: "RRULE:FREQ=DAILY\nEXDATE:yesterday"},
+		{name: "unsupported property", value: "RRULE:FREQ=DAILY\nSUMMARY:pills"},
+	}
+
+	for _, tt := range errorCases {
+		t.Run("error: "+tt.name, func(t *testing.T) {
+			_, err := Parse(tt.value, time.UTC)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSet_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "floating",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;INTERVAL=2",
+			want:  "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;INTERVAL=2",
+		},
+		{
+			name:  "utc",
+			value: "DTSTART:20260101T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,FR;WKST=SU",
+			want:  "DTSTART:20260101T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,FR;WKST=SU",
+		},
+		{
+			name:  "tzid",
+			value: "DTSTART;TZID=Europe/Berlin:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=1MO;COUNT=6",
+			want:  "DTSTART;TZID=Europe/Berlin:20260101T090000\nRRULE:FREQ=MONTHLY;COUNT=6;BYDAY=1MO",
+		},
+		{
+			name:  "exclusions",
+			value: "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;UNTIL=20260201T000000\nEXRULE:FREQ=WEEKLY;BYDAY=SU\nEXDATE:20260105T090000\nEXDATE;VALUE=DATE:20260110",
+			want:  "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;UNTIL=20260201T000000\nEXRULE:FREQ=WEEKLY;BYDAY=SU\nEXDATE:20260105T090000\nEXDATE;VALUE=DATE:20260110",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Parse(tt.value, time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, set.String())
+
+			reparsed, err := Parse(set.String(), time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, set.String(), reparsed.String())
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = [...]string{"DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+func (f Frequency) String() string {
+	if f < 0 || int(f) >= len(frequencyNames) {
+		return fmt.Sprintf("Frequency(%d)", int(f))
+	}
+	return frequencyNames[f]
+}
+
+func parseFrequency(value string) (Frequency, error) {
+	for i, name := range frequencyNames {
+		if strings.EqualFold(name, value) {
+			return Frequency(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported FREQ %q", value)
+}
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is a BYDAY entry such as "MO", "1MO" (first Monday) or
+// "-1FR" (last Friday). N is zero when no ordinal is given.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayCodes[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayCodes[w.Weekday]
+}
+
+// Rule is a single RRULE/EXRULE value. Only the parts that make sense for
+// day-granular medication schedules are supported: FREQ (DAILY, WEEKLY,
+// MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL, BYMONTH, BYMONTHDAY, BYDAY,
+// BYHOUR, BYMINUTE, BYSETPOS and WKST.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByMonth    []int
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	ByHour     []int
+	ByMinute   []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+
+	untilFloating bool
+}
+
+// ParseRule parses the value of an RRULE line, e.g. "FREQ=DAILY;INTERVAL=2".
+// Floating UNTIL values are interpreted in loc.
+func ParseRule(value string, loc *time.Location) (Rule, error) {
+	rule := Rule{Interval: 1, WeekStart: time.Monday}
+	seenFreq := false
+
+	for _, part := range strings.Split(strings.TrimSpace(value), ";") {
+		if part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return Rule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq, err = parseFrequency(val)
+			seenFreq = true
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(val)
+		case "COUNT":
+			rule.Count, err = parsePositive(val)
+		case "UNTIL":
+			var floating bool
+			rule.Until, floating, err = parseDateTime(val, loc)
+			rule.untilFloating = floating
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(val, 1, 12, false)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(val, 1, 31, true)
+		case "BYDAY":
+			rule.ByDay, err = parseWeekdayList(val)
+		case "BYHOUR":
+			rule.ByHour, err = parseIntList(val, 0, 23, false)
+		case "BYMINUTE":
+			rule.ByMinute, err = parseIntList(val, 0, 59, false)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(val, 1, 366, true)
+		case "WKST":
+			rule.WeekStart, err = parseWeekday(val)
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part %q", name)
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid %s: %w", strings.ToUpper(name), err)
+		}
+	}
+
+	if !seenFreq {
+		return Rule{}, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, fmt.Errorf("COUNT and UNTIL are mutually exclusive")
+	}
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return Rule{}, fmt.Errorf("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+	}
+	for _, day := range rule.ByDay {
+		if day.N == 0 {
+			continue
+		}
+		if rule.Freq != Monthly && rule.Freq != Yearly {
+			return Rule{}, fmt.Errorf("BYDAY ordinals require FREQ=MONTHLY or FREQ=YEARLY")
+		}
+		if rule.Freq == Monthly && (day.N > 5 || day.N < -5) {
+			return Rule{}, fmt.Errorf("BYDAY ordinal %d out of range", day.N)
+		}
+	}
+
+	return rule, nil
+}
+
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+formatDateTime(r.Until, r.untilFloating))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByHour) > 0 {
+		parts = append(parts, "BYHOUR="+joinInts(r.ByHour))
+	}
+	if len(r.ByMinute) > 0 {
+		parts = append(parts, "BYMINUTE="+joinInts(r.ByMinute))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCodes[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// maxGap bounds how far iteration runs past the last occurrence before a
+// rule is considered exhausted, so impossible rules such as BYMONTH=2;
+// BYMONTHDAY=30 terminate. Eight years covers the longest leap-day gap.
+const maxGap = 8*366*24*time.Hour + 24*time.Hour
+
+// iterate calls fn with every occurrence of the rule in ascending order,
+// starting at dtstart, until fn returns false or the rule is exhausted.
+func (r Rule) iterate(dtstart time.Time, fn func(time.Time) bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	emitted := 0
+	last := dtstart
+	for period := 0; ; period++ {
+		start := r.periodStart(dtstart, period*interval)
+		if start.Sub(last) > maxGap {
+			return
+		}
+
+		for _, occurrence := range r.expandPeriod(dtstart, start) {
+			if occurrence.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && occurrence.After(r.Until) {
+				return
+			}
+			emitted++
+			last = occurrence
+			if !fn(occurrence) {
+				return
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+func (r Rule) periodStart(dtstart time.Time, offset int) time.Time {
+	loc := dtstart.Location()
+	switch r.Freq {
+	case Weekly:
+		shift := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day()-shift+7*offset, 0, 0, 0, 0, loc)
+	case Monthly:
+		return time.Date(dtstart.Year(), dtstart.Month()+time.Month(offset), 1, 0, 0, 0, 0, loc)
+	case Yearly:
+		return time.Date(dtstart.Year()+offset, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day()+offset, 0, 0, 0, 0, loc)
+	}
+}
+
+func (r Rule) expandPeriod(dtstart, start time.Time) []time.Time {
+	var end time.Time
+	switch r.Freq {
+	case Weekly:
+		end = start.AddDate(0, 0, 7)
+	case Monthly:
+		end = start.AddDate(0, 1, 0)
+	case Yearly:
+		end = start.AddDate(1, 0, 0)
+	default:
+		end = start.AddDate(0, 0, 1)
+	}
+
+	hours := r.ByHour
+	if len(hours) == 0 {
+		hours = []int{dtstart.Hour()}
+	}
+	minutes := r.ByMinute
+	if len(minutes) == 0 {
+		minutes = []int{dtstart.Minute()}
+	}
+
+	var occurrences []time.Time
+	for day := start; day.Before(end); day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location()) {
+		if !r.matchesDay(dtstart, day) {
+			continue
+		}
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				occurrences = append(occurrences, time.Date(day.Year(), day.Month(), day.Day(), hour, minute, dtstart.Second(), 0, day.Location()))
+			}
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+
+	if len(r.BySetPos) == 0 {
+		return occurrences
+	}
+
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		idx := pos - 1
+		if pos < 0 {
+			idx = len(occurrences) + pos
+		}
+		if idx >= 0 && idx < len(occurrences) {
+			selected = append(selected, occurrences[idx])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return dedupe(selected)
+}
+
+func (r Rule) matchesDay(dtstart, day time.Time) bool {
+	if len(r.ByMonth) > 0 && !containsInt(r.ByMonth, int(day.Month())) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, day) {
+		return false
+	}
+
+	if len(r.ByDay) > 0 {
+		yearScope := r.Freq == Yearly && len(r.ByMonth) == 0
+		if !matchesWeekday(r.ByDay, day, yearScope) {
+			return false
+		}
+	}
+
+	// Parts not given explicitly are inherited from DTSTART.
+	switch r.Freq {
+	case Weekly:
+		if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+			return false
+		}
+	case Monthly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && day.Day() != dtstart.Day() {
+			return false
+		}
+	case Yearly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if len(r.ByMonth) == 0 && day.Month() != dtstart.Month() {
+				return false
+			}
+			if day.Day() != dtstart.Day() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func matchesMonthDay(monthDays []int, day time.Time) bool {
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range monthDays {
+		if md > 0 && day.Day() == md {
+			return true
+		}
+		if md < 0 && day.Day() == daysInMonth+md+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesWeekday(weekdays []WeekdayNum, day time.Time, yearScope bool) bool {
+	for _, wd := range weekdays {
+		if wd.Weekday != day.Weekday() {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+
+		var index, total int
+		if yearScope {
+			index = (day.YearDay()-1)/7 + 1
+			daysInYear := time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+			total = (daysInYear-day.YearDay())/7 + index
+		} else {
+			daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+			index = (day.Day()-1)/7 + 1
+			total = (daysInMonth-day.Day())/7 + index
+		}
+
+		if wd.N > 0 && index == wd.N {
+			return true
+		}
+		if wd.N < 0 && index == total+wd.N+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePositive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not a positive integer", value)
+	}
+	return n, nil
+}
+
+func parseIntList(value string, min, max int, allowNegative bool) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", item)
+		}
+		abs := n
+		if allowNegative && n < 0 {
+			abs = -n
+		}
+		if abs < min || abs > max {
+			return nil, fmt.Errorf("%d is out of range", n)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	code := strings.ToUpper(strings.TrimSpace(value))
+	for i, c := range weekdayCodes {
+		if c == code {
+			return time.Weekday(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", value)
+}
+
+func parseWeekdayList(value string) ([]WeekdayNum, error) {
+	var result []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, fmt.Errorf("unknown weekday %q", item)
+		}
+		weekday, err := parseWeekday(item[len(item)-2:])
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid weekday ordinal %q", item)
+			}
+		}
+		result = append(result, WeekdayNum{Weekday: weekday, N: n})
+	}
+	return result, nil
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func dedupe(times []time.Time) []time.Time {
+	if len(times) < 2 {
+		return times
+	}
+	result := times[:1]
+	for _, t := range times[1:] {
+		if !t.Equal(result[len(result)-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,347 @@
+package rrule
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Set is a recurrence set as described by RFC 5545: a DTSTART, one or more
+// RRULEs and optional RDATE, EXDATE and EXRULE properties. EXRULE was dropped
+// from RFC 5545 but is accepted because regimens like "21 days on, 7 off"
+// cannot be expressed without it.
+//
+// Occurrences are generated only where DTSTART matches the rule, as most
+// implementations do; RFC 5545 leaves the unsynchronized case undefined.
+type Set struct {
+	DTStart time.Time
+	RRules  []Rule
+	ExRules []Rule
+	RDates  []time.Time
+	ExDates []time.Time
+	// ExDays holds EXDATE values given in DATE form; each one excludes every
+	// occurrence falling on that day.
+	ExDays []time.Time
+
+	// Floating is set when DTSTART had neither TZID nor a UTC marker, so the
+	// set follows whatever zone it is parsed in.
+	Floating bool
+}
+
+// Parse parses a recurrence set. Properties may be separated by newlines or
+// spaces; a bare rule such as "FREQ=DAILY;INTERVAL=2" is treated as RRULE.
+// Floating date-times are interpreted in loc. DTSTART may be omitted, in
+// which case the returned set has a zero DTStart for the caller to fill in.
+func Parse(value string, loc *time.Location) (*Set, error) {
+	set := &Set{}
+	dtstartSeen := false
+
+	for _, line := range strings.Fields(value) {
+		name, val, ok := strings.Cut(line, ":")
+		if !ok {
+			name, val = "RRULE", line
+		}
+
+		params := strings.Split(name, ";")
+		property := strings.ToUpper(params[0])
+		propLoc, isDate, err := parseParams(params[1:], loc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", property, err)
+		}
+
+		switch property {
+		case "DTSTART":
+			if dtstartSeen {
+				return nil, fmt.Errorf("DTSTART must not be repeated")
+			}
+			dtstartSeen = true
+			var floating bool
+			set.DTStart, floating, err = parseValue(val, propLoc, isDate)
+			set.Floating = floating && propLoc == loc
+		case "RRULE", "EXRULE":
+			var rule Rule
+			rule, err = ParseRule(val, loc)
+			if property == "RRULE" {
+				set.RRules = append(set.RRules, rule)
+			} else {
+				set.ExRules = append(set.ExRules, rule)
+			}
+		case "RDATE", "EXDATE":
+			for _, item := range strings.Split(val, ",") {
+				var date time.Time
+				date, _, err = parseValue(item, propLoc, isDate)
+				if err != nil {
+					break
+				}
+				switch {
+				case property == "RDATE":
+					set.RDates = append(set.RDates, date)
+				case len(item) == len("20060102"):
+					set.ExDays = append(set.ExDays, date)
+				default:
+					set.ExDates = append(set.ExDates, date)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported property %q", property)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", property, err)
+		}
+	}
+
+	if len(set.RRules) == 0 && len(set.RDates) == 0 {
+		return nil, fmt.Errorf("at least one RRULE or RDATE is required")
+	}
+
+	return set, nil
+}
+
+func parseParams(params []string, loc *time.Location) (*time.Location, bool, error) {
+	isDate := false
+	for _, param := range params {
+		key, val, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, false, fmt.Errorf("invalid parameter %q", param)
+		}
+		switch strings.ToUpper(key) {
+		case "TZID":
+			tz, err := time.LoadLocation(val)
+			if err != nil {
+				return nil, false, fmt.Errorf("unknown TZID %q", val)
+			}
+			loc = tz
+		case "VALUE":
+			switch strings.ToUpper(val) {
+			case "DATE":
+				isDate = true
+			case "DATE-TIME":
+			default:
+				return nil, false, fmt.Errorf("unsupported VALUE %q", val)
+			}
+		default:
+			return nil, false, fmt.Errorf("unsupported parameter %q", key)
+		}
+	}
+	return loc, isDate, nil
+}
+
+func parseValue(value string, loc *time.Location, isDate bool) (time.Time, bool, error) {
+	if isDate && len(value) != len("20060102") {
+		return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+	}
+	return parseDateTime(value, loc)
+}
+
+// parseDateTime accepts the iCalendar DATE and DATE-TIME forms. The second
+// result reports whether the value was floating, i.e. had no UTC marker.
+func parseDateTime(value string, loc *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, false, nil
+	case len(value) == len("20060102"):
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, true, nil
+	}
+}
+
+func formatDateTime(t time.Time, floating bool) string {
+	if floating {
+		return t.Format("20060102T150405")
+	}
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// String serializes the set back into iCalendar lines.
+func (s *Set) String() string {
+	var lines []string
+	if !s.DTStart.IsZero() {
+		switch {
+		case s.Floating:
+			lines = append(lines, "DTSTART:"+s.DTStart.Format("20060102T150405"))
+		case s.DTStart.Location() == time.UTC:
+			lines = append(lines, "DTSTART:"+s.DTStart.Format("20060102T150405Z"))
+		default:
+			lines = append(lines, "DTSTART;TZID="+s.DTStart.Location().String()+":"+s.DTStart.Format("20060102T150405"))
+		}
+	}
+	for _, rule := range s.RRules {
+		lines = append(lines, "RRULE:"+rule.String())
+	}
+	for _, rule := range s.ExRules {
+		lines = append(lines, "EXRULE:"+rule.String())
+	}
+	if len(s.RDates) > 0 {
+		lines = append(lines, "RDATE:"+s.formatDates(s.RDates))
+	}
+	if len(s.ExDates) > 0 {
+		lines = append(lines, "EXDATE:"+s.formatDates(s.ExDates))
+	}
+	if len(s.ExDays) > 0 {
+		days := make([]string, len(s.ExDays))
+		for i, day := range s.ExDays {
+			days[i] = day.Format("20060102")
+		}
+		lines = append(lines, "EXDATE;VALUE=DATE:"+strings.Join(days, ","))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Set) formatDates(dates []time.Time) string {
+	items := make([]string, len(dates))
+	for i, date := range dates {
+		items[i] = formatDateTime(date, s.Floating)
+	}
+	return strings.Join(items, ",")
+}
+
+// After returns the first occurrence strictly after t, or the zero time
+// when the set has no further occurrences. A rule whose occurrences stay
+// excluded for longer than maxGap is considered exhausted, so a set that
+// excludes everything ends instead of iterating forever.
+func (s *Set) After(t time.Time) time.Time {
+	var next time.Time
+	consider := func(candidate time.Time) {
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+
+	for _, rule := range s.RRules {
+		excl := s.exclusions()
+		lastKept := s.DTStart
+		rule.iterate(s.DTStart, func(occurrence time.Time) bool {
+			if !next.IsZero() && !occurrence.Before(next) {
+				return false
+			}
+			if excl.covers(occurrence) {
+				return occurrence.Sub(lastKept) <= maxGap
+			}
+			lastKept = occurrence
+			if !occurrence.After(t) {
+				return true
+			}
+			consider(occurrence)
+			return false
+		})
+		excl.stop()
+	}
+
+	excl := s.exclusions()
+	for _, date := range sortedDates(s.RDates) {
+		if date.After(t) && !excl.covers(date) {
+			consider(date)
+			break
+		}
+	}
+	excl.stop()
+
+	return next
+}
+
+// Between returns all occurrences in [from, to) in ascending order.
+func (s *Set) Between(from, to time.Time) []time.Time {
+	var occurrences []time.Time
+	for _, rule := range s.RRules {
+		excl := s.exclusions()
+		rule.iterate(s.DTStart, func(occurrence time.Time) bool {
+			if !occurrence.Before(to) {
+				return false
+			}
+			if !excl.covers(occurrence) && !occurrence.Before(from) {
+				occurrences = append(occurrences, occurrence)
+			}
+			return true
+		})
+		excl.stop()
+	}
+
+	excl := s.exclusions()
+	for _, date := range sortedDates(s.RDates) {
+		if !excl.covers(date) && !date.Before(from) && date.Before(to) {
+			occurrences = append(occurrences, date)
+		}
+	}
+	excl.stop()
+
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+	return dedupe(occurrences)
+}
+
+func sortedDates(dates []time.Time) []time.Time {
+	sorted := append([]time.Time(nil), dates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	return sorted
+}
+
+// exclusions checks candidates against the set's EXDATEs and EXRULEs. The
+// EXRULEs are walked forward alongside the candidates, which must therefore
+// be passed to covers in ascending order; stop releases the walk.
+type exclusions struct {
+	set   *Set
+	rules []*exclusionRule
+}
+
+type exclusionRule struct {
+	next    func() (time.Time, bool)
+	stop    func()
+	current time.Time
+	ok      bool
+}
+
+func (s *Set) exclusions() *exclusions {
+	excl := &exclusions{set: s}
+	for _, rule := range s.ExRules {
+		next, stop := iter.Pull(func(yield func(time.Time) bool) {
+			rule.iterate(s.DTStart, yield)
+		})
+		current, ok := next()
+		excl.rules = append(excl.rules, &exclusionRule{next: next, stop: stop, current: current, ok: ok})
+	}
+	return excl
+}
+
+func (e *exclusions) covers(t time.Time) bool {
+	for _, date := range e.set.ExDates {
+		if date.Equal(t) {
+			return true
+		}
+	}
+	for _, day := range e.set.ExDays {
+		local := t.In(day.Location())
+		if local.Year() == day.Year() && local.YearDay() == day.YearDay() {
+			return true
+		}
+	}
+	for _, rule := range e.rules {
+		for rule.ok && rule.current.Before(t) {
+			rule.current, rule.ok = rule.next()
+		}
+		if rule.ok && rule.current.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *exclusions) stop() {
+	for _, rule := range e.rules {
+		rule.stop()
+	}
+}
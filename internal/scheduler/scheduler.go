@@ -17,16 +17,25 @@ type Scheduler struct {
 	reminderRepo     repository.ReminderRepository
 	executionUsecase usecases.ReminderExecutionUsecase
 	reminderUsecase  usecases.ReminderUsecase
+	caregiverUsecase usecases.CaregiverUsecase
+	stockUsecase     usecases.StockUsecase
+	digestUsecase    usecases.DigestUsecase
+	deliveryUsecase  usecases.DeliveryUsecase
 	handler          *handlers.BotHandler
 	logger           *zap.Logger
 	ticker           *time.Ticker
 	stopChan         chan struct{}
+	done             chan struct{}
 }
 
 func NewScheduler(
 	reminderRepo repository.ReminderRepository,
 	executionUsecase usecases.ReminderExecutionUsecase,
 	reminderUsecase usecases.ReminderUsecase,
+	caregiverUsecase usecases.CaregiverUsecase,
+	stockUsecase usecases.StockUsecase,
+	digestUsecase usecases.DigestUsecase,
+	deliveryUsecase usecases.DeliveryUsecase,
 	handler *handlers.BotHandler,
 	logger *zap.Logger,
 ) *Scheduler {
@@ -34,9 +43,14 @@ func NewScheduler(
 		reminderRepo:     reminderRepo,
 		executionUsecase: executionUsecase,
 		reminderUsecase:  reminderUsecase,
+		caregiverUsecase: caregiverUsecase,
+		stockUsecase:     stockUsecase,
+		digestUsecase:    digestUsecase,
+		deliveryUsecase:  deliveryUsecase,
 		handler:          handler,
 		logger:           logger,
 		stopChan:         make(chan struct{}),
+		done:             make(chan struct{}),
 	}
 }
 
@@ -44,10 +58,11 @@ func (s *Scheduler) Start(ctx context.Context) {
 	s.ticker = time.NewTicker(1 * time.Minute)
 
 	go func() {
+		defer close(s.done)
 		for {
 			select {
 			case <-s.ticker.C:
-				s.processReminders(ctx)
+				s.tick(ctx)
 			case <-s.stopChan:
 				return
 			case <-ctx.Done():
@@ -59,12 +74,44 @@ func (s *Scheduler) Start(ctx context.Context) {
 	s.logger.Info("Scheduler started")
 }
 
-func (s *Scheduler) Stop() {
+// tick runs every job once. After Stop the job in progress is finished and
+// the rest are left to the next start: their reminders stay due.
+func (s *Scheduler) tick(ctx context.Context) {
+	jobs := []func(context.Context){
+		s.processReminders,
+		s.processDeliveries,
+		s.processSnoozed,
+		s.processNags,
+		s.processUnanswered,
+		s.processEscalations,
+		s.processLowStock,
+		s.processDigests,
+	}
+	for _, job := range jobs {
+		select {
+		case <-s.stopChan:
+			return
+		default:
+		}
+		job(ctx)
+	}
+}
+
+// Stop stops the ticker and waits until the tick in progress, with its sends
+// and database writes, is finished or ctx is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
 	if s.ticker != nil {
 		s.ticker.Stop()
 	}
 	close(s.stopChan)
-	s.logger.Info("Scheduler stopped")
+
+	select {
+	case <-s.done:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for scheduler: %w", ctx.Err())
+	}
 }
 
 func (s *Scheduler) processReminders(ctx context.Context) {
@@ -82,54 +129,429 @@ func (s *Scheduler) processReminders(ctx context.Context) {
 		)
 	}
 
-	for _, reminder := range reminders {
+	for _, candidate := range reminders {
 		s.logger.Info("found due reminder",
-			zap.String("reminder_id", reminder.ID.String()),
-			zap.String("title", reminder.Title),
-			zap.String("type", string(reminder.Type)),
-			zap.Time("next_send_at", *reminder.NextSendAt),
+			zap.String("reminder_id", candidate.ID.String()),
+			zap.String("title", candidate.Title),
+			zap.String("type", string(candidate.Type)),
+			zap.Time("next_send_at", *candidate.NextSendAt),
 			zap.Time("current_time", now),
 		)
-		if err := s.sendReminder(ctx, reminder); err != nil {
-			s.logger.Error("failed to send reminder",
+
+		delivery, err := s.deliveryUsecase.Claim(ctx, candidate.ID, now)
+		if err != nil {
+			s.logger.Error("failed to claim reminder",
+				zap.Error(err),
+				zap.String("reminder_id", candidate.ID.String()),
+			)
+			continue
+		}
+		if delivery == nil {
+			s.logger.Debug("reminder claimed by another instance",
+				zap.String("reminder_id", candidate.ID.String()),
+			)
+			continue
+		}
+
+		if delivery.Execution != nil {
+			s.deliver(ctx, delivery)
+		}
+		if delivery.FinishesCourse {
+			s.finishCourse(ctx, delivery.Reminder)
+		}
+	}
+}
+
+// processDeliveries retries reminders whose send failed, once their backoff
+// is over, and those whose sender stopped before reporting back.
+func (s *Scheduler) processDeliveries(ctx context.Context) {
+	now := time.Now()
+	executions, err := s.deliveryUsecase.GetDueRetries(ctx, now)
+	if err != nil {
+		s.logger.Error("failed to get due deliveries", zap.Error(err))
+		return
+	}
+
+	for _, execution := range executions {
+		delivery, err := s.deliveryUsecase.ClaimRetry(ctx, execution.ID, now)
+		if err != nil {
+			s.logger.Error("failed to claim delivery",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			continue
+		}
+		if delivery == nil {
+			continue
+		}
+
+		s.deliver(ctx, delivery)
+	}
+}
+
+// processSnoozed re-delivers snoozed executions whose snooze has expired.
+// The reminder's regular schedule is left untouched. Each execution is
+// claimed first, so parallel instances deliver it once.
+func (s *Scheduler) processSnoozed(ctx context.Context) {
+	executions, err := s.executionUsecase.GetDueSnoozed(ctx)
+	if err != nil {
+		s.logger.Error("failed to get snoozed executions", zap.Error(err))
+		return
+	}
+
+	for _, execution := range executions {
+		claimed, err := s.executionUsecase.ClaimSnoozed(ctx, execution.ID)
+		if err != nil {
+			s.logger.Error("failed to claim snoozed execution",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		reminder, err := s.reminderRepo.GetByID(ctx, execution.ReminderID)
+		if err != nil {
+			s.logger.Error("failed to get snoozed reminder",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			continue
+		}
+
+		if reminder == nil || !reminder.IsActive {
+			if _, err := s.executionUsecase.RecordMissed(ctx, execution.ID); err != nil {
+				s.logger.Error("failed to close snoozed execution",
+					zap.Error(err),
+					zap.String("execution_id", execution.ID.String()),
+				)
+			}
+			continue
+		}
+
+		messageID, err := s.handler.SendReminder(ctx, reminder, execution.ID)
+		if err != nil {
+			s.logger.Error("failed to re-deliver snoozed reminder",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			continue
+		}
+
+		if err := s.executionUsecase.RecordDelivered(ctx, execution.ID, messageID); err != nil {
+			s.logger.Error("failed to record re-delivery",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			continue
+		}
+
+		s.logger.Info("snoozed reminder re-delivered",
+			zap.String("reminder_id", execution.ReminderID.String()),
+			zap.String("execution_id", execution.ID.String()),
+		)
+	}
+}
+
+// processNags repeats unanswered reminders of nagging reminders and marks
+// the dose as missed once all repeats went unanswered. A repeat is counted
+// before it is sent, so parallel instances send it once, and taken back when
+// the send fails, so it is tried again on the next run.
+func (s *Scheduler) processNags(ctx context.Context) {
+	executions, err := s.executionUsecase.GetDueNags(ctx)
+	if err != nil {
+		s.logger.Error("failed to get unanswered executions", zap.Error(err))
+		return
+	}
+
+	for _, execution := range executions {
+		reminder, err := s.reminderRepo.GetByID(ctx, execution.ReminderID)
+		if err != nil {
+			s.logger.Error("failed to get unanswered reminder",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			continue
+		}
+
+		if reminder == nil || !reminder.Nags() || execution.NagCount >= *reminder.NagMaxRepeats {
+			s.markMissed(ctx, reminder, execution)
+			continue
+		}
+
+		claimed, err := s.executionUsecase.ClaimNag(ctx, execution)
+		if err != nil {
+			s.logger.Error("failed to claim repeated reminder",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		messageID, err := s.handler.SendNag(ctx, reminder, execution)
+		if err != nil {
+			s.logger.Error("failed to repeat reminder",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+			if err := s.executionUsecase.ReleaseNag(ctx, execution); err != nil {
+				s.logger.Error("failed to release repeated reminder",
+					zap.Error(err),
+					zap.String("execution_id", execution.ID.String()),
+				)
+			}
+			continue
+		}
+
+		if err := s.executionUsecase.RecordNagged(ctx, execution.ID, messageID); err != nil {
+			s.logger.Error("failed to record repeated reminder",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+		}
+	}
+}
+
+// processUnanswered counts doses that have waited too long for an answer as
+// missed.
+func (s *Scheduler) processUnanswered(ctx context.Context) {
+	count, err := s.executionUsecase.ExpireUnanswered(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("failed to expire unanswered executions", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		s.logger.Info("unanswered executions marked as missed", zap.Int64("count", count))
+	}
+}
+
+// processEscalations notifies caregivers about doses that stayed
+// unconfirmed past the patient's caregiver delay. Each dose is claimed
+// before the caregivers are told, so it is escalated once.
+func (s *Scheduler) processEscalations(ctx context.Context) {
+	executions, err := s.executionUsecase.GetDueEscalations(ctx)
+	if err != nil {
+		s.logger.Error("failed to get executions to escalate", zap.Error(err))
+		return
+	}
+
+	for _, execution := range executions {
+		reminder, err := s.reminderRepo.GetByID(ctx, execution.ReminderID)
+		if err != nil {
+			s.logger.Error("failed to get escalated reminder",
 				zap.Error(err),
-				zap.String("reminder_id", reminder.ID.String()),
+				zap.String("execution_id", execution.ID.String()),
 			)
 			continue
 		}
 
-		nextSendTime := s.reminderUsecase.CalculateNextSendTime(reminder)
-		if err := s.reminderRepo.UpdateNextSendAt(ctx, reminder.ID, nextSendTime); err != nil {
-			s.logger.Error("failed to update next send time",
+		var caregivers []*entities.User
+		if reminder != nil {
+			caregivers, err = s.caregiverUsecase.GetCaregivers(ctx, execution.UserID)
+			if err != nil {
+				s.logger.Error("failed to get caregivers",
+					zap.Error(err),
+					zap.String("execution_id", execution.ID.String()),
+				)
+				continue
+			}
+		}
+
+		claimed, err := s.executionUsecase.ClaimEscalation(ctx, execution.ID)
+		if err != nil {
+			s.logger.Error("failed to record escalated execution",
 				zap.Error(err),
-				zap.String("reminder_id", reminder.ID.String()),
+				zap.String("execution_id", execution.ID.String()),
 			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		for _, caregiver := range caregivers {
+			if err := s.handler.SendCaregiverAlert(ctx, caregiver, reminder, execution); err != nil {
+				s.logger.Error("failed to notify caregiver",
+					zap.Error(err),
+					zap.String("execution_id", execution.ID.String()),
+					zap.String("caregiver_id", caregiver.ID.String()),
+				)
+			}
 		}
+	}
+}
 
+// processLowStock warns about stocks that are running low. A stock is
+// marked as warned before the warning is sent, so it is sent once.
+func (s *Scheduler) processLowStock(ctx context.Context) {
+	stocks, err := s.stockUsecase.GetLowStock(ctx)
+	if err != nil {
+		s.logger.Error("failed to get low stock", zap.Error(err))
+		return
+	}
+
+	for _, low := range stocks {
+		claimed, err := s.stockUsecase.ClaimWarning(ctx, low.Stock.ID)
+		if err != nil {
+			s.logger.Error("failed to mark stock warned",
+				zap.Error(err),
+				zap.String("reminder_id", low.Reminder.ID.String()),
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.handler.SendLowStock(ctx, low); err != nil {
+			s.logger.Error("failed to send low stock warning",
+				zap.Error(err),
+				zap.String("reminder_id", low.Reminder.ID.String()),
+			)
+		}
+	}
+}
+
+// processDigests sends the weekly digests that are due. Each digest is
+// scheduled for the next week before it is sent, so it is sent once.
+func (s *Scheduler) processDigests(ctx context.Context) {
+	users, err := s.digestUsecase.GetDue(ctx)
+	if err != nil {
+		s.logger.Error("failed to get due digests", zap.Error(err))
+		return
+	}
+
+	for _, user := range users {
 		now := time.Now()
-		if err := s.reminderRepo.UpdateLastSentAt(ctx, reminder.ID, now); err != nil {
-			s.logger.Error("failed to update last sent time",
+		digest, err := s.digestUsecase.Build(ctx, user, now)
+		if err != nil {
+			s.logger.Error("failed to build digest",
+				zap.Error(err),
+				zap.String("user_id", user.ID.String()),
+			)
+			continue
+		}
+
+		claimed, err := s.digestUsecase.Claim(ctx, user, now)
+		if err != nil {
+			s.logger.Error("failed to schedule next digest",
 				zap.Error(err),
-				zap.String("reminder_id", reminder.ID.String()),
+				zap.String("user_id", user.ID.String()),
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.handler.SendDigest(ctx, user, digest); err != nil {
+			s.logger.Error("failed to send digest",
+				zap.Error(err),
+				zap.String("user_id", user.ID.String()),
 			)
 		}
 	}
 }
 
-func (s *Scheduler) sendReminder(ctx context.Context, reminder *entities.Reminder) error {
-	execution, err := s.executionUsecase.RecordSent(ctx, reminder.ID, reminder.UserID)
+func (s *Scheduler) markMissed(ctx context.Context, reminder *entities.Reminder, execution *entities.ReminderExecution) {
+	missed, err := s.executionUsecase.RecordMissed(ctx, execution.ID)
 	if err != nil {
-		return fmt.Errorf("failed to record sent execution: %w", err)
+		s.logger.Error("failed to record missed execution",
+			zap.Error(err),
+			zap.String("execution_id", execution.ID.String()),
+		)
+		return
 	}
 
-	if err := s.handler.SendReminder(ctx, reminder, execution.ID); err != nil {
-		return fmt.Errorf("failed to send reminder: %w", err)
+	if !missed || reminder == nil {
+		return
+	}
+	if err := s.handler.SendMissed(ctx, reminder, execution); err != nil {
+		s.logger.Error("failed to send missed notice",
+			zap.Error(err),
+			zap.String("execution_id", execution.ID.String()),
+		)
 	}
 
-	s.logger.Info("reminder sent",
+	s.logger.Info("execution marked as missed",
 		zap.String("reminder_id", reminder.ID.String()),
 		zap.String("execution_id", execution.ID.String()),
 	)
+}
+
+// finishCourse sends the user a summary of a reminder whose schedule has run
+// out, either at the end of its course of treatment or when its rrule has no
+// further occurrences. The claim has already turned the reminder off.
+func (s *Scheduler) finishCourse(ctx context.Context, reminder *entities.Reminder) {
+	summary, err := s.executionUsecase.GetCourseSummary(ctx, reminder)
+	if err != nil {
+		s.logger.Error("failed to get course summary",
+			zap.Error(err),
+			zap.String("reminder_id", reminder.ID.String()),
+		)
+		return
+	}
 
-	return nil
+	if err := s.handler.SendCourseFinished(ctx, reminder, summary); err != nil {
+		s.logger.Error("failed to send course summary",
+			zap.Error(err),
+			zap.String("reminder_id", reminder.ID.String()),
+		)
+		return
+	}
+
+	s.logger.Info("course finished",
+		zap.String("reminder_id", reminder.ID.String()),
+		zap.Int("total_doses", summary.TotalDoses),
+	)
+}
+
+// deliver sends a claimed reminder and records the outcome: the message it
+// was delivered in or, when sending failed, the next attempt.
+func (s *Scheduler) deliver(ctx context.Context, delivery *usecases.Delivery) {
+	reminder, execution := delivery.Reminder, delivery.Execution
+
+	messageID, err := s.handler.SendReminder(ctx, reminder, execution.ID)
+	if err != nil {
+		retryAt, recordErr := s.deliveryUsecase.RecordFailed(ctx, execution, err, time.Now())
+		switch {
+		case recordErr != nil:
+			s.logger.Error("failed to record failed delivery",
+				zap.Error(recordErr),
+				zap.NamedError("send_error", err),
+				zap.String("execution_id", execution.ID.String()),
+			)
+		case retryAt != nil:
+			s.logger.Warn("failed to send reminder, will retry",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+				zap.Int("attempt", execution.DeliveryAttempts),
+				zap.Time("retry_at", *retryAt),
+			)
+		default:
+			s.logger.Error("failed to send reminder, giving up",
+				zap.Error(err),
+				zap.String("execution_id", execution.ID.String()),
+				zap.Int("attempts", execution.DeliveryAttempts),
+			)
+		}
+		return
+	}
+
+	if err := s.executionUsecase.RecordDelivered(ctx, execution.ID, messageID); err != nil {
+		s.logger.Error("failed to record delivered message",
+			zap.Error(err),
+			zap.String("execution_id", execution.ID.String()),
+		)
+	}
+
+	s.logger.Info("reminder sent",
+		zap.String("reminder_id", reminder.ID.String()),
+		zap.String("execution_id", execution.ID.String()),
+	)
 }
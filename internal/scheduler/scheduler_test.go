@@ -0,0 +1,423 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/handlers"
+	"github.com/Helltale/take-your-pills-on-time/internal/migrations"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+	"github.com/Helltale/take-your-pills-on-time/internal/usecases"
+)
+
+// testDatabaseEnv names the PostgreSQL DSN the scheduler tests run against.
+// Without it they are skipped.
+const testDatabaseEnv = "TEST_DATABASE_DSN"
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, migrations.NewMigrator(db, zap.NewNop()).Run())
+
+	return db
+}
+
+// fakeTelegram answers Bot API requests and counts the messages sent to
+// each chat. While failures is positive, sending a message fails.
+type fakeTelegram struct {
+	mu        sync.Mutex
+	messageID int
+	sent      map[string]int
+	failures  int
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	result := "true"
+	switch path.Base(r.URL.Path) {
+	case "getMe":
+		result = `{"id": 1, "is_bot": true, "first_name": "Test", "username": "test_bot"}`
+	case "sendMessage", "sendPhoto":
+		f.mu.Lock()
+		if f.failures > 0 {
+			f.failures--
+			f.mu.Unlock()
+			fmt.Fprint(w, `{"ok": false, "error_code": 502, "description": "Bad Gateway"}`)
+			return
+		}
+		f.messageID++
+		f.sent[r.PostForm.Get("chat_id")]++
+		result = fmt.Sprintf(`{"message_id": %d, "date": 0, "chat": {"id": %s}}`, f.messageID, r.PostForm.Get("chat_id"))
+		f.mu.Unlock()
+	}
+	fmt.Fprintf(w, `{"ok": true, "result": %s}`, result)
+}
+
+func (f *fakeTelegram) sentTo(chatID int64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sent[fmt.Sprint(chatID)]
+}
+
+func newTestBot(t *testing.T, telegram *fakeTelegram) *tgbotapi.BotAPI {
+	t.Helper()
+
+	server := httptest.NewServer(telegram)
+	t.Cleanup(server.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("test-token", server.URL+"/bot%s/%s", server.Client())
+	require.NoError(t, err)
+	return bot
+}
+
+// newTestUser inserts a user with count reminders due in the past and
+// removes them when the test ends.
+func newTestUser(t *testing.T, db *gorm.DB, repo *repository.Repository, count int) (*entities.User, []*entities.Reminder) {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &entities.User{
+		TelegramID: rand.Int63n(1 << 40),
+		FirstName:  "Test",
+		IsActive:   true,
+	}
+	require.NoError(t, repo.User.Create(ctx, user))
+	t.Cleanup(func() {
+		db.Where("user_id = ?", user.ID).Delete(&entities.ReminderExecution{})
+		db.Where("user_id = ?", user.ID).Delete(&entities.Reminder{})
+		db.Delete(&entities.User{}, "id = ?", user.ID)
+	})
+
+	var due []*entities.Reminder
+	for i := 0; i < count; i++ {
+		nextSendAt := time.Now().Add(-time.Duration(i+1) * time.Minute)
+		reminder := &entities.Reminder{
+			UserID:     user.ID,
+			Title:      fmt.Sprintf("Таблетка %d", i+1),
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &nextSendAt,
+		}
+		require.NoError(t, repo.Reminder.Create(ctx, reminder))
+		due = append(due, reminder)
+	}
+	return user, due
+}
+
+func newTestScheduler(bot *tgbotapi.BotAPI, repo *repository.Repository) *Scheduler {
+	uc := usecases.NewUsecases(repo)
+	handler := handlers.NewBotHandler(bot, uc, zap.NewNop())
+	return NewScheduler(repo.Reminder, uc.ReminderExecution, uc.Reminder, uc.Caregiver, uc.Stock, uc.Digest, uc.Delivery, handler, zap.NewNop())
+}
+
+func TestScheduler_ProcessReminders_ParallelInstances(t *testing.T) {
+	const (
+		instances = 4
+		reminders = 10
+	)
+
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	telegram := &fakeTelegram{sent: map[string]int{}}
+	bot := newTestBot(t, telegram)
+	repo := repository.NewRepository(db)
+	user, due := newTestUser(t, db, repo, reminders)
+
+	// Every instance has its own usecases and handler, as separate
+	// replicas would; only the database is shared.
+	var wg sync.WaitGroup
+	for i := 0; i < instances; i++ {
+		s := newTestScheduler(bot, repo)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.processReminders(ctx)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, reminders, telegram.sentTo(user.TelegramID))
+
+	for _, reminder := range due {
+		executions, err := repo.ReminderExecution.GetByReminderID(ctx, reminder.ID, 10)
+		require.NoError(t, err)
+		require.Len(t, executions, 1, "reminder %s", reminder.Title)
+		assert.NotNil(t, executions[0].MessageID, "reminder %s", reminder.Title)
+		assert.Equal(t, entities.DeliveryStatusDelivered, executions[0].DeliveryStatus, "reminder %s", reminder.Title)
+
+		stored, err := repo.Reminder.GetByID(ctx, reminder.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.NextSendAt.After(time.Now()), "reminder %s", reminder.Title)
+	}
+}
+
+func TestScheduler_ProcessDeliveries_RetriesFailedSends(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	telegram := &fakeTelegram{sent: map[string]int{}, failures: 1}
+	bot := newTestBot(t, telegram)
+	repo := repository.NewRepository(db)
+	user, due := newTestUser(t, db, repo, 1)
+	s := newTestScheduler(bot, repo)
+
+	s.processReminders(ctx)
+
+	var executions []*entities.ReminderExecution
+	require.NoError(t, db.Where("reminder_id = ?", due[0].ID).Find(&executions).Error)
+	require.Len(t, executions, 1)
+	execution := executions[0]
+	assert.Equal(t, entities.DeliveryStatusPending, execution.DeliveryStatus)
+	assert.Equal(t, 1, execution.DeliveryAttempts)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *execution.NextAttemptAt, 5*time.Second)
+	assert.Zero(t, telegram.sentTo(user.TelegramID))
+
+	stored, err := repo.Reminder.GetByID(ctx, due[0].ID)
+	require.NoError(t, err)
+	assert.True(t, stored.NextSendAt.After(time.Now()), "a failed send does not hold the schedule back")
+
+	// The retry is not due before its backoff is over.
+	s.processDeliveries(ctx)
+	assert.Zero(t, telegram.sentTo(user.TelegramID))
+
+	require.NoError(t, db.Model(execution).Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+	s.processDeliveries(ctx)
+
+	assert.Equal(t, 1, telegram.sentTo(user.TelegramID))
+	delivered, err := repo.ReminderExecution.GetByID(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.DeliveryStatusDelivered, delivered.DeliveryStatus)
+	assert.Equal(t, 2, delivered.DeliveryAttempts)
+	assert.NotNil(t, delivered.MessageID)
+}
+
+func TestScheduler_ProcessNags_RetriesFailedSends(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	telegram := &fakeTelegram{sent: map[string]int{}, failures: 1}
+	bot := newTestBot(t, telegram)
+	repo := repository.NewRepository(db)
+	user, _ := newTestUser(t, db, repo, 0)
+	s := newTestScheduler(bot, repo)
+
+	nextSendAt := time.Now().Add(time.Hour)
+	interval, repeats := 5, 3
+	reminder := &entities.Reminder{
+		UserID:             user.ID,
+		Title:              "Настойчивая",
+		Type:               entities.ReminderTypeDaily,
+		TimesOfDay:         entities.TimesOfDay{"09:00"},
+		IsActive:           true,
+		NextSendAt:         &nextSendAt,
+		NagIntervalMinutes: &interval,
+		NagMaxRepeats:      &repeats,
+	}
+	require.NoError(t, repo.Reminder.Create(ctx, reminder))
+	sentAt := time.Now().Add(-10 * time.Minute)
+	execution := &entities.ReminderExecution{
+		ReminderID:  reminder.ID,
+		UserID:      user.ID,
+		Status:      entities.ExecutionStatusSent,
+		SentAt:      sentAt,
+		DeliveredAt: &sentAt,
+	}
+	require.NoError(t, repo.ReminderExecution.Create(ctx, execution))
+
+	s.processNags(ctx)
+
+	stored, err := repo.ReminderExecution.GetByID(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.NagCount, "a repeat that was not sent is not counted")
+	assert.Nil(t, stored.NaggedAt)
+	assert.Zero(t, telegram.sentTo(user.TelegramID))
+
+	s.processNags(ctx)
+
+	stored, err = repo.ReminderExecution.GetByID(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.NagCount)
+	assert.NotNil(t, stored.NaggedAt)
+	assert.Equal(t, 1, telegram.sentTo(user.TelegramID))
+}
+
+func TestScheduler_FollowUpJobs_ParallelInstances(t *testing.T) {
+	const instances = 4
+
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	telegram := &fakeTelegram{sent: map[string]int{}}
+	bot := newTestBot(t, telegram)
+	repo := repository.NewRepository(db)
+
+	now := time.Now()
+	newReminder := func(user *entities.User, title string) *entities.Reminder {
+		nextSendAt := now.Add(time.Hour)
+		reminder := &entities.Reminder{
+			UserID:     user.ID,
+			Title:      title,
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &nextSendAt,
+		}
+		require.NoError(t, repo.Reminder.Create(ctx, reminder))
+		return reminder
+	}
+	newExecution := func(reminder *entities.Reminder, execution *entities.ReminderExecution) *entities.ReminderExecution {
+		execution.ReminderID = reminder.ID
+		execution.UserID = reminder.UserID
+		require.NoError(t, repo.ReminderExecution.Create(ctx, execution))
+		return execution
+	}
+
+	// A snoozed dose whose snooze is over.
+	snoozeUser, _ := newTestUser(t, db, repo, 0)
+	snoozedUntil := now.Add(-time.Minute)
+	snoozed := newExecution(newReminder(snoozeUser, "Отложенная"), &entities.ReminderExecution{
+		Status:       entities.ExecutionStatusSnoozed,
+		SentAt:       now.Add(-time.Hour),
+		DeliveredAt:  &snoozedUntil,
+		SnoozedUntil: &snoozedUntil,
+	})
+
+	// An unanswered dose of a nagging reminder due for its first repeat.
+	nagUser, _ := newTestUser(t, db, repo, 0)
+	nagReminder := newReminder(nagUser, "Настойчивая")
+	interval, repeats := 5, 3
+	require.NoError(t, db.Model(nagReminder).Updates(map[string]interface{}{
+		"nag_interval_minutes": interval,
+		"nag_max_repeats":      repeats,
+	}).Error)
+	nagSentAt := now.Add(-10 * time.Minute)
+	nagged := newExecution(nagReminder, &entities.ReminderExecution{
+		Status:      entities.ExecutionStatusSent,
+		SentAt:      nagSentAt,
+		DeliveredAt: &nagSentAt,
+	})
+
+	// An unconfirmed dose of a patient with a caregiver, past the delay.
+	patient, _ := newTestUser(t, db, repo, 0)
+	caregiver, _ := newTestUser(t, db, repo, 0)
+	acceptedAt := now.Add(-3 * time.Hour)
+	require.NoError(t, repo.Caregiver.Create(ctx, &entities.Caregiver{
+		PatientID:   patient.ID,
+		CaregiverID: &caregiver.ID,
+		InviteToken: fmt.Sprintf("test-%d", rand.Int63()),
+		Status:      entities.CaregiverStatusAccepted,
+		AcceptedAt:  &acceptedAt,
+	}))
+	t.Cleanup(func() {
+		db.Where("patient_id = ?", patient.ID).Delete(&entities.Caregiver{})
+	})
+	escalatedSentAt := now.Add(-2 * time.Hour)
+	escalated := newExecution(newReminder(patient, "Под присмотром"), &entities.ReminderExecution{
+		Status:      entities.ExecutionStatusSent,
+		SentAt:      escalatedSentAt,
+		DeliveredAt: &escalatedSentAt,
+	})
+
+	// A stock that lasts two more days against a week's threshold.
+	stockUser, _ := newTestUser(t, db, repo, 0)
+	stock := &entities.Stock{
+		ReminderID:          newReminder(stockUser, "Заканчивается").ID,
+		UserID:              stockUser.ID,
+		OnHand:              2,
+		PerDose:             1,
+		RefillThresholdDays: 7,
+	}
+	require.NoError(t, repo.Stock.Create(ctx, stock))
+	t.Cleanup(func() {
+		db.Where("user_id = ?", stockUser.ID).Delete(&entities.Stock{})
+	})
+
+	// A weekly digest that is due.
+	digestUser, _ := newTestUser(t, db, repo, 0)
+	require.NoError(t, db.Model(digestUser).Updates(map[string]interface{}{
+		"digest_weekday": int(now.Weekday()),
+		"digest_time":    "09:00",
+		"digest_next_at": now.Add(-time.Minute),
+	}).Error)
+
+	var wg sync.WaitGroup
+	for i := 0; i < instances; i++ {
+		s := newTestScheduler(bot, repo)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.processSnoozed(ctx)
+			s.processNags(ctx)
+			s.processEscalations(ctx)
+			s.processLowStock(ctx)
+			s.processDigests(ctx)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, telegram.sentTo(snoozeUser.TelegramID), "snoozed reminder")
+	assert.Equal(t, 1, telegram.sentTo(nagUser.TelegramID), "repeated reminder")
+	assert.Equal(t, 1, telegram.sentTo(caregiver.TelegramID), "caregiver alert")
+	assert.Zero(t, telegram.sentTo(patient.TelegramID), "patient")
+	assert.Equal(t, 1, telegram.sentTo(stockUser.TelegramID), "low stock warning")
+	assert.Equal(t, 1, telegram.sentTo(digestUser.TelegramID), "digest")
+
+	stored, err := repo.ReminderExecution.GetByID(ctx, snoozed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ExecutionStatusSent, stored.Status)
+	assert.NotNil(t, stored.MessageID)
+
+	stored, err = repo.ReminderExecution.GetByID(ctx, nagged.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.NagCount)
+	assert.NotNil(t, stored.MessageID)
+
+	stored, err = repo.ReminderExecution.GetByID(ctx, escalated.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.EscalatedAt)
+
+	storedStock, err := repo.Stock.GetByReminderID(ctx, stock.ReminderID)
+	require.NoError(t, err)
+	assert.NotNil(t, storedStock.LowWarnedAt)
+
+	storedUser, err := repo.User.GetByID(ctx, digestUser.ID)
+	require.NoError(t, err)
+	assert.True(t, storedUser.DigestNextAt.After(now))
+}
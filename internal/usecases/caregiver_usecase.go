@@ -0,0 +1,207 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+)
+
+// InviteTTL is how long a caregiver invite link stays valid.
+const InviteTTL = 7 * 24 * time.Hour
+
+type CaregiverUsecase interface {
+	CreateInvite(ctx context.Context, patientID uuid.UUID) (*entities.Caregiver, error)
+	GetInvite(ctx context.Context, token string) (*entities.Caregiver, error)
+	AcceptInvite(ctx context.Context, token string, caregiverID uuid.UUID) (*entities.Caregiver, error)
+	GetCaregivers(ctx context.Context, patientID uuid.UUID) ([]*entities.User, error)
+	GetPatients(ctx context.Context, caregiverID uuid.UUID) ([]*entities.User, error)
+	IsCaregiverOf(ctx context.Context, caregiverID, patientID uuid.UUID) (bool, error)
+	Remove(ctx context.Context, patientID, caregiverID uuid.UUID) error
+	SetDelay(ctx context.Context, patientID uuid.UUID, minutes int) (*entities.User, error)
+}
+
+type caregiverUsecase struct {
+	repo     repository.CaregiverRepository
+	userRepo repository.UserRepository
+}
+
+func NewCaregiverUsecase(repo repository.CaregiverRepository, userRepo repository.UserRepository) CaregiverUsecase {
+	return &caregiverUsecase{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (u *caregiverUsecase) CreateInvite(ctx context.Context, patientID uuid.UUID) (*entities.Caregiver, error) {
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite token: %w", err)
+	}
+
+	invite := &entities.Caregiver{
+		PatientID:   patientID,
+		InviteToken: token,
+		Status:      entities.CaregiverStatusPending,
+	}
+	if err := u.repo.Create(ctx, invite); err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// GetInvite returns a pending, unexpired invite by its token.
+func (u *caregiverUsecase) GetInvite(ctx context.Context, token string) (*entities.Caregiver, error) {
+	invite, err := u.repo.GetByInviteToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+	if invite == nil || invite.Status != entities.CaregiverStatusPending || time.Since(invite.CreatedAt) > InviteTTL {
+		return nil, fmt.Errorf("invite not found or expired")
+	}
+	return invite, nil
+}
+
+func (u *caregiverUsecase) AcceptInvite(ctx context.Context, token string, caregiverID uuid.UUID) (*entities.Caregiver, error) {
+	invite, err := u.GetInvite(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if invite.PatientID == caregiverID {
+		return nil, fmt.Errorf("cannot become your own caregiver")
+	}
+
+	already, err := u.IsCaregiverOf(ctx, caregiverID, invite.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	if already {
+		return nil, fmt.Errorf("already a caregiver of this user")
+	}
+
+	now := time.Now()
+	accepted, err := u.repo.Accept(ctx, invite.ID, caregiverID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invite: %w", err)
+	}
+	if !accepted {
+		return nil, fmt.Errorf("invite not found or expired")
+	}
+	invite.CaregiverID = &caregiverID
+	invite.Status = entities.CaregiverStatusAccepted
+	invite.AcceptedAt = &now
+
+	return invite, nil
+}
+
+func (u *caregiverUsecase) GetCaregivers(ctx context.Context, patientID uuid.UUID) ([]*entities.User, error) {
+	links, err := u.repo.GetAcceptedByPatientID(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caregivers: %w", err)
+	}
+
+	users := make([]*entities.User, 0, len(links))
+	for _, link := range links {
+		if link.CaregiverID == nil {
+			continue
+		}
+		user, err := u.userRepo.GetByID(ctx, *link.CaregiverID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user != nil {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (u *caregiverUsecase) GetPatients(ctx context.Context, caregiverID uuid.UUID) ([]*entities.User, error) {
+	links, err := u.repo.GetAcceptedByCaregiverID(ctx, caregiverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patients: %w", err)
+	}
+
+	users := make([]*entities.User, 0, len(links))
+	for _, link := range links {
+		user, err := u.userRepo.GetByID(ctx, link.PatientID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user != nil {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (u *caregiverUsecase) IsCaregiverOf(ctx context.Context, caregiverID, patientID uuid.UUID) (bool, error) {
+	links, err := u.repo.GetAcceptedByCaregiverID(ctx, caregiverID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get patients: %w", err)
+	}
+	for _, link := range links {
+		if link.PatientID == patientID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Remove ends the relationship between a patient and a caregiver. Either
+// side may call it.
+func (u *caregiverUsecase) Remove(ctx context.Context, patientID, caregiverID uuid.UUID) error {
+	links, err := u.repo.GetAcceptedByPatientID(ctx, patientID)
+	if err != nil {
+		return fmt.Errorf("failed to get caregivers: %w", err)
+	}
+	for _, link := range links {
+		if link.CaregiverID != nil && *link.CaregiverID == caregiverID {
+			if err := u.repo.Delete(ctx, link.ID); err != nil {
+				return fmt.Errorf("failed to remove caregiver: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("caregiver not found")
+}
+
+// SetDelay sets how many minutes a dose may stay unconfirmed before the
+// patient's caregivers are notified.
+func (u *caregiverUsecase) SetDelay(ctx context.Context, patientID uuid.UUID, minutes int) (*entities.User, error) {
+	if minutes <= 0 || minutes > 24*60 {
+		return nil, fmt.Errorf("delay must be between 1 and 1440 minutes")
+	}
+
+	user, err := u.userRepo.GetByID(ctx, patientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	user.CaregiverDelayMinutes = &minutes
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// newInviteToken returns a random token that fits a Telegram /start
+// payload.
+func newInviteToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
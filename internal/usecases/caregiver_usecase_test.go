@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository/mocks"
+)
+
+func TestCaregiverUsecase_CreateInvite(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	usecase := NewCaregiverUsecase(mockRepo, mockUserRepo)
+
+	patientID := uuid.New()
+	mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	invite, err := usecase.CreateInvite(ctx, patientID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, patientID, invite.PatientID)
+	assert.Equal(t, entities.CaregiverStatusPending, invite.Status)
+	assert.Len(t, invite.InviteToken, 32)
+	assert.Nil(t, invite.CaregiverID)
+}
+
+func TestCaregiverUsecase_AcceptInvite(t *testing.T) {
+	ctx := context.Background()
+	patientID := uuid.New()
+	caregiverID := uuid.New()
+
+	pendingInvite := func() *entities.Caregiver {
+		return &entities.Caregiver{
+			ID:          uuid.New(),
+			PatientID:   patientID,
+			InviteToken: "token",
+			Status:      entities.CaregiverStatusPending,
+			CreatedAt:   time.Now().Add(-time.Hour),
+		}
+	}
+
+	t.Run("successful accept", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		mockRepo.EXPECT().GetByInviteToken(ctx, "token").Return(pendingInvite(), nil)
+		mockRepo.EXPECT().GetAcceptedByCaregiverID(ctx, caregiverID).Return(nil, nil)
+		mockRepo.EXPECT().Accept(ctx, gomock.Any(), caregiverID, gomock.Any()).Return(true, nil)
+
+		invite, err := usecase.AcceptInvite(ctx, "token", caregiverID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.CaregiverStatusAccepted, invite.Status)
+		assert.Equal(t, caregiverID, *invite.CaregiverID)
+		assert.NotNil(t, invite.AcceptedAt)
+	})
+
+	t.Run("accepted meanwhile", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		invite := pendingInvite()
+		mockRepo.EXPECT().GetByInviteToken(ctx, "token").Return(invite, nil)
+		mockRepo.EXPECT().GetAcceptedByCaregiverID(ctx, caregiverID).Return(nil, nil)
+		mockRepo.EXPECT().Accept(ctx, invite.ID, caregiverID, gomock.Any()).Return(false, nil)
+
+		_, err := usecase.AcceptInvite(ctx, "token", caregiverID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invite not found or expired")
+	})
+
+	t.Run("expired invite", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		invite := pendingInvite()
+		invite.CreatedAt = time.Now().Add(-InviteTTL - time.Hour)
+		mockRepo.EXPECT().GetByInviteToken(ctx, "token").Return(invite, nil)
+
+		_, err := usecase.AcceptInvite(ctx, "token", caregiverID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invite not found or expired")
+	})
+
+	t.Run("invite already used", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		invite := pendingInvite()
+		invite.Status = entities.CaregiverStatusAccepted
+		mockRepo.EXPECT().GetByInviteToken(ctx, "token").Return(invite, nil)
+
+		_, err := usecase.AcceptInvite(ctx, "token", caregiverID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invite not found or expired")
+	})
+
+	t.Run("own invite", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		mockRepo.EXPECT().GetByInviteToken(ctx, "token").Return(pendingInvite(), nil)
+
+		_, err := usecase.AcceptInvite(ctx, "token", patientID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot become your own caregiver")
+	})
+
+	t.Run("already a caregiver", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		mockRepo.EXPECT().GetByInviteToken(ctx, "token").Return(pendingInvite(), nil)
+		mockRepo.EXPECT().GetAcceptedByCaregiverID(ctx, caregiverID).Return([]*entities.Caregiver{
+			{PatientID: patientID, CaregiverID: &caregiverID, Status: entities.CaregiverStatusAccepted},
+		}, nil)
+
+		_, err := usecase.AcceptInvite(ctx, "token", caregiverID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already a caregiver of this user")
+	})
+}
+
+func TestCaregiverUsecase_GetCaregivers(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	usecase := NewCaregiverUsecase(mockRepo, mockUserRepo)
+
+	patientID := uuid.New()
+	caregiver := &entities.User{ID: uuid.New(), TelegramID: 42, FirstName: "Anna"}
+
+	mockRepo.EXPECT().GetAcceptedByPatientID(ctx, patientID).Return([]*entities.Caregiver{
+		{PatientID: patientID, CaregiverID: &caregiver.ID, Status: entities.CaregiverStatusAccepted},
+	}, nil)
+	mockUserRepo.EXPECT().GetByID(ctx, caregiver.ID).Return(caregiver, nil)
+
+	caregivers, err := usecase.GetCaregivers(ctx, patientID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*entities.User{caregiver}, caregivers)
+}
+
+func TestCaregiverUsecase_Remove(t *testing.T) {
+	ctx := context.Background()
+	patientID := uuid.New()
+	caregiverID := uuid.New()
+	linkID := uuid.New()
+
+	t.Run("successful remove", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		mockRepo.EXPECT().GetAcceptedByPatientID(ctx, patientID).Return([]*entities.Caregiver{
+			{ID: linkID, PatientID: patientID, CaregiverID: &caregiverID},
+		}, nil)
+		mockRepo.EXPECT().Delete(ctx, linkID).Return(nil)
+
+		err := usecase.Remove(ctx, patientID, caregiverID)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("not a caregiver", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockCaregiverRepository(ctrl)
+		usecase := NewCaregiverUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		mockRepo.EXPECT().GetAcceptedByPatientID(ctx, patientID).Return(nil, nil)
+
+		err := usecase.Remove(ctx, patientID, caregiverID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "caregiver not found")
+	})
+}
+
+func TestCaregiverUsecase_SetDelay(t *testing.T) {
+	ctx := context.Background()
+	patientID := uuid.New()
+
+	t.Run("successful set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewCaregiverUsecase(mocks.NewMockCaregiverRepository(ctrl), mockUserRepo)
+
+		mockUserRepo.EXPECT().GetByID(ctx, patientID).Return(&entities.User{ID: patientID}, nil)
+		mockUserRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		user, err := usecase.SetDelay(ctx, patientID, 30)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, user.CaregiverDelay())
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewCaregiverUsecase(mocks.NewMockCaregiverRepository(ctrl), mocks.NewMockUserRepository(ctrl))
+
+		for _, minutes := range []int{0, -5, 24*60 + 1} {
+			_, err := usecase.SetDelay(ctx, patientID, minutes)
+			assert.Error(t, err)
+		}
+	})
+}
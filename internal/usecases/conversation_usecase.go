@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+)
+
+// ConversationTTL is how long an untouched /new wizard is kept before it is
+// treated as abandoned.
+const ConversationTTL = 24 * time.Hour
+
+type ConversationUsecase interface {
+	Get(ctx context.Context, chatID int64) (*entities.Conversation, error)
+	Start(ctx context.Context, chatID int64, userID uuid.UUID) (*entities.Conversation, error)
+	StartEdit(ctx context.Context, chatID int64, reminder *entities.Reminder, step entities.ConversationStep) (*entities.Conversation, error)
+	StartSnooze(ctx context.Context, chatID int64, userID, executionID uuid.UUID, messageID int) (*entities.Conversation, error)
+	Save(ctx context.Context, conversation *entities.Conversation) error
+	Finish(ctx context.Context, chatID int64) error
+}
+
+type conversationUsecase struct {
+	repo repository.ConversationRepository
+}
+
+func NewConversationUsecase(repo repository.ConversationRepository) ConversationUsecase {
+	return &conversationUsecase{repo: repo}
+}
+
+// Get returns the chat's conversation, or nil when there is none or it has
+// been abandoned for longer than ConversationTTL.
+func (u *conversationUsecase) Get(ctx context.Context, chatID int64) (*entities.Conversation, error) {
+	conversation, err := u.repo.GetByChatID(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	if conversation == nil {
+		return nil, nil
+	}
+
+	if time.Since(conversation.UpdatedAt) > ConversationTTL {
+		if err := u.repo.Delete(ctx, chatID); err != nil {
+			return nil, fmt.Errorf("failed to delete conversation: %w", err)
+		}
+		return nil, nil
+	}
+
+	return conversation, nil
+}
+
+// Start begins a new wizard in the chat, discarding any unfinished one.
+func (u *conversationUsecase) Start(ctx context.Context, chatID int64, userID uuid.UUID) (*entities.Conversation, error) {
+	conversation := &entities.Conversation{
+		ChatID: chatID,
+		UserID: userID,
+		Step:   entities.ConversationStepTitle,
+	}
+	if err := u.repo.Save(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+// StartEdit begins changing one field of an existing reminder, starting at
+// step. The draft is filled from the reminder so that unchanged values are
+// kept.
+func (u *conversationUsecase) StartEdit(ctx context.Context, chatID int64, reminder *entities.Reminder, step entities.ConversationStep) (*entities.Conversation, error) {
+	conversation := &entities.Conversation{
+		ChatID:     chatID,
+		UserID:     reminder.UserID,
+		ReminderID: &reminder.ID,
+		Step:       step,
+		Draft: entities.ReminderDraft{
+			Title:              reminder.Title,
+			Type:               reminder.Type,
+			IntervalHours:      reminder.IntervalHours,
+			TimesOfDay:         reminder.TimesOfDay,
+			Weekdays:           reminder.Weekdays,
+			RRule:              reminder.RRule,
+			MinIntervalMinutes: reminder.MinIntervalMinutes,
+			MaxDosesPerDay:     reminder.MaxDosesPerDay,
+			Comment:            reminder.Comment,
+			ImageFileID:        reminder.ImageFileID,
+		},
+	}
+	if err := u.repo.Save(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+// StartSnooze waits for the user to type how long to snooze the execution
+// delivered in messageID, discarding any unfinished wizard.
+func (u *conversationUsecase) StartSnooze(ctx context.Context, chatID int64, userID, executionID uuid.UUID, messageID int) (*entities.Conversation, error) {
+	conversation := &entities.Conversation{
+		ChatID:      chatID,
+		UserID:      userID,
+		ExecutionID: &executionID,
+		MessageID:   &messageID,
+		Step:        entities.ConversationStepSnooze,
+	}
+	if err := u.repo.Save(ctx, conversation); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+func (u *conversationUsecase) Save(ctx context.Context, conversation *entities.Conversation) error {
+	if err := u.repo.Save(ctx, conversation); err != nil {
+		return fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return nil
+}
+
+func (u *conversationUsecase) Finish(ctx context.Context, chatID int64) error {
+	if err := u.repo.Delete(ctx, chatID); err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository/mocks"
+)
+
+func TestConversationUsecase_Get(t *testing.T) {
+	ctx := context.Background()
+	chatID := int64(12345)
+
+	t.Run("active conversation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockConversationRepository(ctrl)
+		usecase := NewConversationUsecase(mockRepo)
+
+		expected := &entities.Conversation{ChatID: chatID, Step: entities.ConversationStepSchedule, UpdatedAt: time.Now()}
+		mockRepo.EXPECT().GetByChatID(ctx, chatID).Return(expected, nil)
+
+		conversation, err := usecase.Get(ctx, chatID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, conversation)
+	})
+
+	t.Run("abandoned conversation is dropped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockConversationRepository(ctrl)
+		usecase := NewConversationUsecase(mockRepo)
+
+		mockRepo.EXPECT().GetByChatID(ctx, chatID).Return(&entities.Conversation{
+			ChatID:    chatID,
+			Step:      entities.ConversationStepTitle,
+			UpdatedAt: time.Now().Add(-ConversationTTL - time.Minute),
+		}, nil)
+		mockRepo.EXPECT().Delete(ctx, chatID).Return(nil)
+
+		conversation, err := usecase.Get(ctx, chatID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, conversation)
+	})
+
+	t.Run("no conversation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockConversationRepository(ctrl)
+		usecase := NewConversationUsecase(mockRepo)
+
+		mockRepo.EXPECT().GetByChatID(ctx, chatID).Return(nil, nil)
+
+		conversation, err := usecase.Get(ctx, chatID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, conversation)
+	})
+}
+
+func TestConversationUsecase_Start(t *testing.T) {
+	ctx := context.Background()
+	chatID := int64(12345)
+	userID := uuid.New()
+
+	t.Run("starts at title step", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockConversationRepository(ctrl)
+		usecase := NewConversationUsecase(mockRepo)
+
+		mockRepo.EXPECT().Save(ctx, gomock.Any()).Return(nil)
+
+		conversation, err := usecase.Start(ctx, chatID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, chatID, conversation.ChatID)
+		assert.Equal(t, userID, conversation.UserID)
+		assert.Equal(t, entities.ConversationStepTitle, conversation.Step)
+		assert.Empty(t, conversation.Draft.Title)
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockConversationRepository(ctrl)
+		usecase := NewConversationUsecase(mockRepo)
+
+		mockRepo.EXPECT().Save(ctx, gomock.Any()).Return(errors.New("db error"))
+
+		conversation, err := usecase.Start(ctx, chatID, userID)
+
+		assert.Error(t, err)
+		assert.Nil(t, conversation)
+		assert.Contains(t, err.Error(), "failed to save conversation")
+	})
+}
+
+func TestConversationUsecase_StartEdit(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockConversationRepository(ctrl)
+	usecase := NewConversationUsecase(mockRepo)
+
+	comment := "после еды"
+	reminder := &entities.Reminder{
+		ID:         uuid.New(),
+		UserID:     uuid.New(),
+		Title:      "Витамины",
+		Type:       entities.ReminderTypeSpecific,
+		TimesOfDay: entities.TimesOfDay{"09:00"},
+		Comment:    &comment,
+	}
+
+	mockRepo.EXPECT().Save(ctx, gomock.Any()).Return(nil)
+
+	conversation, err := usecase.StartEdit(ctx, 12345, reminder, entities.ConversationStepComment)
+
+	assert.NoError(t, err)
+	assert.True(t, conversation.IsEdit())
+	assert.Equal(t, reminder.ID, *conversation.ReminderID)
+	assert.Equal(t, reminder.UserID, conversation.UserID)
+	assert.Equal(t, entities.ConversationStepComment, conversation.Step)
+	assert.Equal(t, "Витамины", conversation.Draft.Title)
+	assert.Equal(t, []string{"09:00"}, conversation.Draft.TimesOfDay)
+}
+
+func TestConversationUsecase_StartSnooze(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockConversationRepository(ctrl)
+	usecase := NewConversationUsecase(mockRepo)
+
+	userID := uuid.New()
+	executionID := uuid.New()
+
+	mockRepo.EXPECT().Save(ctx, gomock.Any()).Return(nil)
+
+	conversation, err := usecase.StartSnooze(ctx, 12345, userID, executionID, 42)
+
+	assert.NoError(t, err)
+	assert.False(t, conversation.IsEdit())
+	assert.Equal(t, userID, conversation.UserID)
+	assert.Equal(t, entities.ConversationStepSnooze, conversation.Step)
+	assert.Equal(t, executionID, *conversation.ExecutionID)
+	assert.Equal(t, 42, *conversation.MessageID)
+	assert.Zero(t, entities.ConversationStepSnooze.Number(), "snoozing is not a wizard step")
+}
+
+func TestConversationStep_Order(t *testing.T) {
+	assert.Equal(t, entities.ConversationStepType, entities.ConversationStepTitle.Next())
+	assert.Equal(t, entities.ConversationStepSchedule, entities.ConversationStepComment.Previous())
+	assert.Empty(t, entities.ConversationStepTitle.Previous())
+	assert.Empty(t, entities.ConversationStepPhoto.Next())
+}
+
+func TestReminderDraft_ValueScan(t *testing.T) {
+	interval := 6
+	comment := "после еды"
+	draft := entities.ReminderDraft{
+		Title:         "Витамины",
+		Type:          entities.ReminderTypeCustom,
+		IntervalHours: &interval,
+		Comment:       &comment,
+	}
+
+	value, err := draft.Value()
+	assert.NoError(t, err)
+
+	var scanned entities.ReminderDraft
+	assert.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, draft, scanned)
+}
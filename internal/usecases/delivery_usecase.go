@@ -0,0 +1,228 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+)
+
+// DeliveryLease is how long the instance sending a reminder holds its
+// delivery. Should it stop before reporting back, another instance retries
+// the delivery once the lease is over.
+const DeliveryLease = 5 * time.Minute
+
+// MaxDeliveryAttempts is how many times a reminder is sent before its
+// delivery is given up as failed.
+const MaxDeliveryAttempts = 5
+
+// DeliveryUsecase hands out scheduled reminders to send. Each occurrence is
+// recorded and the reminder moved on to the next one in a single
+// transaction, so an occurrence is claimed exactly once however many
+// instances run; sending it happens after the commit and failed sends are
+// retried with backoff.
+type DeliveryUsecase interface {
+	Claim(ctx context.Context, reminderID uuid.UUID, now time.Time) (*Delivery, error)
+	GetDueRetries(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error)
+	ClaimRetry(ctx context.Context, executionID uuid.UUID, now time.Time) (*Delivery, error)
+	RecordFailed(ctx context.Context, execution *entities.ReminderExecution, sendErr error, now time.Time) (*time.Time, error)
+}
+
+// Delivery is a claimed occurrence of a reminder. Execution is the dose to
+// send, nil when the course had already ended. FinishesCourse is set when
+// the claim turned the reminder off because its schedule ran out.
+type Delivery struct {
+	Reminder       *entities.Reminder
+	Execution      *entities.ReminderExecution
+	FinishesCourse bool
+}
+
+type deliveryUsecase struct {
+	uow             repository.UnitOfWork
+	executionRepo   repository.ReminderExecutionRepository
+	reminderUsecase ReminderUsecase
+}
+
+func NewDeliveryUsecase(uow repository.UnitOfWork, executionRepo repository.ReminderExecutionRepository, reminderUsecase ReminderUsecase) DeliveryUsecase {
+	return &deliveryUsecase{
+		uow:             uow,
+		executionRepo:   executionRepo,
+		reminderUsecase: reminderUsecase,
+	}
+}
+
+// Claim takes the reminder's due occurrence: it records the dose as a
+// pending delivery held by the caller for DeliveryLease, counts the earlier
+// doses still left unanswered as missed and moves the reminder to its next
+// send time. When that dose ends the course, or the course was already over,
+// the reminder is turned off straight away.
+// It returns nil when the reminder is no longer due or another instance is
+// claiming it.
+func (u *deliveryUsecase) Claim(ctx context.Context, reminderID uuid.UUID, now time.Time) (*Delivery, error) {
+	var delivery *Delivery
+
+	err := u.uow.Transaction(ctx, func(tx *repository.Repository) error {
+		reminder, err := tx.Reminder.LockDue(ctx, reminderID, now)
+		if err != nil {
+			return fmt.Errorf("failed to lock reminder: %w", err)
+		}
+		if reminder == nil {
+			return nil
+		}
+
+		finished, err := isCourseFinished(ctx, tx.ReminderExecution, reminder, now)
+		if err != nil {
+			return err
+		}
+		if finished {
+			reminder.IsActive = false
+			reminder.NextSendAt = nil
+			if err := tx.Reminder.Update(ctx, reminder); err != nil {
+				return fmt.Errorf("failed to deactivate finished reminder: %w", err)
+			}
+			delivery = &Delivery{Reminder: reminder, FinishesCourse: true}
+			return nil
+		}
+
+		slot, err := u.reminderUsecase.ResolveSlot(ctx, reminder)
+		if err != nil {
+			return fmt.Errorf("failed to resolve slot: %w", err)
+		}
+		nextSendTime, err := u.reminderUsecase.NextSendTime(ctx, reminder)
+		if err != nil {
+			return fmt.Errorf("failed to calculate next send time: %w", err)
+		}
+
+		sentAt := time.Now()
+		if _, err := tx.ReminderExecution.MissUnansweredByReminderID(ctx, reminder.ID, sentAt); err != nil {
+			return fmt.Errorf("failed to record missed executions: %w", err)
+		}
+
+		leaseUntil := sentAt.Add(DeliveryLease)
+		execution := &entities.ReminderExecution{
+			ReminderID:       reminder.ID,
+			UserID:           reminder.UserID,
+			Status:           entities.ExecutionStatusSent,
+			Source:           entities.ExecutionSourceScheduled,
+			Slot:             slot,
+			SentAt:           sentAt,
+			DeliveryStatus:   entities.DeliveryStatusPending,
+			DeliveryAttempts: 1,
+			NextAttemptAt:    &leaseUntil,
+		}
+		if err := tx.ReminderExecution.Create(ctx, execution); err != nil {
+			return fmt.Errorf("failed to record sent execution: %w", err)
+		}
+
+		// The count now includes the dose just recorded, so the last dose
+		// of a course ends it without waiting for the next send time.
+		finishes := nextSendTime.IsZero()
+		if !finishes {
+			if finishes, err = isCourseFinished(ctx, tx.ReminderExecution, reminder, nextSendTime); err != nil {
+				return err
+			}
+		}
+
+		reminder.LastSentAt = &sentAt
+		if finishes {
+			reminder.IsActive = false
+			reminder.NextSendAt = nil
+		} else {
+			reminder.NextSendAt = &nextSendTime
+		}
+		if err := tx.Reminder.Update(ctx, reminder); err != nil {
+			return fmt.Errorf("failed to advance reminder: %w", err)
+		}
+
+		delivery = &Delivery{
+			Reminder:       reminder,
+			Execution:      execution,
+			FinishesCourse: finishes,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// GetDueRetries returns pending deliveries whose next attempt is due: failed
+// sends after their backoff and sends whose lease ran out.
+func (u *deliveryUsecase) GetDueRetries(ctx context.Context, now time.Time) ([]*entities.ReminderExecution, error) {
+	executions, err := u.executionRepo.GetDueDeliveries(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due deliveries: %w", err)
+	}
+	return executions, nil
+}
+
+// ClaimRetry takes a due retry for another attempt held for DeliveryLease.
+// It returns nil when the retry is no longer due, another instance is
+// claiming it or its reminder has been deleted, which gives it up.
+func (u *deliveryUsecase) ClaimRetry(ctx context.Context, executionID uuid.UUID, now time.Time) (*Delivery, error) {
+	var delivery *Delivery
+
+	err := u.uow.Transaction(ctx, func(tx *repository.Repository) error {
+		execution, err := tx.ReminderExecution.LockDueDelivery(ctx, executionID, now)
+		if err != nil {
+			return fmt.Errorf("failed to lock delivery: %w", err)
+		}
+		if execution == nil {
+			return nil
+		}
+
+		reminder, err := tx.Reminder.GetByID(ctx, execution.ReminderID)
+		if err != nil {
+			return fmt.Errorf("failed to get reminder: %w", err)
+		}
+		if reminder == nil {
+			if err := tx.ReminderExecution.MarkDeliveryFailed(ctx, execution.ID, nil, "reminder deleted"); err != nil {
+				return fmt.Errorf("failed to record failed delivery: %w", err)
+			}
+			return nil
+		}
+
+		leaseUntil := now.Add(DeliveryLease)
+		if err := tx.ReminderExecution.StartDeliveryAttempt(ctx, execution.ID, leaseUntil); err != nil {
+			return fmt.Errorf("failed to start delivery attempt: %w", err)
+		}
+		execution.DeliveryAttempts++
+		execution.NextAttemptAt = &leaseUntil
+
+		delivery = &Delivery{Reminder: reminder, Execution: execution}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// RecordFailed schedules the next attempt of a failed send after
+// deliveryBackoff, or gives the delivery up once MaxDeliveryAttempts were
+// made. It returns when the send is retried, nil when it is given up.
+func (u *deliveryUsecase) RecordFailed(ctx context.Context, execution *entities.ReminderExecution, sendErr error, now time.Time) (*time.Time, error) {
+	var retryAt *time.Time
+	if execution.DeliveryAttempts < MaxDeliveryAttempts {
+		next := now.Add(deliveryBackoff(execution.DeliveryAttempts))
+		retryAt = &next
+	}
+
+	if err := u.executionRepo.MarkDeliveryFailed(ctx, execution.ID, retryAt, sendErr.Error()); err != nil {
+		return nil, fmt.Errorf("failed to record failed delivery: %w", err)
+	}
+	return retryAt, nil
+}
+
+// deliveryBackoff returns how long to wait after the given number of failed
+// attempts: a minute after the first, doubling with every further one.
+func deliveryBackoff(attempts int) time.Duration {
+	return time.Minute << max(attempts-1, 0)
+}
@@ -0,0 +1,368 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository/mocks"
+)
+
+// fakeUnitOfWork runs transactions against the mocked repositories.
+type fakeUnitOfWork struct {
+	repo *repository.Repository
+}
+
+func (f *fakeUnitOfWork) Transaction(ctx context.Context, fn func(tx *repository.Repository) error) error {
+	return fn(f.repo)
+}
+
+type deliveryMocks struct {
+	userRepo      *mocks.MockUserRepository
+	reminderRepo  *mocks.MockReminderRepository
+	executionRepo *mocks.MockReminderExecutionRepository
+	stockRepo     *mocks.MockStockRepository
+}
+
+func newDeliveryUsecase(ctrl *gomock.Controller) (DeliveryUsecase, *deliveryMocks) {
+	m := &deliveryMocks{
+		userRepo:      mocks.NewMockUserRepository(ctrl),
+		reminderRepo:  mocks.NewMockReminderRepository(ctrl),
+		executionRepo: mocks.NewMockReminderExecutionRepository(ctrl),
+		stockRepo:     mocks.NewMockStockRepository(ctrl),
+	}
+	uow := &fakeUnitOfWork{repo: &repository.Repository{
+		User:              m.userRepo,
+		Reminder:          m.reminderRepo,
+		ReminderExecution: m.executionRepo,
+		Stock:             m.stockRepo,
+	}}
+	return NewDeliveryUsecase(uow, m.executionRepo, NewReminderUsecase(m.reminderRepo, m.userRepo)), m
+}
+
+func TestDeliveryUsecase_Claim(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("records a pending delivery and moves the reminder on", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		userID := uuid.New()
+		nextSendAt := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+		reminder := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &nextSendAt,
+		}
+
+		m.reminderRepo.EXPECT().LockDue(ctx, reminder.ID, now).Return(reminder, nil)
+		m.userRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil).Times(2)
+		m.executionRepo.EXPECT().MissUnansweredByReminderID(ctx, reminder.ID, gomock.Any()).Return(int64(1), nil)
+		m.executionRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+		m.reminderRepo.EXPECT().Update(ctx, reminder).Return(nil)
+
+		delivery, err := usecase.Claim(ctx, reminder.ID, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, delivery)
+		assert.False(t, delivery.FinishesCourse)
+
+		execution := delivery.Execution
+		require.NotNil(t, execution)
+		assert.Equal(t, entities.DeliveryStatusPending, execution.DeliveryStatus)
+		assert.Equal(t, 1, execution.DeliveryAttempts)
+		assert.WithinDuration(t, execution.SentAt.Add(DeliveryLease), *execution.NextAttemptAt, time.Second)
+		require.NotNil(t, execution.Slot)
+		assert.Equal(t, "09:00", *execution.Slot)
+
+		assert.True(t, reminder.IsActive)
+		assert.True(t, reminder.NextSendAt.After(now))
+		assert.Equal(t, execution.SentAt, *reminder.LastSentAt)
+	})
+
+	t.Run("another instance holds the reminder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		reminderID := uuid.New()
+
+		m.reminderRepo.EXPECT().LockDue(ctx, reminderID, now).Return(nil, nil)
+
+		delivery, err := usecase.Claim(ctx, reminderID, now)
+
+		require.NoError(t, err)
+		assert.Nil(t, delivery)
+	})
+
+	t.Run("turns off a reminder whose course is over", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		endsAt := now.Add(-time.Hour)
+		reminder := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     uuid.New(),
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &now,
+			EndsAt:     &endsAt,
+		}
+
+		m.reminderRepo.EXPECT().LockDue(ctx, reminder.ID, now).Return(reminder, nil)
+		m.reminderRepo.EXPECT().Update(ctx, reminder).Return(nil)
+
+		delivery, err := usecase.Claim(ctx, reminder.ID, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, delivery)
+		assert.True(t, delivery.FinishesCourse)
+		assert.Nil(t, delivery.Execution)
+		assert.False(t, reminder.IsActive)
+		assert.Nil(t, reminder.NextSendAt)
+	})
+
+	t.Run("turns the reminder off with the last dose of the course", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		userID := uuid.New()
+		maxDoses := 3
+		reminder := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &now,
+			MaxDoses:   &maxDoses,
+		}
+
+		m.reminderRepo.EXPECT().LockDue(ctx, reminder.ID, now).Return(reminder, nil)
+		m.userRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil).Times(2)
+		gomock.InOrder(
+			m.executionRepo.EXPECT().CountByReminderID(ctx, reminder.ID).Return(int64(2), nil),
+			m.executionRepo.EXPECT().MissUnansweredByReminderID(ctx, reminder.ID, gomock.Any()).Return(int64(0), nil),
+			m.executionRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil),
+			m.executionRepo.EXPECT().CountByReminderID(ctx, reminder.ID).Return(int64(3), nil),
+		)
+		m.reminderRepo.EXPECT().Update(ctx, reminder).Return(nil)
+
+		delivery, err := usecase.Claim(ctx, reminder.ID, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, delivery)
+		assert.NotNil(t, delivery.Execution)
+		assert.True(t, delivery.FinishesCourse)
+		assert.False(t, reminder.IsActive)
+		assert.Nil(t, reminder.NextSendAt)
+		assert.Equal(t, delivery.Execution.SentAt, *reminder.LastSentAt)
+	})
+
+	t.Run("keeps a course going while doses remain", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		userID := uuid.New()
+		maxDoses := 14
+		endsAt := now.AddDate(0, 0, 14)
+		reminder := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &now,
+			EndsAt:     &endsAt,
+			MaxDoses:   &maxDoses,
+		}
+
+		m.reminderRepo.EXPECT().LockDue(ctx, reminder.ID, now).Return(reminder, nil)
+		m.userRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil).Times(2)
+		gomock.InOrder(
+			m.executionRepo.EXPECT().CountByReminderID(ctx, reminder.ID).Return(int64(12), nil),
+			m.executionRepo.EXPECT().MissUnansweredByReminderID(ctx, reminder.ID, gomock.Any()).Return(int64(0), nil),
+			m.executionRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil),
+			m.executionRepo.EXPECT().CountByReminderID(ctx, reminder.ID).Return(int64(13), nil),
+		)
+		m.reminderRepo.EXPECT().Update(ctx, reminder).Return(nil)
+
+		delivery, err := usecase.Claim(ctx, reminder.ID, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, delivery)
+		assert.False(t, delivery.FinishesCourse)
+		assert.True(t, reminder.IsActive)
+		assert.True(t, reminder.NextSendAt.After(now))
+	})
+
+	t.Run("fails when the sent doses cannot be counted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		maxDoses := 14
+		reminder := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     uuid.New(),
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &now,
+			MaxDoses:   &maxDoses,
+		}
+
+		m.reminderRepo.EXPECT().LockDue(ctx, reminder.ID, now).Return(reminder, nil)
+		m.executionRepo.EXPECT().CountByReminderID(ctx, reminder.ID).Return(int64(0), errors.New("database error"))
+
+		delivery, err := usecase.Claim(ctx, reminder.ID, now)
+
+		assert.ErrorContains(t, err, "failed to count sent doses")
+		assert.Nil(t, delivery)
+	})
+
+	t.Run("fails without advancing when the dose is not recorded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		userID := uuid.New()
+		reminder := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     userID,
+			Type:       entities.ReminderTypeDaily,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			IsActive:   true,
+			NextSendAt: &now,
+		}
+
+		m.reminderRepo.EXPECT().LockDue(ctx, reminder.ID, now).Return(reminder, nil)
+		m.userRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil).Times(2)
+		m.executionRepo.EXPECT().MissUnansweredByReminderID(ctx, reminder.ID, gomock.Any()).Return(int64(0), nil)
+		m.executionRepo.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("database error"))
+
+		delivery, err := usecase.Claim(ctx, reminder.ID, now)
+
+		assert.ErrorContains(t, err, "failed to record sent execution")
+		assert.Nil(t, delivery)
+	})
+}
+
+func TestDeliveryUsecase_ClaimRetry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("starts another attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		reminder := &entities.Reminder{ID: uuid.New()}
+		execution := &entities.ReminderExecution{
+			ID:               uuid.New(),
+			ReminderID:       reminder.ID,
+			DeliveryStatus:   entities.DeliveryStatusPending,
+			DeliveryAttempts: 2,
+		}
+
+		m.executionRepo.EXPECT().LockDueDelivery(ctx, execution.ID, now).Return(execution, nil)
+		m.reminderRepo.EXPECT().GetByID(ctx, reminder.ID).Return(reminder, nil)
+		m.executionRepo.EXPECT().StartDeliveryAttempt(ctx, execution.ID, now.Add(DeliveryLease)).Return(nil)
+
+		delivery, err := usecase.ClaimRetry(ctx, execution.ID, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, delivery)
+		assert.Equal(t, reminder, delivery.Reminder)
+		assert.Equal(t, 3, delivery.Execution.DeliveryAttempts)
+		assert.Equal(t, now.Add(DeliveryLease), *delivery.Execution.NextAttemptAt)
+	})
+
+	t.Run("gives up the delivery of a deleted reminder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		execution := &entities.ReminderExecution{ID: uuid.New(), ReminderID: uuid.New()}
+
+		m.executionRepo.EXPECT().LockDueDelivery(ctx, execution.ID, now).Return(execution, nil)
+		m.reminderRepo.EXPECT().GetByID(ctx, execution.ReminderID).Return(nil, nil)
+		m.executionRepo.EXPECT().MarkDeliveryFailed(ctx, execution.ID, nil, "reminder deleted").Return(nil)
+
+		delivery, err := usecase.ClaimRetry(ctx, execution.ID, now)
+
+		require.NoError(t, err)
+		assert.Nil(t, delivery)
+	})
+}
+
+func TestDeliveryUsecase_RecordFailed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	sendErr := errors.New("Bad Gateway")
+
+	t.Run("retries after a backoff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		execution := &entities.ReminderExecution{ID: uuid.New(), DeliveryAttempts: 3}
+		retryAt := now.Add(4 * time.Minute)
+
+		m.executionRepo.EXPECT().MarkDeliveryFailed(ctx, execution.ID, &retryAt, "Bad Gateway").Return(nil)
+
+		next, err := usecase.RecordFailed(ctx, execution, sendErr, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, next)
+		assert.Equal(t, retryAt, *next)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDeliveryUsecase(ctrl)
+		execution := &entities.ReminderExecution{ID: uuid.New(), DeliveryAttempts: MaxDeliveryAttempts}
+
+		m.executionRepo.EXPECT().MarkDeliveryFailed(ctx, execution.ID, nil, "Bad Gateway").Return(nil)
+
+		next, err := usecase.RecordFailed(ctx, execution, sendErr, now)
+
+		require.NoError(t, err)
+		assert.Nil(t, next)
+	})
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, deliveryBackoff(tt.attempts), "attempts %d", tt.attempts)
+	}
+}
@@ -0,0 +1,232 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+)
+
+// DigestHorizon is how far ahead the weekly digest warns about courses that
+// end and medicines that run out.
+const DigestHorizon = 7 * 24 * time.Hour
+
+type DigestUsecase interface {
+	Enable(ctx context.Context, userID uuid.UUID, weekday time.Weekday, timeOfDay string) (*entities.User, error)
+	Disable(ctx context.Context, userID uuid.UUID) (*entities.User, error)
+	Reschedule(ctx context.Context, user *entities.User) error
+	GetDue(ctx context.Context) ([]*entities.User, error)
+	Build(ctx context.Context, user *entities.User, now time.Time) (*Digest, error)
+	Claim(ctx context.Context, user *entities.User, now time.Time) (bool, error)
+}
+
+// Digest summarises the user's past week against the week before it and
+// looks ahead DigestHorizon for courses that end and stock that runs low.
+type Digest struct {
+	From         time.Time
+	To           time.Time
+	Week         *repository.ExecutionStatistics
+	PreviousWeek *repository.ExecutionStatistics
+	Reminders    []*ReminderDigest
+	CourseEnds   []*entities.Reminder
+	LowStock     []*LowStock
+}
+
+// ReminderDigest is one reminder's share of the digest.
+type ReminderDigest struct {
+	Reminder     *entities.Reminder
+	Week         *repository.ExecutionStatistics
+	PreviousWeek *repository.ExecutionStatistics
+}
+
+type digestUsecase struct {
+	userRepo         repository.UserRepository
+	reminderUsecase  ReminderUsecase
+	executionUsecase ReminderExecutionUsecase
+	stockUsecase     StockUsecase
+}
+
+func NewDigestUsecase(userRepo repository.UserRepository, reminderUsecase ReminderUsecase, executionUsecase ReminderExecutionUsecase, stockUsecase StockUsecase) DigestUsecase {
+	return &digestUsecase{
+		userRepo:         userRepo,
+		reminderUsecase:  reminderUsecase,
+		executionUsecase: executionUsecase,
+		stockUsecase:     stockUsecase,
+	}
+}
+
+// Enable subscribes the user to the digest on the given weekday and time of
+// the user's local calendar.
+func (u *digestUsecase) Enable(ctx context.Context, userID uuid.UUID, weekday time.Weekday, timeOfDay string) (*entities.User, error) {
+	if weekday < time.Sunday || weekday > time.Saturday {
+		return nil, fmt.Errorf("invalid weekday")
+	}
+	parsed, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_of_day format, expected HH:MM")
+	}
+
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	day := int(weekday)
+	formatted := parsed.Format("15:04")
+	next := nextDigestTime(weekday, formatted, time.Now().In(user.Location()))
+	user.DigestWeekday = &day
+	user.DigestTime = &formatted
+	user.DigestNextAt = &next
+
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return user, nil
+}
+
+func (u *digestUsecase) Disable(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.DigestWeekday = nil
+	user.DigestTime = nil
+	user.DigestNextAt = nil
+
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return user, nil
+}
+
+// Reschedule recalculates when the digest is next sent, e.g. after the user
+// changed time zone.
+func (u *digestUsecase) Reschedule(ctx context.Context, user *entities.User) error {
+	if !user.HasDigest() {
+		return nil
+	}
+	next := nextDigestTime(time.Weekday(*user.DigestWeekday), *user.DigestTime, time.Now().In(user.Location()))
+	if err := u.userRepo.UpdateDigestNextAt(ctx, user.ID, next); err != nil {
+		return fmt.Errorf("failed to update digest time: %w", err)
+	}
+	user.DigestNextAt = &next
+	return nil
+}
+
+func (u *digestUsecase) GetDue(ctx context.Context) ([]*entities.User, error) {
+	users, err := u.userRepo.GetDueDigests(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due digests: %w", err)
+	}
+	return users, nil
+}
+
+// Build collects the digest for the seven days before now.
+func (u *digestUsecase) Build(ctx context.Context, user *entities.User, now time.Time) (*Digest, error) {
+	now = now.In(user.Location())
+	digest := &Digest{From: now.AddDate(0, 0, -7), To: now}
+	previousFrom := digest.From.AddDate(0, 0, -7)
+	previousTo := digest.From.Add(-time.Microsecond)
+
+	var err error
+	if digest.Week, err = u.executionUsecase.GetStatisticsByUserID(ctx, user.ID, digest.From, digest.To); err != nil {
+		return nil, err
+	}
+	if digest.PreviousWeek, err = u.executionUsecase.GetStatisticsByUserID(ctx, user.ID, previousFrom, previousTo); err != nil {
+		return nil, err
+	}
+
+	reminders, err := u.reminderUsecase.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	remindersByID := make(map[uuid.UUID]*entities.Reminder, len(reminders))
+	for _, reminder := range reminders {
+		remindersByID[reminder.ID] = reminder
+
+		week, err := u.executionUsecase.GetStatisticsByReminderID(ctx, reminder.ID, digest.From, digest.To)
+		if err != nil {
+			return nil, err
+		}
+		previous, err := u.executionUsecase.GetStatisticsByReminderID(ctx, reminder.ID, previousFrom, previousTo)
+		if err != nil {
+			return nil, err
+		}
+		if week.TotalDelivered > 0 || previous.TotalDelivered > 0 {
+			digest.Reminders = append(digest.Reminders, &ReminderDigest{Reminder: reminder, Week: week, PreviousWeek: previous})
+		}
+
+		if reminder.IsActive && reminder.EndsAt != nil && reminder.EndsAt.After(now) && reminder.EndsAt.Before(now.Add(DigestHorizon)) {
+			digest.CourseEnds = append(digest.CourseEnds, reminder)
+		}
+	}
+	sort.Slice(digest.CourseEnds, func(i, j int) bool {
+		return digest.CourseEnds[i].EndsAt.Before(*digest.CourseEnds[j].EndsAt)
+	})
+
+	stocks, err := u.stockUsecase.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	horizonDays := DigestHorizon.Hours() / 24
+	for _, stock := range stocks {
+		reminder := remindersByID[stock.ReminderID]
+		if reminder == nil || !reminder.IsActive {
+			continue
+		}
+		daysLeft, runsOut, err := u.stockUsecase.DaysLeft(ctx, reminder, stock)
+		if err != nil {
+			return nil, err
+		}
+		if runsOut && (daysLeft < horizonDays || daysLeft < float64(stock.RefillThresholdDays)) {
+			digest.LowStock = append(digest.LowStock, &LowStock{Stock: stock, Reminder: reminder, DaysLeft: daysLeft})
+		}
+	}
+
+	return digest, nil
+}
+
+// Claim schedules a due digest for the same weekday and time next week
+// before it is sent. It reports false when the digest is no longer due, e.g.
+// because another instance is sending it.
+func (u *digestUsecase) Claim(ctx context.Context, user *entities.User, now time.Time) (bool, error) {
+	if !user.HasDigest() {
+		return false, nil
+	}
+	next := nextDigestTime(time.Weekday(*user.DigestWeekday), *user.DigestTime, now.In(user.Location()))
+	claimed, err := u.userRepo.ClaimDigest(ctx, user.ID, now, next)
+	if err != nil {
+		return false, fmt.Errorf("failed to update digest time: %w", err)
+	}
+	return claimed, nil
+}
+
+func (u *digestUsecase) getUser(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+// nextDigestTime returns the first weekday at timeOfDay strictly after
+// after, in its location.
+func nextDigestTime(weekday time.Weekday, timeOfDay string, after time.Time) time.Time {
+	parsed, _ := time.Parse("15:04", timeOfDay)
+	for days := 0; ; days++ {
+		candidate := time.Date(after.Year(), after.Month(), after.Day()+days, parsed.Hour(), parsed.Minute(), 0, 0, after.Location())
+		if candidate.Weekday() == weekday && candidate.After(after) {
+			return candidate
+		}
+	}
+}
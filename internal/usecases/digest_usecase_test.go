@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository/mocks"
+)
+
+type digestMocks struct {
+	userRepo      *mocks.MockUserRepository
+	reminderRepo  *mocks.MockReminderRepository
+	executionRepo *mocks.MockReminderExecutionRepository
+	stockRepo     *mocks.MockStockRepository
+}
+
+func newDigestUsecase(ctrl *gomock.Controller) (DigestUsecase, *digestMocks) {
+	m := &digestMocks{
+		userRepo:      mocks.NewMockUserRepository(ctrl),
+		reminderRepo:  mocks.NewMockReminderRepository(ctrl),
+		executionRepo: mocks.NewMockReminderExecutionRepository(ctrl),
+		stockRepo:     mocks.NewMockStockRepository(ctrl),
+	}
+	reminder := NewReminderUsecase(m.reminderRepo, m.userRepo)
+	execution := NewReminderExecutionUsecase(m.executionRepo, m.stockRepo)
+	stock := NewStockUsecase(m.stockRepo, reminder)
+	return NewDigestUsecase(m.userRepo, reminder, execution, stock), m
+}
+
+func TestDigestUsecase_Enable(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("schedules the next digest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDigestUsecase(ctrl)
+		userID := uuid.New()
+
+		m.userRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		m.userRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		user, err := usecase.Enable(ctx, userID, time.Sunday, "9:05")
+
+		require.NoError(t, err)
+		assert.True(t, user.HasDigest())
+		assert.Equal(t, "09:05", *user.DigestTime)
+		assert.Equal(t, time.Sunday, user.DigestNextAt.In(user.Location()).Weekday())
+		assert.True(t, user.DigestNextAt.After(time.Now()))
+		assert.True(t, user.DigestNextAt.Before(time.Now().AddDate(0, 0, 7)))
+	})
+
+	t.Run("error on invalid time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, _ := newDigestUsecase(ctrl)
+
+		_, err := usecase.Enable(ctx, uuid.New(), time.Sunday, "25:00")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("disable clears the schedule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase, m := newDigestUsecase(ctrl)
+		userID := uuid.New()
+		weekday, at, next := 0, "20:00", time.Now()
+
+		m.userRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID, DigestWeekday: &weekday, DigestTime: &at, DigestNextAt: &next}, nil)
+		m.userRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		user, err := usecase.Disable(ctx, userID)
+
+		require.NoError(t, err)
+		assert.False(t, user.HasDigest())
+		assert.Nil(t, user.DigestNextAt)
+	})
+}
+
+func TestDigestUsecase_Claim(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newDigestUsecase(ctrl)
+
+	zone := "Europe/Moscow"
+	weekday, at := int(time.Sunday), "20:00"
+	user := &entities.User{ID: uuid.New(), Timezone: &zone, DigestWeekday: &weekday, DigestTime: &at}
+	loc := user.Location()
+	sentAt := time.Date(2026, 5, 10, 20, 0, 30, 0, loc)
+	next := time.Date(2026, 5, 17, 20, 0, 0, 0, loc)
+
+	t.Run("schedules next week", func(t *testing.T) {
+		m.userRepo.EXPECT().ClaimDigest(ctx, user.ID, sentAt, next).Return(true, nil)
+
+		claimed, err := usecase.Claim(ctx, user, sentAt)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+	})
+
+	t.Run("claimed by another instance", func(t *testing.T) {
+		m.userRepo.EXPECT().ClaimDigest(ctx, user.ID, sentAt, next).Return(false, nil)
+
+		claimed, err := usecase.Claim(ctx, user, sentAt)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+}
+
+func TestNextDigestTime(t *testing.T) {
+	// 2026-05-06 is a Wednesday.
+	wednesday := time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		weekday  time.Weekday
+		at       string
+		after    time.Time
+		expected time.Time
+	}{
+		{"later this week", time.Friday, "09:00", wednesday, time.Date(2026, 5, 8, 9, 0, 0, 0, time.UTC)},
+		{"later today", time.Wednesday, "18:30", wednesday, time.Date(2026, 5, 6, 18, 30, 0, 0, time.UTC)},
+		{"earlier today means next week", time.Wednesday, "08:00", wednesday, time.Date(2026, 5, 13, 8, 0, 0, 0, time.UTC)},
+		{"exactly now means next week", time.Wednesday, "12:00", wednesday, time.Date(2026, 5, 13, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, nextDigestTime(tt.weekday, tt.at, tt.after))
+		})
+	}
+}
+
+func TestDigestUsecase_Build(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usecase, m := newDigestUsecase(ctrl)
+
+	user := &entities.User{ID: uuid.New()}
+	now := time.Now()
+	endsSoon := now.Add(3 * 24 * time.Hour)
+	endsLater := now.Add(30 * 24 * time.Hour)
+
+	taken := &entities.Reminder{ID: uuid.New(), UserID: user.ID, Title: "Aspirin", Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{"09:00"}, IsActive: true, EndsAt: &endsLater}
+	course := &entities.Reminder{ID: uuid.New(), UserID: user.ID, Title: "Antibiotic", Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{"09:00", "21:00"}, IsActive: true, EndsAt: &endsSoon}
+	idle := &entities.Reminder{ID: uuid.New(), UserID: user.ID, Title: "Vitamin D", Type: entities.ReminderTypeDaily, IsActive: false}
+
+	week := &repository.ExecutionStatistics{TotalDelivered: 14, TotalConfirmed: 12, AdherenceRate: 85.7}
+	previous := &repository.ExecutionStatistics{TotalDelivered: 14, TotalConfirmed: 10, AdherenceRate: 71.4}
+	empty := &repository.ExecutionStatistics{}
+
+	m.executionRepo.EXPECT().GetStatisticsByUserID(ctx, user.ID, gomock.Any(), gomock.Any()).Return(week, nil)
+	m.executionRepo.EXPECT().GetStatisticsByUserID(ctx, user.ID, gomock.Any(), gomock.Any()).Return(previous, nil)
+	m.reminderRepo.EXPECT().GetByUserID(ctx, user.ID).Return([]*entities.Reminder{taken, course, idle}, nil)
+	m.executionRepo.EXPECT().GetStatisticsByReminderID(ctx, taken.ID, gomock.Any(), gomock.Any()).Return(week, nil)
+	m.executionRepo.EXPECT().GetStatisticsByReminderID(ctx, taken.ID, gomock.Any(), gomock.Any()).Return(previous, nil)
+	m.executionRepo.EXPECT().GetStatisticsByReminderID(ctx, course.ID, gomock.Any(), gomock.Any()).Return(empty, nil).Times(2)
+	m.executionRepo.EXPECT().GetStatisticsByReminderID(ctx, idle.ID, gomock.Any(), gomock.Any()).Return(empty, nil).Times(2)
+	m.stockRepo.EXPECT().GetByUserID(ctx, user.ID).Return([]*entities.Stock{
+		{ID: uuid.New(), ReminderID: taken.ID, OnHand: 3, PerDose: 1, RefillThresholdDays: 2},
+		{ID: uuid.New(), ReminderID: idle.ID, OnHand: 1, PerDose: 1},
+	}, nil)
+	m.userRepo.EXPECT().GetByID(ctx, user.ID).Return(user, nil).AnyTimes()
+
+	digest, err := usecase.Build(ctx, user, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, week, digest.Week)
+	assert.Equal(t, previous, digest.PreviousWeek)
+	assert.Equal(t, now.AddDate(0, 0, -7), digest.From)
+
+	require.Len(t, digest.Reminders, 1)
+	assert.Equal(t, taken, digest.Reminders[0].Reminder)
+	assert.Equal(t, previous, digest.Reminders[0].PreviousWeek)
+
+	require.Len(t, digest.CourseEnds, 1)
+	assert.Equal(t, course, digest.CourseEnds[0])
+
+	require.Len(t, digest.LowStock, 1)
+	assert.Equal(t, taken, digest.LowStock[0].Reminder)
+	assert.InDelta(t, 3, digest.LowStock[0].DaysLeft, 0.5)
+}
@@ -0,0 +1,16 @@
+package usecases
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden is returned when a user acts on another user's data.
+	ErrForbidden = errors.New("access denied")
+	// ErrExecutionFinalized is returned when a dose that has already been
+	// answered or marked missed is changed again.
+	ErrExecutionFinalized = errors.New("execution is already finalized")
+	// ErrInvalidTakenAt is returned when a dose is logged in the future or
+	// further back than MaxBackdate.
+	ErrInvalidTakenAt = errors.New("invalid intake time")
+)
@@ -3,6 +3,7 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,52 +13,421 @@ import (
 )
 
 type ReminderExecutionUsecase interface {
-	RecordSent(ctx context.Context, reminderID, userID uuid.UUID) (*entities.ReminderExecution, error)
-	RecordConfirmed(ctx context.Context, executionID uuid.UUID) error
-	RecordSkipped(ctx context.Context, executionID uuid.UUID) error
+	RecordConfirmed(ctx context.Context, userID, executionID uuid.UUID, takenAt time.Time) (bool, error)
+	LogDose(ctx context.Context, userID uuid.UUID, reminder *entities.Reminder, takenAt time.Time) (*entities.ReminderExecution, error)
+	CheckDoseLimits(ctx context.Context, reminder *entities.Reminder, takenAt time.Time) (*DoseLimits, error)
+	RecordSkipped(ctx context.Context, userID, executionID uuid.UUID) (bool, error)
+	Snooze(ctx context.Context, userID, executionID uuid.UUID, duration time.Duration) (time.Time, error)
+	GetDueSnoozed(ctx context.Context) ([]*entities.ReminderExecution, error)
+	ClaimSnoozed(ctx context.Context, executionID uuid.UUID) (bool, error)
+	RecordDelivered(ctx context.Context, executionID uuid.UUID, messageID int) error
+	ClaimNag(ctx context.Context, execution *entities.ReminderExecution) (bool, error)
+	ReleaseNag(ctx context.Context, execution *entities.ReminderExecution) error
+	RecordNagged(ctx context.Context, executionID uuid.UUID, messageID int) error
+	RecordMissed(ctx context.Context, executionID uuid.UUID) (bool, error)
+	ExpireUnanswered(ctx context.Context, now time.Time) (int64, error)
+	GetDueNags(ctx context.Context) ([]*entities.ReminderExecution, error)
+	GetDueEscalations(ctx context.Context) ([]*entities.ReminderExecution, error)
+	ClaimEscalation(ctx context.Context, executionID uuid.UUID) (bool, error)
 	GetHistoryByReminderID(ctx context.Context, reminderID uuid.UUID, limit int) ([]*entities.ReminderExecution, error)
 	GetHistoryByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.ReminderExecution, error)
 	GetStatisticsByUserID(ctx context.Context, userID uuid.UUID, fromDate, toDate time.Time) (*repository.ExecutionStatistics, error)
 	GetStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (*repository.ExecutionStatistics, error)
+	GetSlotStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) ([]*repository.SlotStatistics, error)
+	CountAsNeededIntakes(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (int, error)
+	GetDailyAdherence(ctx context.Context, userID uuid.UUID, fromDate, toDate time.Time) ([]*DailyAdherence, error)
+	GetStreaks(ctx context.Context, userID uuid.UUID, now time.Time) (*Streaks, error)
+	GetCourseSummary(ctx context.Context, reminder *entities.Reminder) (*CourseSummary, error)
+}
+
+// CourseSummary describes how a finished course of treatment went.
+type CourseSummary struct {
+	TotalDoses    int
+	Confirmed     int
+	Skipped       int
+	Missed        int
+	Unanswered    int
+	AdherenceRate float64
+}
+
+// DailyAdherence counts the doses sent on one calendar day by how they were
+// answered. Pending doses are still waiting for an answer.
+type DailyAdherence struct {
+	Date      time.Time
+	Confirmed int
+	Skipped   int
+	Missed    int
+	Pending   int
+}
+
+func (d *DailyAdherence) Total() int {
+	return d.Confirmed + d.Skipped + d.Missed + d.Pending
+}
+
+// Rate returns the share of the day's doses that were taken, in percent.
+func (d *DailyAdherence) Rate() float64 {
+	if d.Total() == 0 {
+		return 0
+	}
+	return float64(d.Confirmed) / float64(d.Total()) * 100
+}
+
+// Streaks are runs of consecutive days on which every dose was taken, in
+// days. Days without doses neither extend nor break a streak.
+type Streaks struct {
+	Current int
+	Longest int
+}
+
+// StreakLookbackDays bounds how far back streaks are counted.
+const StreakLookbackDays = 365
+
+// DoseLimits tells how a dose of an as-needed medicine taken at some time
+// fits the reminder's minimum interval and daily maximum.
+type DoseLimits struct {
+	LastTakenAt   *time.Time
+	TakenInDay    int
+	TooSoon       bool
+	OverDailyMax  bool
+	NextAllowedAt time.Time
+}
+
+// Exceeded reports whether the dose breaks any of the limits.
+func (l *DoseLimits) Exceeded() bool {
+	return l.TooSoon || l.OverDailyMax
 }
 
 type reminderExecutionUsecase struct {
-	repo repository.ReminderExecutionRepository
+	repo      repository.ReminderExecutionRepository
+	stockRepo repository.StockRepository
 }
 
-func NewReminderExecutionUsecase(repo repository.ReminderExecutionRepository) ReminderExecutionUsecase {
-	return &reminderExecutionUsecase{repo: repo}
+func NewReminderExecutionUsecase(repo repository.ReminderExecutionRepository, stockRepo repository.StockRepository) ReminderExecutionUsecase {
+	return &reminderExecutionUsecase{
+		repo:      repo,
+		stockRepo: stockRepo,
+	}
+}
+
+// MaxBackdate bounds how far back a dose may be logged.
+const MaxBackdate = 7 * 24 * time.Hour
+
+// LogDoseWindow is how far from a reminder a logged dose may be taken to
+// still answer it.
+const LogDoseWindow = 3 * time.Hour
+
+// RecordConfirmed marks the user's dose as taken at takenAt and takes it out
+// of the reminder's stock. It reports false when the dose was already
+// confirmed, so pressing the button twice changes nothing; a skipped or
+// missed dose can no longer be confirmed.
+func (u *reminderExecutionUsecase) RecordConfirmed(ctx context.Context, userID, executionID uuid.UUID, takenAt time.Time) (bool, error) {
+	if err := validateTakenAt(takenAt, time.Now()); err != nil {
+		return false, err
+	}
+
+	execution, err := u.ownedExecution(ctx, userID, executionID)
+	if err != nil {
+		return false, err
+	}
+	if execution.Status == entities.ExecutionStatusConfirmed {
+		return false, nil
+	}
+	if execution.IsFinal() {
+		return false, ErrExecutionFinalized
+	}
+
+	changed, err := u.repo.Confirm(ctx, executionID, takenAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record confirmed execution: %w", err)
+	}
+	if !changed {
+		return false, nil
+	}
+	if err := u.stockRepo.Consume(ctx, execution.ReminderID); err != nil {
+		return true, fmt.Errorf("failed to update stock: %w", err)
+	}
+	return true, nil
 }
 
-func (u *reminderExecutionUsecase) RecordSent(ctx context.Context, reminderID, userID uuid.UUID) (*entities.ReminderExecution, error) {
-	execution := &entities.ReminderExecution{
-		ReminderID: reminderID,
-		UserID:     userID,
-		Status:     entities.ExecutionStatusSent,
-		SentAt:     time.Now(),
+// LogDose records a dose of the reminder the user took at takenAt. The
+// unanswered reminder sent closest to takenAt within LogDoseWindow is
+// confirmed; without one, and always for as-needed medicines, the dose is
+// stored as a new confirmed execution marked as logged or as an as-needed
+// intake, neither of which counts as a scheduled dose. Dose limits are
+// checked separately by CheckDoseLimits.
+func (u *reminderExecutionUsecase) LogDose(ctx context.Context, userID uuid.UUID, reminder *entities.Reminder, takenAt time.Time) (*entities.ReminderExecution, error) {
+	if reminder.UserID != userID {
+		return nil, ErrForbidden
+	}
+	now := time.Now()
+	if err := validateTakenAt(takenAt, now); err != nil {
+		return nil, err
 	}
 
-	if err := u.repo.Create(ctx, execution); err != nil {
-		return nil, fmt.Errorf("failed to record sent execution: %w", err)
+	var execution *entities.ReminderExecution
+	var err error
+	if !reminder.IsAsNeeded() {
+		execution, err = u.repo.GetUnansweredNear(ctx, reminder.ID, takenAt, LogDoseWindow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get unanswered execution: %w", err)
+		}
 	}
 
+	changed := false
+	if execution != nil {
+		if changed, err = u.repo.Confirm(ctx, execution.ID, takenAt); err != nil {
+			return nil, fmt.Errorf("failed to record confirmed execution: %w", err)
+		}
+	}
+	if changed {
+		execution.Status = entities.ExecutionStatusConfirmed
+		execution.ConfirmedAt = &now
+		execution.TakenAt = &takenAt
+	} else {
+		source := entities.ExecutionSourceLogged
+		if reminder.IsAsNeeded() {
+			source = entities.ExecutionSourceAsNeeded
+		}
+		execution = &entities.ReminderExecution{
+			ReminderID:  reminder.ID,
+			UserID:      userID,
+			Status:      entities.ExecutionStatusConfirmed,
+			Source:      source,
+			SentAt:      takenAt,
+			ConfirmedAt: &now,
+			TakenAt:     &takenAt,
+		}
+		if err := u.repo.Create(ctx, execution); err != nil {
+			return nil, fmt.Errorf("failed to record logged dose: %w", err)
+		}
+	}
+
+	if err := u.stockRepo.Consume(ctx, reminder.ID); err != nil {
+		return execution, fmt.Errorf("failed to update stock: %w", err)
+	}
 	return execution, nil
 }
 
-func (u *reminderExecutionUsecase) RecordConfirmed(ctx context.Context, executionID uuid.UUID) error {
-	if err := u.repo.UpdateStatus(ctx, executionID, entities.ExecutionStatusConfirmed); err != nil {
-		return fmt.Errorf("failed to record confirmed execution: %w", err)
+// CheckDoseLimits reports whether one more dose taken at takenAt would come
+// sooner than the reminder's minimum interval after the previous one or go
+// over its maximum per 24 hours, and when the dose would be allowed.
+func (u *reminderExecutionUsecase) CheckDoseLimits(ctx context.Context, reminder *entities.Reminder, takenAt time.Time) (*DoseLimits, error) {
+	doses, err := u.repo.GetTakenBetween(ctx, reminder.ID, takenAt.Add(-24*time.Hour), takenAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get taken doses: %w", err)
+	}
+
+	limits := &DoseLimits{TakenInDay: len(doses)}
+	if len(doses) > 0 {
+		last := doses[0].IntakeTime()
+		limits.LastTakenAt = &last
+	}
+
+	if reminder.MinIntervalMinutes != nil && limits.LastTakenAt != nil {
+		allowedAt := limits.LastTakenAt.Add(time.Duration(*reminder.MinIntervalMinutes) * time.Minute)
+		if takenAt.Before(allowedAt) {
+			limits.TooSoon = true
+			limits.NextAllowedAt = allowedAt
+		}
+	}
+	if maxDoses := reminder.MaxDosesPerDay; maxDoses != nil && len(doses) >= *maxDoses {
+		limits.OverDailyMax = true
+		// The dose is allowed once the maxDoses-th latest one leaves the 24 hours.
+		allowedAt := doses[*maxDoses-1].IntakeTime().Add(24 * time.Hour)
+		if allowedAt.After(limits.NextAllowedAt) {
+			limits.NextAllowedAt = allowedAt
+		}
+	}
+
+	return limits, nil
+}
+
+func validateTakenAt(takenAt, now time.Time) error {
+	if takenAt.After(now.Add(time.Minute)) || takenAt.Before(now.Add(-MaxBackdate)) {
+		return ErrInvalidTakenAt
 	}
 	return nil
 }
 
-func (u *reminderExecutionUsecase) RecordSkipped(ctx context.Context, executionID uuid.UUID) error {
-	if err := u.repo.UpdateStatus(ctx, executionID, entities.ExecutionStatusSkipped); err != nil {
-		return fmt.Errorf("failed to record skipped execution: %w", err)
+// RecordSkipped marks the user's dose as skipped and reports whether it
+// changed anything.
+func (u *reminderExecutionUsecase) RecordSkipped(ctx context.Context, userID, executionID uuid.UUID) (bool, error) {
+	execution, err := u.ownedExecution(ctx, userID, executionID)
+	if err != nil {
+		return false, err
+	}
+	if execution.Status == entities.ExecutionStatusSkipped {
+		return false, nil
+	}
+	if execution.IsFinal() {
+		return false, ErrExecutionFinalized
+	}
+
+	changed, err := u.repo.UpdateStatus(ctx, executionID, entities.ExecutionStatusSkipped)
+	if err != nil {
+		return false, fmt.Errorf("failed to record skipped execution: %w", err)
+	}
+	return changed, nil
+}
+
+// MinSnoozeDuration and MaxSnoozeDuration bound how far a single snooze may
+// postpone a dose.
+const (
+	MinSnoozeDuration = time.Minute
+	MaxSnoozeDuration = 12 * time.Hour
+)
+
+// Snooze postpones the execution by duration. The execution is delivered
+// again at the returned time; the reminder's own schedule is not affected.
+func (u *reminderExecutionUsecase) Snooze(ctx context.Context, userID, executionID uuid.UUID, duration time.Duration) (time.Time, error) {
+	if duration < MinSnoozeDuration || duration > MaxSnoozeDuration {
+		return time.Time{}, fmt.Errorf("snooze duration must be between %s and %s", MinSnoozeDuration, MaxSnoozeDuration)
+	}
+
+	execution, err := u.ownedExecution(ctx, userID, executionID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if execution.IsFinal() {
+		return time.Time{}, ErrExecutionFinalized
+	}
+
+	until := time.Now().Add(duration)
+	if err := u.repo.Snooze(ctx, executionID, until); err != nil {
+		return time.Time{}, fmt.Errorf("failed to snooze execution: %w", err)
+	}
+	return until, nil
+}
+
+// ownedExecution loads an execution the user is about to change and makes
+// sure it is theirs.
+func (u *reminderExecutionUsecase) ownedExecution(ctx context.Context, userID, executionID uuid.UUID) (*entities.ReminderExecution, error) {
+	execution, err := u.repo.GetByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution: %w", err)
+	}
+	if execution == nil {
+		return nil, ErrNotFound
+	}
+	if execution.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return execution, nil
+}
+
+func (u *reminderExecutionUsecase) GetDueSnoozed(ctx context.Context) ([]*entities.ReminderExecution, error) {
+	executions, err := u.repo.GetDueSnoozed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snoozed executions: %w", err)
+	}
+	return executions, nil
+}
+
+// ClaimSnoozed takes a snoozed execution whose snooze is over for
+// re-delivery, held for DeliveryLease. It reports false when the execution
+// is no longer due or another instance has taken it.
+func (u *reminderExecutionUsecase) ClaimSnoozed(ctx context.Context, executionID uuid.UUID) (bool, error) {
+	now := time.Now()
+	claimed, err := u.repo.ClaimSnoozed(ctx, executionID, now, now.Add(DeliveryLease))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim snoozed execution: %w", err)
+	}
+	return claimed, nil
+}
+
+// RecordDelivered stores the Telegram message the execution was delivered
+// in. A snoozed execution becomes sent again.
+func (u *reminderExecutionUsecase) RecordDelivered(ctx context.Context, executionID uuid.UUID, messageID int) error {
+	if err := u.repo.MarkDelivered(ctx, executionID, messageID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record delivered execution: %w", err)
 	}
 	return nil
 }
 
+// ClaimNag counts the next repeat of an unanswered execution before it is
+// sent. It reports false when the execution has been answered or another
+// instance has repeated it meanwhile.
+func (u *reminderExecutionUsecase) ClaimNag(ctx context.Context, execution *entities.ReminderExecution) (bool, error) {
+	claimed, err := u.repo.ClaimNag(ctx, execution.ID, execution.NagCount, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim repeated reminder: %w", err)
+	}
+	return claimed, nil
+}
+
+// ReleaseNag takes back the repeat claimed by ClaimNag when it could not be
+// sent, so that it is sent again on a later run instead of being counted as
+// sent.
+func (u *reminderExecutionUsecase) ReleaseNag(ctx context.Context, execution *entities.ReminderExecution) error {
+	if _, err := u.repo.ReleaseNag(ctx, execution.ID, execution.NagCount, execution.NaggedAt); err != nil {
+		return fmt.Errorf("failed to release repeated reminder: %w", err)
+	}
+	return nil
+}
+
+func (u *reminderExecutionUsecase) RecordNagged(ctx context.Context, executionID uuid.UUID, messageID int) error {
+	if err := u.repo.RecordNag(ctx, executionID, messageID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record repeated reminder: %w", err)
+	}
+	return nil
+}
+
+// RecordMissed marks an unanswered execution as missed and reports whether
+// it did.
+func (u *reminderExecutionUsecase) RecordMissed(ctx context.Context, executionID uuid.UUID) (bool, error) {
+	missed, err := u.repo.UpdateStatus(ctx, executionID, entities.ExecutionStatusMissed)
+	if err != nil {
+		return false, fmt.Errorf("failed to record missed execution: %w", err)
+	}
+	return missed, nil
+}
+
+// UnansweredTimeout is how long a delivered dose waits for an answer before
+// it is counted as missed. A dose is also missed as soon as the next one of
+// its reminder is sent.
+const UnansweredTimeout = 24 * time.Hour
+
+// ExpireUnanswered marks doses left unanswered for UnansweredTimeout as
+// missed and returns how many there were.
+func (u *reminderExecutionUsecase) ExpireUnanswered(ctx context.Context, now time.Time) (int64, error) {
+	count, err := u.repo.MissUnanswered(ctx, now.Add(-UnansweredTimeout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record missed executions: %w", err)
+	}
+	return count, nil
+}
+
+// GetDueNags returns unanswered executions that should be repeated now or,
+// once their repeats are used up, marked as missed.
+func (u *reminderExecutionUsecase) GetDueNags(ctx context.Context) ([]*entities.ReminderExecution, error) {
+	executions, err := u.repo.GetDueNags(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unanswered executions: %w", err)
+	}
+	return executions, nil
+}
+
+// GetDueEscalations returns doses that stayed unconfirmed past the patient's
+// caregiver delay and whose caregivers have not been told yet.
+func (u *reminderExecutionUsecase) GetDueEscalations(ctx context.Context) ([]*entities.ReminderExecution, error) {
+	executions, err := u.repo.GetDueEscalations(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executions to escalate: %w", err)
+	}
+	return executions, nil
+}
+
+// ClaimEscalation records that the caregivers are being told about the
+// execution. It reports false when it has been escalated already, so each
+// dose is escalated by one instance only.
+func (u *reminderExecutionUsecase) ClaimEscalation(ctx context.Context, executionID uuid.UUID) (bool, error) {
+	claimed, err := u.repo.MarkEscalated(ctx, executionID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to record escalated execution: %w", err)
+	}
+	return claimed, nil
+}
+
 func (u *reminderExecutionUsecase) GetHistoryByReminderID(ctx context.Context, reminderID uuid.UUID, limit int) ([]*entities.ReminderExecution, error) {
 	if limit <= 0 {
 		limit = 50
@@ -95,3 +465,141 @@ func (u *reminderExecutionUsecase) GetStatisticsByReminderID(ctx context.Context
 	}
 	return stats, nil
 }
+
+func (u *reminderExecutionUsecase) GetSlotStatisticsByReminderID(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) ([]*repository.SlotStatistics, error) {
+	stats, err := u.repo.GetSlotStatisticsByReminderID(ctx, reminderID, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slot statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// CountAsNeededIntakes counts the intakes of an as-needed medicine taken
+// between fromDate and toDate. They are kept out of the adherence
+// statistics, which are about scheduled doses.
+func (u *reminderExecutionUsecase) CountAsNeededIntakes(ctx context.Context, reminderID uuid.UUID, fromDate, toDate time.Time) (int, error) {
+	count, err := u.repo.CountAsNeededByReminderID(ctx, reminderID, fromDate, toDate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count as-needed intakes: %w", err)
+	}
+	return int(count), nil
+}
+
+// GetDailyAdherence returns one entry per calendar day from fromDate to
+// toDate, both inclusive, in the location of fromDate.
+func (u *reminderExecutionUsecase) GetDailyAdherence(ctx context.Context, userID uuid.UUID, fromDate, toDate time.Time) ([]*DailyAdherence, error) {
+	loc := fromDate.Location()
+	first := dayStart(fromDate)
+	toDate = toDate.In(loc)
+
+	executions, err := u.repo.GetSentBetween(ctx, userID, first, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executions: %w", err)
+	}
+
+	var days []*DailyAdherence
+	for day := first; !day.After(toDate); day = day.AddDate(0, 0, 1) {
+		days = append(days, &DailyAdherence{Date: day})
+	}
+
+	for _, execution := range executions {
+		sent := execution.SentAt.In(loc)
+		i := daysBetween(first, dayStart(sent))
+		if i < 0 || i >= len(days) {
+			continue
+		}
+		switch execution.Status {
+		case entities.ExecutionStatusConfirmed:
+			days[i].Confirmed++
+		case entities.ExecutionStatusSkipped:
+			days[i].Skipped++
+		case entities.ExecutionStatusMissed:
+			days[i].Missed++
+		default:
+			days[i].Pending++
+		}
+	}
+
+	return days, nil
+}
+
+// GetStreaks returns the user's current and longest streaks over the last
+// StreakLookbackDays days, counted in the location of now. A dose still
+// pending today does not break the current streak yet; one left unanswered
+// on an earlier day does.
+func (u *reminderExecutionUsecase) GetStreaks(ctx context.Context, userID uuid.UUID, now time.Time) (*Streaks, error) {
+	days, err := u.GetDailyAdherence(ctx, userID, dayStart(now).AddDate(0, 0, -(StreakLookbackDays-1)), now)
+	if err != nil {
+		return nil, err
+	}
+	return calculateStreaks(days), nil
+}
+
+func calculateStreaks(days []*DailyAdherence) *Streaks {
+	streaks := &Streaks{}
+	for i, day := range days {
+		pendingMissed := day.Pending > 0 && i < len(days)-1
+		switch {
+		case day.Skipped > 0 || day.Missed > 0 || pendingMissed:
+			streaks.Current = 0
+		case day.Confirmed > 0:
+			streaks.Current++
+			if streaks.Current > streaks.Longest {
+				streaks.Longest = streaks.Current
+			}
+		}
+	}
+	return streaks
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween returns the number of calendar days from one day start to
+// another, robust to daylight saving changes.
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+// isCourseFinished reports whether the reminder's course of treatment is
+// over: its end date has passed or all of its doses have been sent. The doses
+// are counted in repo, so that a transaction can take the dose it has just
+// recorded into account.
+func isCourseFinished(ctx context.Context, repo repository.ReminderExecutionRepository, reminder *entities.Reminder, now time.Time) (bool, error) {
+	if reminder.EndsAt != nil && !reminder.EndsAt.After(now) {
+		return true, nil
+	}
+	if reminder.MaxDoses == nil {
+		return false, nil
+	}
+
+	sent, err := repo.CountByReminderID(ctx, reminder.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to count sent doses: %w", err)
+	}
+	return sent >= int64(*reminder.MaxDoses), nil
+}
+
+func (u *reminderExecutionUsecase) GetCourseSummary(ctx context.Context, reminder *entities.Reminder) (*CourseSummary, error) {
+	fromDate := reminder.CreatedAt
+	if reminder.StartsAt != nil {
+		fromDate = *reminder.StartsAt
+	}
+
+	stats, err := u.repo.GetStatisticsByReminderID(ctx, reminder.ID, fromDate, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statistics: %w", err)
+	}
+
+	summary := &CourseSummary{
+		TotalDoses:    stats.TotalDelivered,
+		Confirmed:     stats.TotalConfirmed,
+		Skipped:       stats.TotalSkipped,
+		Missed:        stats.TotalMissed,
+		Unanswered:    stats.TotalPending,
+		AdherenceRate: stats.AdherenceRate,
+	}
+
+	return summary, nil
+}
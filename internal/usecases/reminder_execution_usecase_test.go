@@ -15,93 +15,357 @@ import (
 	"github.com/Helltale/take-your-pills-on-time/internal/repository/mocks"
 )
 
-func TestReminderExecutionUsecase_RecordSent(t *testing.T) {
+func TestReminderExecutionUsecase_RecordConfirmed(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("successful record sent", func(t *testing.T) {
+	t.Run("successful record confirmed", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		mockStockRepo := mocks.NewMockStockRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mockStockRepo)
 
+		executionID := uuid.New()
 		reminderID := uuid.New()
 		userID := uuid.New()
 
-		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, execution *entities.ReminderExecution) error {
-			execution.ID = uuid.New()
-			return nil
-		})
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{
+			ID:         executionID,
+			ReminderID: reminderID,
+			UserID:     userID,
+			Status:     entities.ExecutionStatusSent,
+		}, nil)
+		mockRepo.EXPECT().Confirm(ctx, executionID, gomock.Any()).Return(true, nil)
+		mockStockRepo.EXPECT().Consume(ctx, reminderID).Return(nil)
 
-		execution, err := usecase.RecordSent(ctx, reminderID, userID)
+		changed, err := usecase.RecordConfirmed(ctx, userID, executionID, time.Now())
 
 		assert.NoError(t, err)
-		assert.NotNil(t, execution)
-		assert.Equal(t, reminderID, execution.ReminderID)
-		assert.Equal(t, userID, execution.UserID)
-		assert.Equal(t, entities.ExecutionStatusSent, execution.Status)
-		assert.False(t, execution.SentAt.IsZero())
+		assert.True(t, changed)
 	})
 
-	t.Run("error when repository fails", func(t *testing.T) {
+	t.Run("already confirmed does not consume stock twice", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
-		reminderID := uuid.New()
+		executionID := uuid.New()
 		userID := uuid.New()
-		repoError := errors.New("repository error")
 
-		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(repoError)
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{
+			ID:     executionID,
+			UserID: userID,
+			Status: entities.ExecutionStatusConfirmed,
+		}, nil)
 
-		execution, err := usecase.RecordSent(ctx, reminderID, userID)
+		changed, err := usecase.RecordConfirmed(ctx, userID, executionID, time.Now())
 
-		assert.Error(t, err)
-		assert.Nil(t, execution)
-		assert.Contains(t, err.Error(), "failed to record sent execution")
+		assert.NoError(t, err)
+		assert.False(t, changed)
 	})
-}
-
-func TestReminderExecutionUsecase_RecordConfirmed(t *testing.T) {
-	ctx := context.Background()
 
-	t.Run("successful record confirmed", func(t *testing.T) {
+	t.Run("concurrent answer does not consume stock", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		executionID := uuid.New()
+		userID := uuid.New()
 
-		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusConfirmed).Return(nil)
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{
+			ID:     executionID,
+			UserID: userID,
+			Status: entities.ExecutionStatusSent,
+		}, nil)
+		mockRepo.EXPECT().Confirm(ctx, executionID, gomock.Any()).Return(false, nil)
 
-		err := usecase.RecordConfirmed(ctx, executionID)
+		changed, err := usecase.RecordConfirmed(ctx, userID, executionID, time.Now())
 
 		assert.NoError(t, err)
+		assert.False(t, changed)
 	})
 
+	t.Run("execution not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(nil, nil)
+
+		_, err := usecase.RecordConfirmed(ctx, uuid.New(), executionID, time.Now())
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("execution of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{
+			ID:     executionID,
+			UserID: uuid.New(),
+			Status: entities.ExecutionStatusSent,
+		}, nil)
+
+		_, err := usecase.RecordConfirmed(ctx, uuid.New(), executionID, time.Now())
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("error when intake time is in the future", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderExecutionUsecase(mocks.NewMockReminderExecutionRepository(ctrl), mocks.NewMockStockRepository(ctrl))
+
+		_, err := usecase.RecordConfirmed(ctx, uuid.New(), uuid.New(), time.Now().Add(time.Hour))
+
+		assert.ErrorIs(t, err, ErrInvalidTakenAt)
+	})
+
+	for _, status := range []entities.ExecutionStatus{entities.ExecutionStatusSkipped, entities.ExecutionStatusMissed} {
+		t.Run("refuses "+string(status)+" execution", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+			usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+			executionID := uuid.New()
+			userID := uuid.New()
+
+			mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{
+				ID:     executionID,
+				UserID: userID,
+				Status: status,
+			}, nil)
+
+			_, err := usecase.RecordConfirmed(ctx, userID, executionID, time.Now())
+
+			assert.ErrorIs(t, err, ErrExecutionFinalized)
+		})
+	}
+
 	t.Run("error when repository fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		executionID := uuid.New()
+		userID := uuid.New()
 		repoError := errors.New("repository error")
 
-		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusConfirmed).Return(repoError)
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusSent}, nil)
+		mockRepo.EXPECT().Confirm(ctx, executionID, gomock.Any()).Return(false, repoError)
 
-		err := usecase.RecordConfirmed(ctx, executionID)
+		_, err := usecase.RecordConfirmed(ctx, userID, executionID, time.Now())
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to record confirmed execution")
 	})
 }
 
+func TestReminderExecutionUsecase_LogDose(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	reminder := &entities.Reminder{ID: uuid.New(), UserID: userID}
+
+	t.Run("confirms the closest unanswered reminder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		mockStockRepo := mocks.NewMockStockRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mockStockRepo)
+
+		takenAt := time.Now().Add(-2 * time.Hour)
+		unanswered := &entities.ReminderExecution{
+			ID:         uuid.New(),
+			ReminderID: reminder.ID,
+			UserID:     userID,
+			Status:     entities.ExecutionStatusSent,
+			SentAt:     takenAt.Add(-time.Hour),
+		}
+
+		mockRepo.EXPECT().GetUnansweredNear(ctx, reminder.ID, takenAt, LogDoseWindow).Return(unanswered, nil)
+		mockRepo.EXPECT().Confirm(ctx, unanswered.ID, takenAt).Return(true, nil)
+		mockStockRepo.EXPECT().Consume(ctx, reminder.ID).Return(nil)
+
+		execution, err := usecase.LogDose(ctx, userID, reminder, takenAt)
+
+		assert.NoError(t, err)
+		assert.Equal(t, unanswered.ID, execution.ID)
+		assert.Equal(t, entities.ExecutionStatusConfirmed, execution.Status)
+		assert.Equal(t, takenAt, *execution.TakenAt)
+		assert.True(t, execution.IsLate())
+	})
+
+	t.Run("creates a dose without a reminder to answer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		mockStockRepo := mocks.NewMockStockRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mockStockRepo)
+
+		takenAt := time.Now().Add(-30 * time.Minute)
+
+		mockRepo.EXPECT().GetUnansweredNear(ctx, reminder.ID, takenAt, LogDoseWindow).Return(nil, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, execution *entities.ReminderExecution) error {
+			assert.Equal(t, entities.ExecutionStatusConfirmed, execution.Status)
+			assert.Equal(t, entities.ExecutionSourceLogged, execution.Source)
+			assert.Equal(t, takenAt, execution.SentAt)
+			assert.Equal(t, takenAt, *execution.TakenAt)
+			assert.NotNil(t, execution.ConfirmedAt)
+			return nil
+		})
+		mockStockRepo.EXPECT().Consume(ctx, reminder.ID).Return(nil)
+
+		execution, err := usecase.LogDose(ctx, userID, reminder, takenAt)
+
+		assert.NoError(t, err)
+		assert.False(t, execution.IsLate())
+	})
+
+	t.Run("error when reminder belongs to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderExecutionUsecase(mocks.NewMockReminderExecutionRepository(ctrl), mocks.NewMockStockRepository(ctrl))
+
+		_, err := usecase.LogDose(ctx, uuid.New(), reminder, time.Now())
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	for name, takenAt := range map[string]time.Time{
+		"in the future":   time.Now().Add(time.Hour),
+		"too far in past": time.Now().Add(-MaxBackdate - time.Hour),
+	} {
+		t.Run("error when intake time is "+name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usecase := NewReminderExecutionUsecase(mocks.NewMockReminderExecutionRepository(ctrl), mocks.NewMockStockRepository(ctrl))
+
+			_, err := usecase.LogDose(ctx, userID, reminder, takenAt)
+
+			assert.ErrorIs(t, err, ErrInvalidTakenAt)
+		})
+	}
+}
+
+func TestReminderExecutionUsecase_LogDose_AsNeeded(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	reminder := &entities.Reminder{ID: uuid.New(), UserID: userID, Type: entities.ReminderTypeAsNeeded}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	usecase := NewReminderExecutionUsecase(mockRepo, mockStockRepo)
+
+	takenAt := time.Now().Add(-10 * time.Minute)
+
+	mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+	mockStockRepo.EXPECT().Consume(ctx, reminder.ID).Return(nil)
+
+	execution, err := usecase.LogDose(ctx, userID, reminder, takenAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ExecutionStatusConfirmed, execution.Status)
+	assert.Equal(t, entities.ExecutionSourceAsNeeded, execution.Source)
+	assert.Equal(t, takenAt, execution.SentAt)
+}
+
+func TestReminderExecutionUsecase_CheckDoseLimits(t *testing.T) {
+	ctx := context.Background()
+	intPtr := func(v int) *int { return &v }
+	now := time.Date(2026, 5, 4, 20, 0, 0, 0, time.UTC)
+
+	dose := func(ago time.Duration) *entities.ReminderExecution {
+		takenAt := now.Add(-ago)
+		return &entities.ReminderExecution{Status: entities.ExecutionStatusConfirmed, SentAt: takenAt, TakenAt: &takenAt}
+	}
+
+	tests := []struct {
+		name          string
+		reminder      *entities.Reminder
+		doses         []*entities.ReminderExecution
+		tooSoon       bool
+		overDailyMax  bool
+		nextAllowedAt time.Time
+	}{
+		{
+			name:     "no doses yet",
+			reminder: &entities.Reminder{ID: uuid.New(), MinIntervalMinutes: intPtr(240), MaxDosesPerDay: intPtr(3)},
+		},
+		{
+			name:     "within limits",
+			reminder: &entities.Reminder{ID: uuid.New(), MinIntervalMinutes: intPtr(240), MaxDosesPerDay: intPtr(3)},
+			doses:    []*entities.ReminderExecution{dose(5 * time.Hour), dose(12 * time.Hour)},
+		},
+		{
+			name:          "too soon after the last dose",
+			reminder:      &entities.Reminder{ID: uuid.New(), MinIntervalMinutes: intPtr(240)},
+			doses:         []*entities.ReminderExecution{dose(time.Hour)},
+			tooSoon:       true,
+			nextAllowedAt: now.Add(3 * time.Hour),
+		},
+		{
+			name:          "over the daily maximum",
+			reminder:      &entities.Reminder{ID: uuid.New(), MaxDosesPerDay: intPtr(2)},
+			doses:         []*entities.ReminderExecution{dose(2 * time.Hour), dose(6 * time.Hour), dose(20 * time.Hour)},
+			overDailyMax:  true,
+			nextAllowedAt: now.Add(18 * time.Hour),
+		},
+		{
+			name:     "no limits",
+			reminder: &entities.Reminder{ID: uuid.New()},
+			doses:    []*entities.ReminderExecution{dose(time.Minute), dose(2 * time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+			usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+			mockRepo.EXPECT().GetTakenBetween(ctx, tt.reminder.ID, now.Add(-24*time.Hour), now).Return(tt.doses, nil)
+
+			limits, err := usecase.CheckDoseLimits(ctx, tt.reminder, now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.doses), limits.TakenInDay)
+			assert.Equal(t, tt.tooSoon, limits.TooSoon)
+			assert.Equal(t, tt.overDailyMax, limits.OverDailyMax)
+			assert.Equal(t, tt.tooSoon || tt.overDailyMax, limits.Exceeded())
+			assert.Equal(t, tt.nextAllowedAt, limits.NextAllowedAt)
+		})
+	}
+}
+
 func TestReminderExecutionUsecase_RecordSkipped(t *testing.T) {
 	ctx := context.Background()
 
@@ -110,15 +374,69 @@ func TestReminderExecutionUsecase_RecordSkipped(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		executionID := uuid.New()
+		userID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusSnoozed}, nil)
+		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusSkipped).Return(true, nil)
+
+		changed, err := usecase.RecordSkipped(ctx, userID, executionID)
+
+		assert.NoError(t, err)
+		assert.True(t, changed)
+	})
+
+	t.Run("already skipped changes nothing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		executionID := uuid.New()
+		userID := uuid.New()
 
-		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusSkipped).Return(nil)
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusSkipped}, nil)
 
-		err := usecase.RecordSkipped(ctx, executionID)
+		changed, err := usecase.RecordSkipped(ctx, userID, executionID)
 
 		assert.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("refuses confirmed execution", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		executionID := uuid.New()
+		userID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusConfirmed}, nil)
+
+		_, err := usecase.RecordSkipped(ctx, userID, executionID)
+
+		assert.ErrorIs(t, err, ErrExecutionFinalized)
+	})
+
+	t.Run("execution of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: uuid.New(), Status: entities.ExecutionStatusSent}, nil)
+
+		_, err := usecase.RecordSkipped(ctx, uuid.New(), executionID)
+
+		assert.ErrorIs(t, err, ErrForbidden)
 	})
 
 	t.Run("error when repository fails", func(t *testing.T) {
@@ -126,14 +444,16 @@ func TestReminderExecutionUsecase_RecordSkipped(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		executionID := uuid.New()
+		userID := uuid.New()
 		repoError := errors.New("repository error")
 
-		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusSkipped).Return(repoError)
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusSent}, nil)
+		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusSkipped).Return(false, repoError)
 
-		err := usecase.RecordSkipped(ctx, executionID)
+		_, err := usecase.RecordSkipped(ctx, userID, executionID)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to record skipped execution")
@@ -148,7 +468,7 @@ func TestReminderExecutionUsecase_GetHistoryByReminderID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		reminderID := uuid.New()
 		limit := 10
@@ -170,7 +490,7 @@ func TestReminderExecutionUsecase_GetHistoryByReminderID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		reminderID := uuid.New()
 		expectedExecutions := []*entities.ReminderExecution{}
@@ -188,7 +508,7 @@ func TestReminderExecutionUsecase_GetHistoryByReminderID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		reminderID := uuid.New()
 		repoError := errors.New("repository error")
@@ -210,7 +530,7 @@ func TestReminderExecutionUsecase_GetHistoryByUserID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		userID := uuid.New()
 		limit := 20
@@ -231,7 +551,7 @@ func TestReminderExecutionUsecase_GetHistoryByUserID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		userID := uuid.New()
 		expectedExecutions := []*entities.ReminderExecution{}
@@ -253,17 +573,17 @@ func TestReminderExecutionUsecase_GetStatisticsByUserID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		userID := uuid.New()
 		fromDate := time.Now().AddDate(0, 0, -30)
 		toDate := time.Now()
 
 		expectedStats := &repository.ExecutionStatistics{
-			TotalSent:        10,
-			TotalConfirmed:   8,
-			TotalSkipped:     2,
-			ConfirmationRate: 80.0,
+			TotalDelivered: 10,
+			TotalConfirmed: 8,
+			TotalSkipped:   2,
+			AdherenceRate:  80.0,
 		}
 
 		mockRepo.EXPECT().GetStatisticsByUserID(ctx, userID, fromDate, toDate).Return(expectedStats, nil)
@@ -272,10 +592,10 @@ func TestReminderExecutionUsecase_GetStatisticsByUserID(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.NotNil(t, stats)
-		assert.Equal(t, 10, stats.TotalSent)
+		assert.Equal(t, 10, stats.TotalDelivered)
 		assert.Equal(t, 8, stats.TotalConfirmed)
 		assert.Equal(t, 2, stats.TotalSkipped)
-		assert.Equal(t, 80.0, stats.ConfirmationRate)
+		assert.Equal(t, 80.0, stats.AdherenceRate)
 	})
 
 	t.Run("error when repository fails", func(t *testing.T) {
@@ -283,7 +603,7 @@ func TestReminderExecutionUsecase_GetStatisticsByUserID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		userID := uuid.New()
 		fromDate := time.Now().AddDate(0, 0, -30)
@@ -308,17 +628,17 @@ func TestReminderExecutionUsecase_GetStatisticsByReminderID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		reminderID := uuid.New()
 		fromDate := time.Now().AddDate(0, 0, -7)
 		toDate := time.Now()
 
 		expectedStats := &repository.ExecutionStatistics{
-			TotalSent:        7,
-			TotalConfirmed:   5,
-			TotalSkipped:     2,
-			ConfirmationRate: 71.43,
+			TotalDelivered: 7,
+			TotalConfirmed: 5,
+			TotalSkipped:   2,
+			AdherenceRate:  71.43,
 		}
 
 		mockRepo.EXPECT().GetStatisticsByReminderID(ctx, reminderID, fromDate, toDate).Return(expectedStats, nil)
@@ -327,7 +647,7 @@ func TestReminderExecutionUsecase_GetStatisticsByReminderID(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.NotNil(t, stats)
-		assert.Equal(t, 7, stats.TotalSent)
+		assert.Equal(t, 7, stats.TotalDelivered)
 		assert.Equal(t, 5, stats.TotalConfirmed)
 	})
 
@@ -336,7 +656,7 @@ func TestReminderExecutionUsecase_GetStatisticsByReminderID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
-		usecase := NewReminderExecutionUsecase(mockRepo)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
 
 		reminderID := uuid.New()
 		fromDate := time.Now().AddDate(0, 0, -7)
@@ -351,3 +671,591 @@ func TestReminderExecutionUsecase_GetStatisticsByReminderID(t *testing.T) {
 		assert.Nil(t, stats)
 	})
 }
+
+func TestReminderExecutionUsecase_GetSlotStatisticsByReminderID(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful get slot statistics", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		reminderID := uuid.New()
+		fromDate := time.Now().AddDate(0, 0, -7)
+		toDate := time.Now()
+
+		expectedStats := []*repository.SlotStatistics{
+			{Slot: "08:00", ExecutionStatistics: repository.ExecutionStatistics{TotalConfirmed: 7}},
+			{Slot: "21:00", ExecutionStatistics: repository.ExecutionStatistics{TotalConfirmed: 4, TotalSkipped: 3}},
+		}
+
+		mockRepo.EXPECT().GetSlotStatisticsByReminderID(ctx, reminderID, fromDate, toDate).Return(expectedStats, nil)
+
+		stats, err := usecase.GetSlotStatisticsByReminderID(ctx, reminderID, fromDate, toDate)
+
+		assert.NoError(t, err)
+		assert.Len(t, stats, 2)
+		assert.Equal(t, "21:00", stats[1].Slot)
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		reminderID := uuid.New()
+		fromDate := time.Now().AddDate(0, 0, -7)
+		toDate := time.Now()
+
+		mockRepo.EXPECT().GetSlotStatisticsByReminderID(ctx, reminderID, fromDate, toDate).Return(nil, errors.New("repository error"))
+
+		stats, err := usecase.GetSlotStatisticsByReminderID(ctx, reminderID, fromDate, toDate)
+
+		assert.Error(t, err)
+		assert.Nil(t, stats)
+		assert.Contains(t, err.Error(), "failed to get slot statistics")
+	})
+}
+
+func TestReminderExecutionUsecase_GetDailyAdherence(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+	usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	userID := uuid.New()
+	fromDate := time.Date(2026, 5, 1, 15, 0, 0, 0, loc)
+	toDate := time.Date(2026, 5, 3, 12, 0, 0, 0, loc)
+
+	execution := func(sentAt time.Time, status entities.ExecutionStatus) *entities.ReminderExecution {
+		return &entities.ReminderExecution{UserID: userID, SentAt: sentAt, Status: status}
+	}
+
+	mockRepo.EXPECT().GetSentBetween(ctx, userID, time.Date(2026, 5, 1, 0, 0, 0, 0, loc), toDate).Return([]*entities.ReminderExecution{
+		execution(time.Date(2026, 5, 1, 6, 0, 0, 0, time.UTC), entities.ExecutionStatusConfirmed),
+		execution(time.Date(2026, 5, 1, 22, 30, 0, 0, time.UTC), entities.ExecutionStatusSkipped),
+		execution(time.Date(2026, 5, 3, 6, 0, 0, 0, time.UTC), entities.ExecutionStatusMissed),
+		execution(time.Date(2026, 5, 3, 8, 0, 0, 0, time.UTC), entities.ExecutionStatusSnoozed),
+	}, nil)
+
+	days, err := usecase.GetDailyAdherence(ctx, userID, fromDate, toDate)
+
+	assert.NoError(t, err)
+	assert.Len(t, days, 3)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, loc), days[0].Date)
+	assert.Equal(t, 1, days[0].Confirmed)
+	// 22:30 UTC is already the next day in UTC+3.
+	assert.Equal(t, 1, days[1].Skipped)
+	assert.Equal(t, 1, days[2].Missed)
+	assert.Equal(t, 1, days[2].Pending)
+	assert.Equal(t, 100.0, days[0].Rate())
+	assert.Equal(t, 0.0, days[2].Rate())
+}
+
+func TestReminderExecutionUsecase_CalculateStreaks(t *testing.T) {
+	taken := &DailyAdherence{Confirmed: 2}
+	skipped := &DailyAdherence{Confirmed: 1, Skipped: 1}
+	empty := &DailyAdherence{}
+	pending := &DailyAdherence{Confirmed: 1, Pending: 1}
+
+	tests := []struct {
+		name     string
+		days     []*DailyAdherence
+		expected Streaks
+	}{
+		{"no doses", []*DailyAdherence{empty, empty}, Streaks{}},
+		{"every day taken", []*DailyAdherence{taken, taken, taken}, Streaks{Current: 3, Longest: 3}},
+		{"skip breaks the streak", []*DailyAdherence{taken, taken, taken, skipped, taken}, Streaks{Current: 1, Longest: 3}},
+		{"days without doses are neutral", []*DailyAdherence{taken, empty, taken}, Streaks{Current: 2, Longest: 2}},
+		{"pending today keeps the streak", []*DailyAdherence{taken, taken, pending}, Streaks{Current: 3, Longest: 3}},
+		{"pending on an earlier day breaks it", []*DailyAdherence{taken, pending, taken}, Streaks{Current: 1, Longest: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, *calculateStreaks(tt.days))
+		})
+	}
+}
+
+func TestReminderExecutionUsecase_GetCourseSummary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("summary from course start", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		startsAt := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+		reminder := &entities.Reminder{ID: uuid.New(), StartsAt: &startsAt, CreatedAt: startsAt.AddDate(0, 0, -3)}
+
+		mockRepo.EXPECT().GetStatisticsByReminderID(ctx, reminder.ID, startsAt, gomock.Any()).Return(&repository.ExecutionStatistics{
+			TotalDelivered: 16,
+			TotalPending:   1,
+			TotalConfirmed: 12,
+			TotalSkipped:   2,
+			TotalMissed:    1,
+			AdherenceRate:  75,
+		}, nil)
+
+		summary, err := usecase.GetCourseSummary(ctx, reminder)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 16, summary.TotalDoses)
+		assert.Equal(t, 12, summary.Confirmed)
+		assert.Equal(t, 2, summary.Skipped)
+		assert.Equal(t, 1, summary.Missed)
+		assert.Equal(t, 1, summary.Unanswered)
+		assert.InDelta(t, 75.0, summary.AdherenceRate, 0.001)
+	})
+
+	t.Run("no doses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		reminder := &entities.Reminder{ID: uuid.New(), CreatedAt: time.Now()}
+
+		mockRepo.EXPECT().GetStatisticsByReminderID(ctx, reminder.ID, reminder.CreatedAt, gomock.Any()).Return(&repository.ExecutionStatistics{}, nil)
+
+		summary, err := usecase.GetCourseSummary(ctx, reminder)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, summary.TotalDoses)
+		assert.Zero(t, summary.AdherenceRate)
+	})
+}
+
+func TestReminderExecutionUsecase_Snooze(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful snooze", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+		userID := uuid.New()
+
+		var snoozedUntil time.Time
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusSent}, nil)
+		mockRepo.EXPECT().Snooze(ctx, executionID, gomock.Any()).DoAndReturn(func(ctx context.Context, id uuid.UUID, until time.Time) error {
+			snoozedUntil = until
+			return nil
+		})
+
+		before := time.Now()
+		until, err := usecase.Snooze(ctx, userID, executionID, 30*time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, snoozedUntil, until)
+		assert.WithinDuration(t, before.Add(30*time.Minute), until, time.Second)
+	})
+
+	t.Run("error when duration is not positive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderExecutionUsecase(mocks.NewMockReminderExecutionRepository(ctrl), mocks.NewMockStockRepository(ctrl))
+
+		_, err := usecase.Snooze(ctx, uuid.New(), uuid.New(), 0)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "snooze duration")
+	})
+
+	t.Run("error when duration is too long", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderExecutionUsecase(mocks.NewMockReminderExecutionRepository(ctrl), mocks.NewMockStockRepository(ctrl))
+
+		_, err := usecase.Snooze(ctx, uuid.New(), uuid.New(), MaxSnoozeDuration+time.Minute)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("error when duration is too short", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderExecutionUsecase(mocks.NewMockReminderExecutionRepository(ctrl), mocks.NewMockStockRepository(ctrl))
+
+		_, err := usecase.Snooze(ctx, uuid.New(), uuid.New(), 30*time.Second)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("refuses finalized execution", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+		userID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusConfirmed}, nil)
+
+		_, err := usecase.Snooze(ctx, userID, executionID, 10*time.Minute)
+
+		assert.ErrorIs(t, err, ErrExecutionFinalized)
+	})
+
+	t.Run("execution of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: uuid.New(), Status: entities.ExecutionStatusSent}, nil)
+
+		_, err := usecase.Snooze(ctx, uuid.New(), executionID, 10*time.Minute)
+
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+		userID := uuid.New()
+
+		mockRepo.EXPECT().GetByID(ctx, executionID).Return(&entities.ReminderExecution{ID: executionID, UserID: userID, Status: entities.ExecutionStatusSent}, nil)
+		mockRepo.EXPECT().Snooze(ctx, executionID, gomock.Any()).Return(errors.New("repository error"))
+
+		_, err := usecase.Snooze(ctx, userID, executionID, 10*time.Minute)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to snooze execution")
+	})
+}
+
+func TestReminderExecutionUsecase_GetDueSnoozed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful get due snoozed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		expected := []*entities.ReminderExecution{{ID: uuid.New(), Status: entities.ExecutionStatusSnoozed}}
+		mockRepo.EXPECT().GetDueSnoozed(ctx).Return(expected, nil)
+
+		executions, err := usecase.GetDueSnoozed(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, executions)
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		mockRepo.EXPECT().GetDueSnoozed(ctx).Return(nil, errors.New("repository error"))
+
+		executions, err := usecase.GetDueSnoozed(ctx)
+
+		assert.Error(t, err)
+		assert.Nil(t, executions)
+	})
+}
+
+func TestReminderExecutionUsecase_RecordDelivered(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful record delivered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().MarkDelivered(ctx, executionID, 42, gomock.Any()).Return(nil)
+
+		assert.NoError(t, usecase.RecordDelivered(ctx, executionID, 42))
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().MarkDelivered(ctx, executionID, 42, gomock.Any()).Return(errors.New("repository error"))
+
+		err := usecase.RecordDelivered(ctx, executionID, 42)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record delivered execution")
+	})
+}
+
+func TestReminderExecutionUsecase_ClaimSnoozed(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+	usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+	executionID := uuid.New()
+
+	mockRepo.EXPECT().ClaimSnoozed(ctx, executionID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, now, leaseUntil time.Time) (bool, error) {
+			assert.Equal(t, DeliveryLease, leaseUntil.Sub(now))
+			return true, nil
+		})
+
+	claimed, err := usecase.ClaimSnoozed(ctx, executionID)
+
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestReminderExecutionUsecase_ClaimNag(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+	usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+	execution := &entities.ReminderExecution{ID: uuid.New(), Status: entities.ExecutionStatusSent, NagCount: 2}
+
+	mockRepo.EXPECT().ClaimNag(ctx, execution.ID, 2, gomock.Any()).Return(false, nil)
+
+	claimed, err := usecase.ClaimNag(ctx, execution)
+
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestReminderExecutionUsecase_ReleaseNag(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("restores the previous repeat", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		naggedAt := time.Now().Add(-time.Hour)
+		execution := &entities.ReminderExecution{ID: uuid.New(), Status: entities.ExecutionStatusSent, NagCount: 2, NaggedAt: &naggedAt}
+
+		mockRepo.EXPECT().ReleaseNag(ctx, execution.ID, 2, &naggedAt).Return(true, nil)
+
+		assert.NoError(t, usecase.ReleaseNag(ctx, execution))
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		execution := &entities.ReminderExecution{ID: uuid.New(), Status: entities.ExecutionStatusSent}
+
+		mockRepo.EXPECT().ReleaseNag(ctx, execution.ID, 0, nil).Return(false, errors.New("database error"))
+
+		err := usecase.ReleaseNag(ctx, execution)
+
+		assert.ErrorContains(t, err, "failed to release repeated reminder")
+	})
+}
+
+func TestReminderExecutionUsecase_RecordNagged(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+	usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+	executionID := uuid.New()
+
+	mockRepo.EXPECT().RecordNag(ctx, executionID, 43, gomock.Any()).Return(nil)
+
+	assert.NoError(t, usecase.RecordNagged(ctx, executionID, 43))
+}
+
+func TestReminderExecutionUsecase_RecordMissed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful record missed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusMissed).Return(true, nil)
+
+		missed, err := usecase.RecordMissed(ctx, executionID)
+
+		assert.NoError(t, err)
+		assert.True(t, missed)
+	})
+
+	t.Run("already answered", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusMissed).Return(false, nil)
+
+		missed, err := usecase.RecordMissed(ctx, executionID)
+
+		assert.NoError(t, err)
+		assert.False(t, missed)
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+		executionID := uuid.New()
+
+		mockRepo.EXPECT().UpdateStatus(ctx, executionID, entities.ExecutionStatusMissed).Return(false, errors.New("repository error"))
+
+		_, err := usecase.RecordMissed(ctx, executionID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record missed execution")
+	})
+}
+
+func TestReminderExecutionUsecase_GetDueNags(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+	usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+	expected := []*entities.ReminderExecution{{ID: uuid.New(), Status: entities.ExecutionStatusSent, NagCount: 1}}
+	mockRepo.EXPECT().GetDueNags(ctx, gomock.Any()).Return(expected, nil)
+
+	executions, err := usecase.GetDueNags(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, executions)
+}
+
+func TestReminderExecutionUsecase_Escalations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get due escalations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		expected := []*entities.ReminderExecution{{ID: uuid.New(), Status: entities.ExecutionStatusMissed}}
+		mockRepo.EXPECT().GetDueEscalations(ctx, gomock.Any()).Return(expected, nil)
+
+		executions, err := usecase.GetDueEscalations(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, executions)
+	})
+
+	t.Run("claim escalation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		executionID := uuid.New()
+		mockRepo.EXPECT().MarkEscalated(ctx, executionID, gomock.Any()).Return(true, nil)
+
+		claimed, err := usecase.ClaimEscalation(ctx, executionID)
+
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+	})
+
+	t.Run("claim escalation fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		executionID := uuid.New()
+		mockRepo.EXPECT().MarkEscalated(ctx, executionID, gomock.Any()).Return(false, errors.New("db error"))
+
+		_, err := usecase.ClaimEscalation(ctx, executionID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record escalated execution")
+	})
+}
+
+func TestReminderExecutionUsecase_ExpireUnanswered(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	t.Run("misses doses unanswered for the timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		mockRepo.EXPECT().MissUnanswered(ctx, now.Add(-UnansweredTimeout)).Return(int64(3), nil)
+
+		count, err := usecase.ExpireUnanswered(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("error when repository fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderExecutionRepository(ctrl)
+		usecase := NewReminderExecutionUsecase(mockRepo, mocks.NewMockStockRepository(ctrl))
+
+		mockRepo.EXPECT().MissUnanswered(ctx, gomock.Any()).Return(int64(0), errors.New("repository error"))
+
+		_, err := usecase.ExpireUnanswered(ctx, now)
+
+		assert.ErrorContains(t, err, "failed to record missed executions")
+	})
+}
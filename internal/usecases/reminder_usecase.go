@@ -3,64 +3,159 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/Helltale/take-your-pills-on-time/internal/entities"
 	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+	"github.com/Helltale/take-your-pills-on-time/internal/rrule"
 )
 
 type ReminderUsecase interface {
-	Create(ctx context.Context, userID uuid.UUID, title string, comment *string, imageURL *string, reminderType entities.ReminderType, intervalHours *int, timeOfDay *string) (*entities.Reminder, error)
+	Create(ctx context.Context, input CreateReminderInput) (*entities.Reminder, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Reminder, error)
+	GetOwned(ctx context.Context, userID, id uuid.UUID) (*entities.Reminder, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Reminder, error)
 	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Reminder, error)
-	Update(ctx context.Context, id uuid.UUID, title *string, comment *string, imageURL *string, reminderType *entities.ReminderType, intervalHours *int, timeOfDay *string, isActive *bool) (*entities.Reminder, error)
+	Update(ctx context.Context, id uuid.UUID, input UpdateReminderInput) (*entities.Reminder, error)
 	Delete(ctx context.Context, id uuid.UUID) error
-	CalculateNextSendTime(reminder *entities.Reminder) time.Time
+	RescheduleByUserID(ctx context.Context, userID uuid.UUID) error
+	NextSendTime(ctx context.Context, reminder *entities.Reminder) (time.Time, error)
+	ResolveSlot(ctx context.Context, reminder *entities.Reminder) (*string, error)
+	CalculateNextSendTime(reminder *entities.Reminder, loc *time.Location) time.Time
+	DosesPerDay(ctx context.Context, reminder *entities.Reminder) (float64, error)
+}
+
+type CreateReminderInput struct {
+	UserID             uuid.UUID
+	Title              string
+	Comment            *string
+	ImageURL           *string
+	ImageFileID        *string
+	Type               entities.ReminderType
+	IntervalHours      *int
+	TimesOfDay         []string
+	Weekdays           entities.Weekdays
+	RRule              *string
+	StartsAt           *time.Time
+	EndsAt             *time.Time
+	MaxDoses           *int
+	NagIntervalMinutes *int
+	NagMaxRepeats      *int
+	MinIntervalMinutes *int
+	MaxDosesPerDay     *int
+}
+
+// UpdateReminderInput holds the fields to change; nil fields are left as is.
+// An empty Comment, ImageURL or ImageFileID removes it. Changing Type drops the schedule
+// fields the new type does not use. NagIntervalMinutes and NagMaxRepeats are
+// changed together; a zero interval turns repeating off. A zero
+// MinIntervalMinutes or MaxDosesPerDay removes that limit.
+type UpdateReminderInput struct {
+	Title              *string
+	Comment            *string
+	ImageURL           *string
+	ImageFileID        *string
+	Type               *entities.ReminderType
+	IntervalHours      *int
+	TimesOfDay         []string
+	Weekdays           *entities.Weekdays
+	RRule              *string
+	StartsAt           *time.Time
+	EndsAt             *time.Time
+	MaxDoses           *int
+	NagIntervalMinutes *int
+	NagMaxRepeats      *int
+	MinIntervalMinutes *int
+	MaxDosesPerDay     *int
+	IsActive           *bool
 }
 
 type reminderUsecase struct {
-	repo repository.ReminderRepository
+	repo     repository.ReminderRepository
+	userRepo repository.UserRepository
 }
 
-func NewReminderUsecase(repo repository.ReminderRepository) ReminderUsecase {
-	return &reminderUsecase{repo: repo}
+func NewReminderUsecase(repo repository.ReminderRepository, userRepo repository.UserRepository) ReminderUsecase {
+	return &reminderUsecase{
+		repo:     repo,
+		userRepo: userRepo,
+	}
 }
 
-func (u *reminderUsecase) Create(ctx context.Context, userID uuid.UUID, title string, comment *string, imageURL *string, reminderType entities.ReminderType, intervalHours *int, timeOfDay *string) (*entities.Reminder, error) {
-	if title == "" {
+func (u *reminderUsecase) Create(ctx context.Context, input CreateReminderInput) (*entities.Reminder, error) {
+	if input.Title == "" {
 		return nil, fmt.Errorf("title is required")
 	}
 
-	switch reminderType {
-	case entities.ReminderTypeCustom:
-		if intervalHours == nil || *intervalHours <= 0 {
-			return nil, fmt.Errorf("interval_hours is required for custom type and must be greater than 0")
-		}
-	case entities.ReminderTypeSpecific:
-		if timeOfDay == nil || *timeOfDay == "" {
-			return nil, fmt.Errorf("time_of_day is required for specific type")
-		}
-		if _, err := time.Parse("15:04", *timeOfDay); err != nil {
-			return nil, fmt.Errorf("invalid time_of_day format, expected HH:MM")
-		}
+	times, err := normalizeTimesOfDay(input.TimesOfDay)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSchedule(input.Type, input.IntervalHours, times, input.Weekdays, input.RRule); err != nil {
+		return nil, err
+	}
+
+	if err := validateCourse(input.StartsAt, input.EndsAt, input.MaxDoses); err != nil {
+		return nil, err
+	}
+	if input.EndsAt != nil && !input.EndsAt.After(time.Now()) {
+		return nil, fmt.Errorf("ends_at must be in the future")
+	}
+	if err := validateNag(input.NagIntervalMinutes, input.NagMaxRepeats); err != nil {
+		return nil, err
+	}
+	if err := validateDoseLimits(input.MinIntervalMinutes, input.MaxDosesPerDay); err != nil {
+		return nil, err
+	}
+
+	loc, err := u.userLocation(ctx, input.UserID)
+	if err != nil {
+		return nil, err
 	}
 
 	reminder := &entities.Reminder{
-		UserID:        userID,
-		Title:         title,
-		Comment:       comment,
-		ImageURL:      imageURL,
-		Type:          reminderType,
-		IntervalHours: intervalHours,
-		TimeOfDay:     timeOfDay,
-		IsActive:      true,
+		UserID:             input.UserID,
+		Title:              input.Title,
+		Comment:            input.Comment,
+		ImageURL:           input.ImageURL,
+		ImageFileID:        input.ImageFileID,
+		Type:               input.Type,
+		IntervalHours:      input.IntervalHours,
+		TimesOfDay:         times,
+		Weekdays:           input.Weekdays,
+		StartsAt:           input.StartsAt,
+		EndsAt:             input.EndsAt,
+		MaxDoses:           input.MaxDoses,
+		IsActive:           true,
+		NagIntervalMinutes: input.NagIntervalMinutes,
+		NagMaxRepeats:      input.NagMaxRepeats,
+	}
+	if reminder.IsAsNeeded() {
+		reminder.TimesOfDay = nil
+		reminder.MinIntervalMinutes = nilIfZero(input.MinIntervalMinutes)
+		reminder.MaxDosesPerDay = nilIfZero(input.MaxDosesPerDay)
+	}
+
+	if input.Type == entities.ReminderTypeRRule {
+		value, err := normalizeRRule(*input.RRule, time.Now().In(loc))
+		if err != nil {
+			return nil, err
+		}
+		reminder.RRule = &value
 	}
 
-	nextTime := u.CalculateNextSendTime(reminder)
-	reminder.NextSendAt = &nextTime
+	if !reminder.IsAsNeeded() {
+		nextTime := u.CalculateNextSendTime(reminder, loc)
+		if nextTime.IsZero() {
+			return nil, fmt.Errorf("rrule has no upcoming occurrences")
+		}
+		reminder.NextSendAt = &nextTime
+	}
 
 	if err := u.repo.Create(ctx, reminder); err != nil {
 		return nil, fmt.Errorf("failed to create reminder: %w", err)
@@ -77,6 +172,22 @@ func (u *reminderUsecase) GetByID(ctx context.Context, id uuid.UUID) (*entities.
 	return reminder, nil
 }
 
+// GetOwned returns the user's reminder, failing with ErrNotFound or
+// ErrForbidden when it does not exist or belongs to someone else.
+func (u *reminderUsecase) GetOwned(ctx context.Context, userID, id uuid.UUID) (*entities.Reminder, error) {
+	reminder, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reminder == nil {
+		return nil, ErrNotFound
+	}
+	if reminder.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return reminder, nil
+}
+
 func (u *reminderUsecase) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Reminder, error) {
 	reminders, err := u.repo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -93,7 +204,7 @@ func (u *reminderUsecase) GetActiveByUserID(ctx context.Context, userID uuid.UUI
 	return reminders, nil
 }
 
-func (u *reminderUsecase) Update(ctx context.Context, id uuid.UUID, title *string, comment *string, imageURL *string, reminderType *entities.ReminderType, intervalHours *int, timeOfDay *string, isActive *bool) (*entities.Reminder, error) {
+func (u *reminderUsecase) Update(ctx context.Context, id uuid.UUID, input UpdateReminderInput) (*entities.Reminder, error) {
 	reminder, err := u.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reminder: %w", err)
@@ -102,36 +213,125 @@ func (u *reminderUsecase) Update(ctx context.Context, id uuid.UUID, title *strin
 		return nil, fmt.Errorf("reminder not found")
 	}
 
-	if title != nil {
-		reminder.Title = *title
+	reschedule := false
+	if input.Title != nil {
+		reminder.Title = *input.Title
 	}
-	if comment != nil {
-		reminder.Comment = comment
+	if input.Comment != nil {
+		reminder.Comment = nilIfEmpty(input.Comment)
 	}
-	if imageURL != nil {
-		reminder.ImageURL = imageURL
+	if input.ImageURL != nil {
+		reminder.ImageURL = nilIfEmpty(input.ImageURL)
 	}
-	if reminderType != nil {
-		reminder.Type = *reminderType
-		nextTime := u.CalculateNextSendTime(reminder)
-		reminder.NextSendAt = &nextTime
+	if input.ImageFileID != nil {
+		reminder.ImageFileID = nilIfEmpty(input.ImageFileID)
+	}
+	if input.Type != nil {
+		reminder.Type = *input.Type
+		clearUnusedSchedule(reminder)
+		reschedule = true
 	}
-	if intervalHours != nil {
-		reminder.IntervalHours = intervalHours
+	if input.IntervalHours != nil {
+		reminder.IntervalHours = input.IntervalHours
 		if reminder.Type == entities.ReminderTypeCustom {
-			nextTime := u.CalculateNextSendTime(reminder)
-			reminder.NextSendAt = &nextTime
+			reschedule = true
+		}
+	}
+	if input.TimesOfDay != nil {
+		times, err := normalizeTimesOfDay(input.TimesOfDay)
+		if err != nil {
+			return nil, err
+		}
+		reminder.TimesOfDay = times
+		if isWallClockSchedule(reminder) {
+			reschedule = true
+		}
+	}
+	if input.Weekdays != nil {
+		reminder.Weekdays = *input.Weekdays
+		if isWallClockSchedule(reminder) {
+			reschedule = true
+		}
+	}
+	if input.RRule != nil {
+		reminder.RRule = input.RRule
+		if reminder.Type == entities.ReminderTypeRRule {
+			reschedule = true
+		}
+	}
+	if input.Type != nil || input.IntervalHours != nil || input.TimesOfDay != nil || input.Weekdays != nil || input.RRule != nil {
+		if err := validateSchedule(reminder.Type, reminder.IntervalHours, reminder.TimesOfDay, reminder.Weekdays, reminder.RRule); err != nil {
+			return nil, err
+		}
+	}
+	if input.StartsAt != nil {
+		reminder.StartsAt = input.StartsAt
+		reschedule = true
+	}
+	if input.EndsAt != nil {
+		reminder.EndsAt = input.EndsAt
+		reschedule = true
+	}
+	if input.MaxDoses != nil {
+		reminder.MaxDoses = input.MaxDoses
+	}
+	if input.StartsAt != nil || input.EndsAt != nil || input.MaxDoses != nil {
+		if err := validateCourse(reminder.StartsAt, reminder.EndsAt, reminder.MaxDoses); err != nil {
+			return nil, err
 		}
 	}
-	if timeOfDay != nil {
-		reminder.TimeOfDay = timeOfDay
-		if reminder.Type == entities.ReminderTypeSpecific {
-			nextTime := u.CalculateNextSendTime(reminder)
-			reminder.NextSendAt = &nextTime
+	if input.NagIntervalMinutes != nil || input.NagMaxRepeats != nil {
+		if input.NagIntervalMinutes != nil && *input.NagIntervalMinutes == 0 {
+			reminder.NagIntervalMinutes = nil
+			reminder.NagMaxRepeats = nil
+		} else {
+			if err := validateNag(input.NagIntervalMinutes, input.NagMaxRepeats); err != nil {
+				return nil, err
+			}
+			reminder.NagIntervalMinutes = input.NagIntervalMinutes
+			reminder.NagMaxRepeats = input.NagMaxRepeats
 		}
 	}
-	if isActive != nil {
-		reminder.IsActive = *isActive
+	if input.MinIntervalMinutes != nil || input.MaxDosesPerDay != nil {
+		if err := validateDoseLimits(input.MinIntervalMinutes, input.MaxDosesPerDay); err != nil {
+			return nil, err
+		}
+		if input.MinIntervalMinutes != nil {
+			reminder.MinIntervalMinutes = nilIfZero(input.MinIntervalMinutes)
+		}
+		if input.MaxDosesPerDay != nil {
+			reminder.MaxDosesPerDay = nilIfZero(input.MaxDosesPerDay)
+		}
+	}
+	if input.IsActive != nil {
+		if *input.IsActive && !reminder.IsActive {
+			reschedule = true
+		}
+		reminder.IsActive = *input.IsActive
+	}
+
+	if reschedule && reminder.IsAsNeeded() {
+		reminder.NextSendAt = nil
+	} else if reschedule {
+		loc, err := u.userLocation(ctx, reminder.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if reminder.Type == entities.ReminderTypeRRule {
+			if reminder.RRule == nil {
+				return nil, fmt.Errorf("rrule is required for rrule type")
+			}
+			value, err := normalizeRRule(*reminder.RRule, time.Now().In(loc))
+			if err != nil {
+				return nil, err
+			}
+			reminder.RRule = &value
+		}
+		nextTime := u.CalculateNextSendTime(reminder, loc)
+		if nextTime.IsZero() {
+			return nil, fmt.Errorf("rrule has no upcoming occurrences")
+		}
+		reminder.NextSendAt = &nextTime
 	}
 
 	if err := u.repo.Update(ctx, reminder); err != nil {
@@ -148,15 +348,341 @@ func (u *reminderUsecase) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (u *reminderUsecase) CalculateNextSendTime(reminder *entities.Reminder) time.Time {
-	now := time.Now()
+func (u *reminderUsecase) RescheduleByUserID(ctx context.Context, userID uuid.UUID) error {
+	loc, err := u.userLocation(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	reminders, err := u.repo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get active reminders: %w", err)
+	}
+
+	for _, reminder := range reminders {
+		if !isWallClockSchedule(reminder) {
+			continue
+		}
+		nextTime := u.CalculateNextSendTime(reminder, loc)
+		if nextTime.IsZero() {
+			continue
+		}
+		if err := u.repo.UpdateNextSendAt(ctx, reminder.ID, nextTime); err != nil {
+			return fmt.Errorf("failed to reschedule reminder: %w", err)
+		}
+		reminder.NextSendAt = &nextTime
+	}
+
+	return nil
+}
+
+func (u *reminderUsecase) NextSendTime(ctx context.Context, reminder *entities.Reminder) (time.Time, error) {
+	loc, err := u.userLocation(ctx, reminder.UserID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return u.CalculateNextSendTime(reminder, loc), nil
+}
+
+// DosesPerDay returns how many doses a day the reminder's schedule will
+// produce on average over the coming weeks.
+func (u *reminderUsecase) DosesPerDay(ctx context.Context, reminder *entities.Reminder) (float64, error) {
+	loc, err := u.userLocation(ctx, reminder.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return u.dosesPerDay(reminder, time.Now().In(loc)), nil
+}
+
+// dosePlanningWindow is how far ahead DosesPerDay looks at the schedule.
+const dosePlanningWindow = 28 * 24 * time.Hour
+
+func (u *reminderUsecase) dosesPerDay(reminder *entities.Reminder, now time.Time) float64 {
+	end := now.Add(dosePlanningWindow)
+	if reminder.EndsAt != nil && reminder.EndsAt.Before(end) {
+		end = *reminder.EndsAt
+	}
+	if !end.After(now) {
+		return 0
+	}
+
+	doses := 0
+	for t := now; ; {
+		next := u.nextSendTimeAfter(reminder, t)
+		if next.IsZero() || !next.After(t) || next.After(end) {
+			break
+		}
+		doses++
+		t = next
+	}
+
+	return float64(doses) / (float64(end.Sub(now)) / float64(24*time.Hour))
+}
+
+// ResolveSlot returns the dose time the reminder's pending occurrence
+// belongs to, or nil when the reminder is not scheduled by time of day.
+func (u *reminderUsecase) ResolveSlot(ctx context.Context, reminder *entities.Reminder) (*string, error) {
+	if reminder.NextSendAt == nil || !isWallClockSchedule(reminder) {
+		return nil, nil
+	}
+
+	loc, err := u.userLocation(ctx, reminder.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	slot := reminder.NextSendAt.In(loc).Format("15:04")
+	for _, t := range reminder.TimesOfDay {
+		if t == slot {
+			return &slot, nil
+		}
+	}
+	return nil, nil
+}
+
+func (u *reminderUsecase) userLocation(ctx context.Context, userID uuid.UUID) (*time.Location, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user.Location(), nil
+}
+
+// clearUnusedSchedule drops the schedule fields that the reminder's type
+// does not read, so that they do not linger after the type is changed.
+func clearUnusedSchedule(reminder *entities.Reminder) {
+	if reminder.Type != entities.ReminderTypeCustom {
+		reminder.IntervalHours = nil
+	}
+	if reminder.Type != entities.ReminderTypeWeekly {
+		reminder.Weekdays = 0
+	}
+	if reminder.Type != entities.ReminderTypeRRule {
+		reminder.RRule = nil
+	}
+	if reminder.Type != entities.ReminderTypeAsNeeded {
+		reminder.MinIntervalMinutes = nil
+		reminder.MaxDosesPerDay = nil
+	}
+	switch reminder.Type {
+	case entities.ReminderTypeCustom, entities.ReminderTypeRRule, entities.ReminderTypeAsNeeded:
+		reminder.TimesOfDay = nil
+	}
+}
+
+func nilIfEmpty(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
+
+func nilIfZero(value *int) *int {
+	if value == nil || *value == 0 {
+		return nil
+	}
+	return value
+}
+
+// isWallClockSchedule reports whether the reminder fires at a local clock
+// time, i.e. whether its next send time depends on the user's zone.
+func isWallClockSchedule(reminder *entities.Reminder) bool {
+	switch reminder.Type {
+	case entities.ReminderTypeSpecific:
+		return true
+	case entities.ReminderTypeDaily:
+		return len(reminder.TimesOfDay) > 0
+	case entities.ReminderTypeWeekly:
+		return !reminder.Weekdays.IsEmpty() && len(reminder.TimesOfDay) > 0
+	case entities.ReminderTypeRRule:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateSchedule checks that a reminder of the given type has the fields
+// its schedule is calculated from.
+func validateSchedule(reminderType entities.ReminderType, intervalHours *int, times entities.TimesOfDay, weekdays entities.Weekdays, rrule *string) error {
+	switch reminderType {
+	case entities.ReminderTypeCustom:
+		if intervalHours == nil || *intervalHours <= 0 {
+			return fmt.Errorf("interval_hours is required for custom type and must be greater than 0")
+		}
+	case entities.ReminderTypeSpecific:
+		if len(times) == 0 {
+			return fmt.Errorf("time_of_day is required for specific type")
+		}
+	case entities.ReminderTypeWeekly:
+		if weekdays.IsEmpty() {
+			return fmt.Errorf("weekdays are required for weekly type")
+		}
+		if len(times) == 0 {
+			return fmt.Errorf("time_of_day is required for weekly type")
+		}
+	case entities.ReminderTypeRRule:
+		if rrule == nil || strings.TrimSpace(*rrule) == "" {
+			return fmt.Errorf("rrule is required for rrule type")
+		}
+	}
+	return nil
+}
+
+func validateCourse(startsAt, endsAt *time.Time, maxDoses *int) error {
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	if maxDoses != nil && *maxDoses <= 0 {
+		return fmt.Errorf("max_doses must be greater than 0")
+	}
+	return nil
+}
+
+// MaxNagRepeats bounds how many times an unanswered dose is repeated.
+const MaxNagRepeats = 10
+
+func validateNag(intervalMinutes, maxRepeats *int) error {
+	if intervalMinutes == nil && maxRepeats == nil {
+		return nil
+	}
+	if intervalMinutes == nil || maxRepeats == nil {
+		return fmt.Errorf("nag interval and max repeats must be set together")
+	}
+	if *intervalMinutes <= 0 {
+		return fmt.Errorf("nag interval must be greater than 0")
+	}
+	if *maxRepeats <= 0 || *maxRepeats > MaxNagRepeats {
+		return fmt.Errorf("nag max repeats must be between 1 and %d", MaxNagRepeats)
+	}
+	return nil
+}
+
+// MaxDosesPerDayLimit bounds the daily cap of an as-needed medicine.
+const MaxDosesPerDayLimit = 24
+
+func validateDoseLimits(minIntervalMinutes, maxDosesPerDay *int) error {
+	if minIntervalMinutes != nil && (*minIntervalMinutes < 0 || *minIntervalMinutes > 24*60) {
+		return fmt.Errorf("min interval must be between 0 and 24 hours")
+	}
+	if maxDosesPerDay != nil && (*maxDosesPerDay < 0 || *maxDosesPerDay > MaxDosesPerDayLimit) {
+		return fmt.Errorf("max doses per day must be between 0 and %d", MaxDosesPerDayLimit)
+	}
+	return nil
+}
+
+// normalizeRRule validates an iCalendar recurrence set and returns it in
+// canonical form. A missing DTSTART is anchored at now, as floating time so
+// the rule keeps following the user's zone.
+func normalizeRRule(value string, now time.Time) (string, error) {
+	set, err := rrule.Parse(value, now.Location())
+	if err != nil {
+		return "", fmt.Errorf("invalid rrule: %w", err)
+	}
+	if set.DTStart.IsZero() {
+		set.DTStart = now.Truncate(time.Minute)
+		set.Floating = true
+	}
+	return set.String(), nil
+}
+
+func normalizeTimesOfDay(timesOfDay []string) (entities.TimesOfDay, error) {
+	seen := make(map[string]bool, len(timesOfDay))
+	var times entities.TimesOfDay
+	for _, raw := range timesOfDay {
+		parsed, err := time.Parse("15:04", raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time_of_day format, expected HH:MM")
+		}
+		formatted := parsed.Format("15:04")
+		if !seen[formatted] {
+			seen[formatted] = true
+			times = append(times, formatted)
+		}
+	}
+	sort.Strings(times)
+	return times, nil
+}
+
+// nextSlotAfter returns the first of the given "HH:MM" times strictly after
+// now, rolling over to the following day when today's slots have passed.
+func nextSlotAfter(times entities.TimesOfDay, now time.Time) (time.Time, bool) {
+	for dayOffset := 0; dayOffset <= 1; dayOffset++ {
+		for _, t := range times {
+			parsed, err := time.Parse("15:04", t)
+			if err != nil {
+				continue
+			}
+			next := time.Date(now.Year(), now.Month(), now.Day()+dayOffset, parsed.Hour(), parsed.Minute(), 0, 0, now.Location())
+			if next.After(now) {
+				return next, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// nextWeeklySlotAfter returns the first dose time strictly after now that
+// falls on one of the given weekdays.
+func nextWeeklySlotAfter(weekdays entities.Weekdays, times entities.TimesOfDay, now time.Time) (time.Time, bool) {
+	if weekdays.IsEmpty() {
+		return time.Time{}, false
+	}
+	for dayOffset := 0; dayOffset <= 7; dayOffset++ {
+		day := time.Date(now.Year(), now.Month(), now.Day()+dayOffset, 0, 0, 0, 0, now.Location())
+		if !weekdays.Has(day.Weekday()) {
+			continue
+		}
+		for _, t := range times {
+			parsed, err := time.Parse("15:04", t)
+			if err != nil {
+				continue
+			}
+			next := time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, now.Location())
+			if next.After(now) {
+				return next, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
 
+// CalculateNextSendTime returns the next time the reminder is due. A course
+// that has not started yet is scheduled from its start, and no time past the
+// course end is returned: the end itself is, so the scheduler can close the
+// course. For rrule reminders it is the zero time once the rule has no
+// further occurrences.
+func (u *reminderUsecase) CalculateNextSendTime(reminder *entities.Reminder, loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	if reminder.StartsAt != nil && reminder.StartsAt.After(now) {
+		if !isWallClockSchedule(reminder) {
+			return reminder.StartsAt.In(loc)
+		}
+		now = reminder.StartsAt.In(loc).Add(-time.Nanosecond)
+	}
+
+	next := u.nextSendTimeAfter(reminder, now)
+	if reminder.EndsAt != nil && (next.IsZero() || next.After(*reminder.EndsAt)) {
+		return reminder.EndsAt.In(loc)
+	}
+	return next
+}
+
+func (u *reminderUsecase) nextSendTimeAfter(reminder *entities.Reminder, now time.Time) time.Time {
 	switch reminder.Type {
 	case entities.ReminderTypeDaily:
+		if next, ok := nextSlotAfter(reminder.TimesOfDay, now); ok {
+			return next
+		}
 		next := now.Add(24 * time.Hour)
 		return time.Date(next.Year(), next.Month(), next.Day(), now.Hour(), now.Minute(), 0, 0, now.Location())
 
 	case entities.ReminderTypeWeekly:
+		if next, ok := nextWeeklySlotAfter(reminder.Weekdays, reminder.TimesOfDay, now); ok {
+			return next
+		}
 		return now.Add(7 * 24 * time.Hour)
 
 	case entities.ReminderTypeCustom:
@@ -166,20 +692,24 @@ func (u *reminderUsecase) CalculateNextSendTime(reminder *entities.Reminder) tim
 		return now.Add(24 * time.Hour)
 
 	case entities.ReminderTypeSpecific:
-		if reminder.TimeOfDay != nil {
-			parsedTime, err := time.Parse("15:04", *reminder.TimeOfDay)
-			if err == nil {
-				hour := parsedTime.Hour()
-				minute := parsedTime.Minute()
-				next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
-				if next.Before(now) || next.Equal(now) {
-					next = next.Add(24 * time.Hour)
-				}
-				return next
-			}
+		if next, ok := nextSlotAfter(reminder.TimesOfDay, now); ok {
+			return next
 		}
 		return now.Add(24 * time.Hour)
 
+	case entities.ReminderTypeAsNeeded:
+		return time.Time{}
+
+	case entities.ReminderTypeRRule:
+		if reminder.RRule == nil {
+			return time.Time{}
+		}
+		set, err := rrule.Parse(*reminder.RRule, now.Location())
+		if err != nil {
+			return time.Time{}
+		}
+		return set.After(now)
+
 	default:
 		return now.Add(24 * time.Hour)
 	}
@@ -3,6 +3,7 @@ package usecases
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,18 +23,20 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test Reminder"
 		reminderType := entities.ReminderTypeDaily
 
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
 		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, reminder *entities.Reminder) error {
 			reminder.ID = uuid.New()
 			return nil
 		})
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, nil, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reminder)
@@ -48,7 +51,8 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Medicine"
@@ -56,12 +60,13 @@ func TestReminderUsecase_Create(t *testing.T) {
 		imageURL := "https://example.com/image.jpg"
 		reminderType := entities.ReminderTypeDaily
 
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
 		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, reminder *entities.Reminder) error {
 			reminder.ID = uuid.New()
 			return nil
 		})
 
-		reminder, err := usecase.Create(ctx, userID, title, &comment, &imageURL, reminderType, nil, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Comment: &comment, ImageURL: &imageURL, Type: reminderType})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reminder)
@@ -74,12 +79,13 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		reminderType := entities.ReminderTypeDaily
 
-		reminder, err := usecase.Create(ctx, userID, "", nil, nil, reminderType, nil, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: "", Type: reminderType})
 
 		assert.Error(t, err)
 		assert.Nil(t, reminder)
@@ -91,13 +97,14 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test"
 		reminderType := entities.ReminderTypeCustom
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, nil, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType})
 
 		assert.Error(t, err)
 		assert.Nil(t, reminder)
@@ -109,14 +116,15 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test"
 		reminderType := entities.ReminderTypeCustom
 		invalidInterval := 0
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, &invalidInterval, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType, IntervalHours: &invalidInterval})
 
 		assert.Error(t, err)
 		assert.Nil(t, reminder)
@@ -128,19 +136,21 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test"
 		reminderType := entities.ReminderTypeCustom
 		intervalHours := 6
 
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
 		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, reminder *entities.Reminder) error {
 			reminder.ID = uuid.New()
 			return nil
 		})
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, &intervalHours, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType, IntervalHours: &intervalHours})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reminder)
@@ -152,13 +162,14 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test"
 		reminderType := entities.ReminderTypeSpecific
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, nil, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType})
 
 		assert.Error(t, err)
 		assert.Nil(t, reminder)
@@ -170,14 +181,15 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test"
 		reminderType := entities.ReminderTypeSpecific
 		invalidTime := "25:00"
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, nil, &invalidTime)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType, TimesOfDay: []string{invalidTime}})
 
 		assert.Error(t, err)
 		assert.Nil(t, reminder)
@@ -189,23 +201,25 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test"
 		reminderType := entities.ReminderTypeSpecific
 		timeOfDay := "09:00"
 
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
 		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, reminder *entities.Reminder) error {
 			reminder.ID = uuid.New()
 			return nil
 		})
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, nil, &timeOfDay)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType, TimesOfDay: []string{timeOfDay}})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reminder)
-		assert.Equal(t, timeOfDay, *reminder.TimeOfDay)
+		assert.Equal(t, entities.TimesOfDay{timeOfDay}, reminder.TimesOfDay)
 	})
 
 	t.Run("error when repository fails", func(t *testing.T) {
@@ -213,16 +227,18 @@ func TestReminderUsecase_Create(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		title := "Test"
 		reminderType := entities.ReminderTypeDaily
 		repoError := errors.New("repository error")
 
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
 		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(repoError)
 
-		reminder, err := usecase.Create(ctx, userID, title, nil, nil, reminderType, nil, nil)
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: title, Type: reminderType})
 
 		assert.Error(t, err)
 		assert.Nil(t, reminder)
@@ -238,7 +254,8 @@ func TestReminderUsecase_GetByID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		reminderID := uuid.New()
 		expectedReminder := &entities.Reminder{
@@ -261,7 +278,8 @@ func TestReminderUsecase_GetByID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		reminderID := uuid.New()
 		repoError := errors.New("repository error")
@@ -276,6 +294,44 @@ func TestReminderUsecase_GetByID(t *testing.T) {
 	})
 }
 
+func TestReminderUsecase_GetOwned(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		reminder *entities.Reminder
+		wantErr  error
+	}{
+		{name: "own reminder", reminder: &entities.Reminder{ID: uuid.New(), UserID: userID}},
+		{name: "missing reminder", wantErr: ErrNotFound},
+		{name: "reminder of another user", reminder: &entities.Reminder{ID: uuid.New(), UserID: uuid.New()}, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockReminderRepository(ctrl)
+			usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+			reminderID := uuid.New()
+			mockRepo.EXPECT().GetByID(ctx, reminderID).Return(tt.reminder, nil)
+
+			reminder, err := usecase.GetOwned(ctx, userID, reminderID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, reminder)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.reminder, reminder)
+		})
+	}
+}
+
 func TestReminderUsecase_GetByUserID(t *testing.T) {
 	ctx := context.Background()
 
@@ -284,7 +340,8 @@ func TestReminderUsecase_GetByUserID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		expectedReminders := []*entities.Reminder{
@@ -305,7 +362,8 @@ func TestReminderUsecase_GetByUserID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		repoError := errors.New("repository error")
@@ -327,7 +385,8 @@ func TestReminderUsecase_GetActiveByUserID(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		userID := uuid.New()
 		expectedReminders := []*entities.Reminder{
@@ -351,7 +410,8 @@ func TestReminderUsecase_Update(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		reminderID := uuid.New()
 		existingReminder := &entities.Reminder{
@@ -368,7 +428,7 @@ func TestReminderUsecase_Update(t *testing.T) {
 			return nil
 		})
 
-		reminder, err := usecase.Update(ctx, reminderID, &newTitle, nil, nil, nil, nil, nil, nil)
+		reminder, err := usecase.Update(ctx, reminderID, UpdateReminderInput{Title: &newTitle})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, reminder)
@@ -380,13 +440,14 @@ func TestReminderUsecase_Update(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		reminderID := uuid.New()
 
 		mockRepo.EXPECT().GetByID(ctx, reminderID).Return(nil, nil)
 
-		reminder, err := usecase.Update(ctx, reminderID, nil, nil, nil, nil, nil, nil, nil)
+		reminder, err := usecase.Update(ctx, reminderID, UpdateReminderInput{})
 
 		assert.Error(t, err)
 		assert.Nil(t, reminder)
@@ -398,7 +459,8 @@ func TestReminderUsecase_Update(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		reminderID := uuid.New()
 		existingReminder := &entities.Reminder{
@@ -408,19 +470,213 @@ func TestReminderUsecase_Update(t *testing.T) {
 			UserID: uuid.New(),
 		}
 		newType := entities.ReminderTypeWeekly
+		weekdays := entities.NewWeekdays(time.Monday, time.Thursday)
 
 		mockRepo.EXPECT().GetByID(ctx, reminderID).Return(existingReminder, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, existingReminder.UserID).Return(&entities.User{ID: existingReminder.UserID}, nil)
 		mockRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, reminder *entities.Reminder) error {
 			assert.Equal(t, newType, reminder.Type)
 			assert.NotNil(t, reminder.NextSendAt)
 			return nil
 		})
 
-		reminder, err := usecase.Update(ctx, reminderID, nil, nil, nil, &newType, nil, nil, nil)
+		reminder, err := usecase.Update(ctx, reminderID, UpdateReminderInput{
+			Type:       &newType,
+			Weekdays:   &weekdays,
+			TimesOfDay: []string{"09:00"},
+		})
 
 		assert.NoError(t, err)
 		assert.Equal(t, newType, reminder.Type)
 	})
+
+	t.Run("type change validates the new schedule", func(t *testing.T) {
+		weekly := entities.ReminderTypeWeekly
+		specific := entities.ReminderTypeSpecific
+		custom := entities.ReminderTypeCustom
+		rrule := entities.ReminderTypeRRule
+		monday := entities.NewWeekdays(time.Monday)
+
+		tests := []struct {
+			name    string
+			input   UpdateReminderInput
+			wantErr string
+		}{
+			{"weekly without weekdays", UpdateReminderInput{Type: &weekly, TimesOfDay: []string{"09:00"}}, "weekdays are required for weekly type"},
+			{"weekly without times", UpdateReminderInput{Type: &weekly, Weekdays: &monday}, "time_of_day is required for weekly type"},
+			{"specific without times", UpdateReminderInput{Type: &specific}, "time_of_day is required for specific type"},
+			{"custom without interval", UpdateReminderInput{Type: &custom}, "interval_hours is required for custom type"},
+			{"rrule without rule", UpdateReminderInput{Type: &rrule}, "rrule is required for rrule type"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				mockRepo := mocks.NewMockReminderRepository(ctrl)
+				usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+				existing := &entities.Reminder{
+					ID:     uuid.New(),
+					UserID: uuid.New(),
+					Type:   entities.ReminderTypeDaily,
+				}
+
+				mockRepo.EXPECT().GetByID(ctx, existing.ID).Return(existing, nil)
+
+				_, err := usecase.Update(ctx, existing.ID, tt.input)
+
+				assert.ErrorContains(t, err, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("clearing the weekdays of a weekly reminder fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+		existing := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     uuid.New(),
+			Type:       entities.ReminderTypeWeekly,
+			Weekdays:   entities.NewWeekdays(time.Monday),
+			TimesOfDay: entities.TimesOfDay{"08:00"},
+		}
+		noWeekdays := entities.Weekdays(0)
+
+		mockRepo.EXPECT().GetByID(ctx, existing.ID).Return(existing, nil)
+
+		_, err := usecase.Update(ctx, existing.ID, UpdateReminderInput{Weekdays: &noWeekdays})
+
+		assert.ErrorContains(t, err, "weekdays are required for weekly type")
+	})
+}
+
+func TestReminderUsecase_UpdateManagement(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("empty comment removes it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		comment := "после еды"
+		existing := &entities.Reminder{ID: uuid.New(), UserID: uuid.New(), Type: entities.ReminderTypeDaily, Comment: &comment}
+		empty := ""
+
+		mockRepo.EXPECT().GetByID(ctx, existing.ID).Return(existing, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, existing.ID, UpdateReminderInput{Comment: &empty})
+
+		assert.NoError(t, err)
+		assert.Nil(t, reminder.Comment)
+	})
+
+	t.Run("uploaded photo replaces image url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		url := "https://example.com/pill.png"
+		existing := &entities.Reminder{ID: uuid.New(), UserID: uuid.New(), Type: entities.ReminderTypeDaily, ImageURL: &url}
+		fileID := "AgACAgIAAxkBAAI"
+		empty := ""
+
+		mockRepo.EXPECT().GetByID(ctx, existing.ID).Return(existing, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, existing.ID, UpdateReminderInput{ImageFileID: &fileID, ImageURL: &empty})
+
+		assert.NoError(t, err)
+		assert.Nil(t, reminder.ImageURL)
+		assert.Equal(t, fileID, *reminder.ImageFileID)
+	})
+
+	t.Run("empty file id removes photo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		fileID := "AgACAgIAAxkBAAI"
+		existing := &entities.Reminder{ID: uuid.New(), UserID: uuid.New(), Type: entities.ReminderTypeDaily, ImageFileID: &fileID}
+		empty := ""
+
+		mockRepo.EXPECT().GetByID(ctx, existing.ID).Return(existing, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, existing.ID, UpdateReminderInput{ImageFileID: &empty})
+
+		assert.NoError(t, err)
+		assert.Nil(t, reminder.ImageFileID)
+	})
+
+	t.Run("type change drops unused schedule fields", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		existing := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     uuid.New(),
+			Type:       entities.ReminderTypeWeekly,
+			Weekdays:   entities.NewWeekdays(time.Monday),
+			TimesOfDay: entities.TimesOfDay{"08:00"},
+		}
+		newType := entities.ReminderTypeCustom
+		interval := 6
+
+		mockRepo.EXPECT().GetByID(ctx, existing.ID).Return(existing, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, existing.UserID).Return(&entities.User{ID: existing.UserID}, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, existing.ID, UpdateReminderInput{Type: &newType, IntervalHours: &interval})
+
+		assert.NoError(t, err)
+		assert.True(t, reminder.Weekdays.IsEmpty())
+		assert.Nil(t, reminder.TimesOfDay)
+		assert.Equal(t, 6, *reminder.IntervalHours)
+	})
+
+	t.Run("resume reschedules a stale reminder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		stale := time.Now().Add(-48 * time.Hour)
+		existing := &entities.Reminder{
+			ID:         uuid.New(),
+			UserID:     uuid.New(),
+			Type:       entities.ReminderTypeSpecific,
+			TimesOfDay: entities.TimesOfDay{"08:00"},
+			NextSendAt: &stale,
+		}
+		isActive := true
+
+		mockRepo.EXPECT().GetByID(ctx, existing.ID).Return(existing, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, existing.UserID).Return(&entities.User{ID: existing.UserID}, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, existing.ID, UpdateReminderInput{IsActive: &isActive})
+
+		assert.NoError(t, err)
+		assert.True(t, reminder.IsActive)
+		assert.True(t, reminder.NextSendAt.After(time.Now()))
+	})
 }
 
 func TestReminderUsecase_Delete(t *testing.T) {
@@ -431,7 +687,8 @@ func TestReminderUsecase_Delete(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		reminderID := uuid.New()
 
@@ -447,7 +704,8 @@ func TestReminderUsecase_Delete(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := mocks.NewMockReminderRepository(ctrl)
-		usecase := NewReminderUsecase(mockRepo)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
 
 		reminderID := uuid.New()
 		repoError := errors.New("repository error")
@@ -469,7 +727,7 @@ func TestReminderUsecase_CalculateNextSendTime(t *testing.T) {
 			Type: entities.ReminderTypeDaily,
 		}
 
-		nextTime := usecase.CalculateNextSendTime(reminder)
+		nextTime := usecase.CalculateNextSendTime(reminder, time.Local)
 		now := time.Now()
 		expected := now.Add(24 * time.Hour)
 
@@ -481,7 +739,7 @@ func TestReminderUsecase_CalculateNextSendTime(t *testing.T) {
 			Type: entities.ReminderTypeWeekly,
 		}
 
-		nextTime := usecase.CalculateNextSendTime(reminder)
+		nextTime := usecase.CalculateNextSendTime(reminder, time.Local)
 		now := time.Now()
 		expected := now.Add(7 * 24 * time.Hour)
 
@@ -495,7 +753,7 @@ func TestReminderUsecase_CalculateNextSendTime(t *testing.T) {
 			IntervalHours: &intervalHours,
 		}
 
-		nextTime := usecase.CalculateNextSendTime(reminder)
+		nextTime := usecase.CalculateNextSendTime(reminder, time.Local)
 		now := time.Now()
 		expected := now.Add(time.Duration(intervalHours) * time.Hour)
 
@@ -505,11 +763,11 @@ func TestReminderUsecase_CalculateNextSendTime(t *testing.T) {
 	t.Run("specific type - future time today", func(t *testing.T) {
 		timeOfDay := "15:00"
 		reminder := &entities.Reminder{
-			Type:      entities.ReminderTypeSpecific,
-			TimeOfDay: &timeOfDay,
+			Type:       entities.ReminderTypeSpecific,
+			TimesOfDay: entities.TimesOfDay{timeOfDay},
 		}
 
-		nextTime := usecase.CalculateNextSendTime(reminder)
+		nextTime := usecase.CalculateNextSendTime(reminder, time.Local)
 		now := time.Now()
 
 		parsedTime, _ := time.Parse("15:04", timeOfDay)
@@ -531,14 +789,780 @@ func TestReminderUsecase_CalculateNextSendTime(t *testing.T) {
 		timeOfDay := time.Date(2000, 1, 1, hour, now.Minute(), 0, 0, time.UTC).Format("15:04")
 
 		reminder := &entities.Reminder{
-			Type:      entities.ReminderTypeSpecific,
-			TimeOfDay: &timeOfDay,
+			Type:       entities.ReminderTypeSpecific,
+			TimesOfDay: entities.TimesOfDay{timeOfDay},
 		}
 
-		nextTime := usecase.CalculateNextSendTime(reminder)
+		nextTime := usecase.CalculateNextSendTime(reminder, time.Local)
 		expected := now.Add(24 * time.Hour)
 
 		assert.True(t, nextTime.After(now))
 		assert.WithinDuration(t, expected, nextTime, 2*time.Hour)
 	})
 }
+
+func TestReminderUsecase_CalculateNextSendTime_Timezone(t *testing.T) {
+	usecase := &reminderUsecase{}
+
+	t.Run("specific type uses user's wall clock", func(t *testing.T) {
+		vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+		assert.NoError(t, err)
+
+		timeOfDay := "08:30"
+		reminder := &entities.Reminder{
+			Type:       entities.ReminderTypeSpecific,
+			TimesOfDay: entities.TimesOfDay{timeOfDay},
+		}
+
+		now := time.Date(2026, 3, 10, 6, 0, 0, 0, vladivostok)
+		nextTime := usecase.nextSendTimeAfter(reminder, now)
+
+		assert.Equal(t, time.Date(2026, 3, 10, 8, 30, 0, 0, vladivostok), nextTime)
+		assert.Equal(t, time.Date(2026, 3, 9, 22, 30, 0, 0, time.UTC), nextTime.UTC())
+	})
+
+	t.Run("specific type keeps wall clock across DST change", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		assert.NoError(t, err)
+
+		timeOfDay := "08:30"
+		reminder := &entities.Reminder{
+			Type:       entities.ReminderTypeSpecific,
+			TimesOfDay: entities.TimesOfDay{timeOfDay},
+		}
+
+		now := time.Date(2026, 3, 28, 9, 0, 0, 0, berlin)
+		nextTime := usecase.nextSendTimeAfter(reminder, now)
+
+		assert.Equal(t, time.Date(2026, 3, 29, 8, 30, 0, 0, berlin), nextTime)
+	})
+}
+
+func TestReminderUsecase_RescheduleByUserID(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reschedules wall clock reminders only", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+		timezone := "Asia/Tokyo"
+		timeOfDay := "09:00"
+		intervalHours := 6
+		specific := &entities.Reminder{ID: uuid.New(), UserID: userID, Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{timeOfDay}, IsActive: true}
+		custom := &entities.Reminder{ID: uuid.New(), UserID: userID, Type: entities.ReminderTypeCustom, IntervalHours: &intervalHours, IsActive: true}
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID, Timezone: &timezone}, nil)
+		mockRepo.EXPECT().GetActiveByUserID(ctx, userID).Return([]*entities.Reminder{specific, custom}, nil)
+		mockRepo.EXPECT().UpdateNextSendAt(ctx, specific.ID, gomock.Any()).DoAndReturn(func(ctx context.Context, id uuid.UUID, nextSendAt time.Time) error {
+			tokyo, _ := time.LoadLocation(timezone)
+			local := nextSendAt.In(tokyo)
+			assert.Equal(t, 9, local.Hour())
+			assert.Equal(t, 0, local.Minute())
+			return nil
+		})
+
+		err := usecase.RescheduleByUserID(ctx, userID)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, specific.NextSendAt)
+		assert.Nil(t, custom.NextSendAt)
+	})
+
+	t.Run("error when user not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(nil, nil)
+
+		err := usecase.RescheduleByUserID(ctx, userID)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "user not found")
+	})
+}
+
+func TestReminderUsecase_MultipleTimesOfDay(t *testing.T) {
+	ctx := context.Background()
+	usecase := &reminderUsecase{}
+	times := entities.TimesOfDay{"08:00", "14:00", "21:00"}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{"before first slot", time.Date(2026, 5, 4, 6, 0, 0, 0, time.UTC), time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC)},
+		{"between slots", time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC), time.Date(2026, 5, 4, 14, 0, 0, 0, time.UTC)},
+		{"after last slot", time.Date(2026, 5, 4, 21, 30, 0, 0, time.UTC), time.Date(2026, 5, 5, 8, 0, 0, 0, time.UTC)},
+		{"month rollover", time.Date(2026, 5, 31, 22, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder := &entities.Reminder{Type: entities.ReminderTypeSpecific, TimesOfDay: times}
+			assert.Equal(t, tt.expected, usecase.nextSendTimeAfter(reminder, tt.now))
+		})
+	}
+
+	t.Run("daily type with times uses slots", func(t *testing.T) {
+		reminder := &entities.Reminder{Type: entities.ReminderTypeDaily, TimesOfDay: entities.TimesOfDay{"09:00"}}
+		now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+
+		assert.Equal(t, time.Date(2026, 5, 5, 9, 0, 0, 0, time.UTC), usecase.nextSendTimeAfter(reminder, now))
+	})
+
+	t.Run("create normalizes, sorts and deduplicates times", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: "Pills", Type: entities.ReminderTypeSpecific, TimesOfDay: []string{"21:00", "8:00", "14:00", "08:00"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, times, reminder.TimesOfDay)
+	})
+
+	t.Run("create rejects invalid time in list", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: uuid.New(), Title: "Pills", Type: entities.ReminderTypeSpecific, TimesOfDay: []string{"08:00", "14:60"}})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "invalid time_of_day format")
+	})
+}
+
+func TestReminderUsecase_ResolveSlot(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns slot of pending occurrence in user's zone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+		timezone := "Europe/Moscow"
+		nextSendAt := time.Date(2026, 5, 4, 11, 0, 0, 0, time.UTC)
+		reminder := &entities.Reminder{
+			UserID:     userID,
+			Type:       entities.ReminderTypeSpecific,
+			TimesOfDay: entities.TimesOfDay{"08:00", "14:00", "21:00"},
+			NextSendAt: &nextSendAt,
+		}
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID, Timezone: &timezone}, nil)
+
+		slot, err := usecase.ResolveSlot(ctx, reminder)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "14:00", *slot)
+	})
+
+	t.Run("returns nil for interval reminders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		intervalHours := 6
+		nextSendAt := time.Now()
+		reminder := &entities.Reminder{Type: entities.ReminderTypeCustom, IntervalHours: &intervalHours, NextSendAt: &nextSendAt}
+
+		slot, err := usecase.ResolveSlot(ctx, reminder)
+
+		assert.NoError(t, err)
+		assert.Nil(t, slot)
+	})
+}
+
+func TestReminderUsecase_WeeklySchedule(t *testing.T) {
+	ctx := context.Background()
+	usecase := &reminderUsecase{}
+	monWedFri := entities.NewWeekdays(time.Monday, time.Wednesday, time.Friday)
+
+	// 2026-05-04 is a Monday.
+	tests := []struct {
+		name     string
+		weekdays entities.Weekdays
+		times    entities.TimesOfDay
+		now      time.Time
+		expected time.Time
+	}{
+		{"later today", monWedFri, entities.TimesOfDay{"08:00"}, time.Date(2026, 5, 4, 7, 0, 0, 0, time.UTC), time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC)},
+		{"next chosen weekday", monWedFri, entities.TimesOfDay{"08:00"}, time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC), time.Date(2026, 5, 6, 8, 0, 0, 0, time.UTC)},
+		{"wraps to next week", monWedFri, entities.TimesOfDay{"08:00"}, time.Date(2026, 5, 8, 9, 0, 0, 0, time.UTC), time.Date(2026, 5, 11, 8, 0, 0, 0, time.UTC)},
+		{"second time on same day", monWedFri, entities.TimesOfDay{"08:00", "20:00"}, time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC), time.Date(2026, 5, 6, 20, 0, 0, 0, time.UTC)},
+		{"single weekday a week later", entities.NewWeekdays(time.Sunday), entities.TimesOfDay{"10:00"}, time.Date(2026, 5, 10, 10, 30, 0, 0, time.UTC), time.Date(2026, 5, 17, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder := &entities.Reminder{Type: entities.ReminderTypeWeekly, Weekdays: tt.weekdays, TimesOfDay: tt.times}
+			next := usecase.nextSendTimeAfter(reminder, tt.now)
+			assert.Equal(t, tt.expected, next)
+			assert.True(t, tt.weekdays.Has(next.Weekday()))
+		})
+	}
+
+	t.Run("is deterministic regardless of when it was last sent", func(t *testing.T) {
+		reminder := &entities.Reminder{Type: entities.ReminderTypeWeekly, Weekdays: monWedFri, TimesOfDay: entities.TimesOfDay{"08:00"}}
+		early := usecase.nextSendTimeAfter(reminder, time.Date(2026, 5, 4, 8, 0, 1, 0, time.UTC))
+		late := usecase.nextSendTimeAfter(reminder, time.Date(2026, 5, 4, 8, 3, 0, 0, time.UTC))
+		assert.Equal(t, early, late)
+	})
+
+	t.Run("error when weekly type without weekdays", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: uuid.New(), Title: "Shot", Type: entities.ReminderTypeWeekly, TimesOfDay: []string{"20:00"}})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "weekdays are required")
+	})
+
+	t.Run("error when weekly type without time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: uuid.New(), Title: "Shot", Type: entities.ReminderTypeWeekly, Weekdays: monWedFri})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "time_of_day is required")
+	})
+
+	t.Run("successful creation weekly type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: "Shot", Type: entities.ReminderTypeWeekly, Weekdays: monWedFri, TimesOfDay: []string{"20:00"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, monWedFri, reminder.Weekdays)
+		assert.True(t, monWedFri.Has(reminder.NextSendAt.Weekday()))
+		assert.Equal(t, 20, reminder.NextSendAt.Hour())
+	})
+}
+
+func TestReminderUsecase_RRuleSchedule(t *testing.T) {
+	ctx := context.Background()
+	usecase := &reminderUsecase{}
+
+	tests := []struct {
+		name     string
+		rule     string
+		now      time.Time
+		expected time.Time
+	}{
+		{"every other day", "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;INTERVAL=2", time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC), time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC)},
+		{"first monday of the month", "DTSTART:20260101T100000\nRRULE:FREQ=MONTHLY;BYDAY=1MO", time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 2, 10, 0, 0, 0, time.UTC)},
+		{"skips exdate", "DTSTART:20260101T090000\nRRULE:FREQ=DAILY\nEXDATE:20260103T090000", time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), time.Date(2026, 1, 4, 9, 0, 0, 0, time.UTC)},
+		{"exhausted rule", "DTSTART:20260101T090000\nRRULE:FREQ=DAILY;COUNT=2", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule
+			reminder := &entities.Reminder{Type: entities.ReminderTypeRRule, RRule: &rule}
+			assert.Equal(t, tt.expected, usecase.nextSendTimeAfter(reminder, tt.now))
+		})
+	}
+
+	t.Run("floating rule follows user timezone", func(t *testing.T) {
+		moscow, err := time.LoadLocation("Europe/Moscow")
+		assert.NoError(t, err)
+
+		rule := "DTSTART:20260101T090000\nRRULE:FREQ=DAILY"
+		reminder := &entities.Reminder{Type: entities.ReminderTypeRRule, RRule: &rule}
+		next := usecase.nextSendTimeAfter(reminder, time.Date(2026, 1, 1, 10, 0, 0, 0, moscow))
+		assert.Equal(t, time.Date(2026, 1, 2, 6, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("error when rrule type without rule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: uuid.New(), Title: "Pill", Type: entities.ReminderTypeRRule})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "rrule is required")
+	})
+
+	t.Run("error when rule is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+		rule := "FREQ=HOURLY"
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: "Pill", Type: entities.ReminderTypeRRule, RRule: &rule})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "invalid rrule")
+	})
+
+	t.Run("error when rule has no upcoming occurrences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+		rule := "DTSTART:20200101T090000\nRRULE:FREQ=DAILY;UNTIL=20200201T000000"
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: "Pill", Type: entities.ReminderTypeRRule, RRule: &rule})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "no upcoming occurrences")
+	})
+
+	t.Run("successful creation anchors missing DTSTART", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+		rule := "FREQ=DAILY;INTERVAL=2;BYHOUR=9;BYMINUTE=0"
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: userID, Title: "Pill", Type: entities.ReminderTypeRRule, RRule: &rule})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, reminder.RRule)
+		assert.True(t, strings.HasPrefix(*reminder.RRule, "DTSTART:"))
+		assert.Contains(t, *reminder.RRule, "RRULE:FREQ=DAILY;INTERVAL=2;BYHOUR=9;BYMINUTE=0")
+		assert.True(t, reminder.NextSendAt.After(time.Now()))
+		assert.Equal(t, 9, reminder.NextSendAt.Hour())
+	})
+}
+
+func TestReminderUsecase_Course(t *testing.T) {
+	ctx := context.Background()
+	usecase := &reminderUsecase{}
+
+	t.Run("not started course is scheduled from its start", func(t *testing.T) {
+		startsAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+		reminder := &entities.Reminder{Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{startsAt.UTC().Format("15:04")}, StartsAt: &startsAt}
+
+		next := usecase.CalculateNextSendTime(reminder, time.UTC)
+
+		assert.True(t, next.Equal(startsAt))
+	})
+
+	t.Run("not started interval course fires at its start", func(t *testing.T) {
+		startsAt := time.Now().Add(48 * time.Hour)
+		interval := 8
+		reminder := &entities.Reminder{Type: entities.ReminderTypeCustom, IntervalHours: &interval, StartsAt: &startsAt}
+
+		next := usecase.CalculateNextSendTime(reminder, time.UTC)
+
+		assert.True(t, next.Equal(startsAt))
+	})
+
+	t.Run("next time is capped at course end", func(t *testing.T) {
+		endsAt := time.Now().Add(time.Hour)
+		interval := 8
+		reminder := &entities.Reminder{Type: entities.ReminderTypeCustom, IntervalHours: &interval, EndsAt: &endsAt}
+
+		next := usecase.CalculateNextSendTime(reminder, time.UTC)
+
+		assert.True(t, next.Equal(endsAt))
+	})
+
+	t.Run("error when end is before start", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl))
+		startsAt := time.Now().AddDate(0, 0, 5)
+		endsAt := time.Now().AddDate(0, 0, 2)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: uuid.New(), Title: "Antibiotic", Type: entities.ReminderTypeDaily, StartsAt: &startsAt, EndsAt: &endsAt})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "ends_at must be after starts_at")
+	})
+
+	t.Run("error when course already ended", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl))
+		endsAt := time.Now().Add(-time.Hour)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: uuid.New(), Title: "Antibiotic", Type: entities.ReminderTypeDaily, EndsAt: &endsAt})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "ends_at must be in the future")
+	})
+
+	t.Run("error when max doses is not positive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl))
+		maxDoses := 0
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{UserID: uuid.New(), Title: "Antibiotic", Type: entities.ReminderTypeDaily, MaxDoses: &maxDoses})
+
+		assert.Error(t, err)
+		assert.Nil(t, reminder)
+		assert.Contains(t, err.Error(), "max_doses must be greater than 0")
+	})
+
+	t.Run("successful creation with course", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+		startsAt := time.Now().AddDate(0, 0, 1)
+		endsAt := startsAt.AddDate(0, 0, 7)
+		maxDoses := 14
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{
+			UserID:     userID,
+			Title:      "Antibiotic",
+			Type:       entities.ReminderTypeSpecific,
+			TimesOfDay: []string{"08:00", "20:00"},
+			StartsAt:   &startsAt,
+			EndsAt:     &endsAt,
+			MaxDoses:   &maxDoses,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &startsAt, reminder.StartsAt)
+		assert.Equal(t, &endsAt, reminder.EndsAt)
+		assert.Equal(t, &maxDoses, reminder.MaxDoses)
+		assert.False(t, reminder.NextSendAt.Before(startsAt))
+	})
+}
+
+func TestReminderUsecase_Nag(t *testing.T) {
+	ctx := context.Background()
+
+	intPtr := func(v int) *int { return &v }
+
+	errorCases := []struct {
+		name     string
+		interval *int
+		repeats  *int
+		message  string
+	}{
+		{"interval without repeats", intPtr(15), nil, "must be set together"},
+		{"repeats without interval", nil, intPtr(3), "must be set together"},
+		{"zero interval", intPtr(0), intPtr(3), "nag interval must be greater than 0"},
+		{"zero repeats", intPtr(15), intPtr(0), "nag max repeats must be between"},
+		{"too many repeats", intPtr(15), intPtr(MaxNagRepeats + 1), "nag max repeats must be between"},
+	}
+
+	for _, tt := range errorCases {
+		t.Run("error when "+tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usecase := NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl))
+
+			reminder, err := usecase.Create(ctx, CreateReminderInput{
+				UserID:             uuid.New(),
+				Title:              "Insulin",
+				Type:               entities.ReminderTypeDaily,
+				NagIntervalMinutes: tt.interval,
+				NagMaxRepeats:      tt.repeats,
+			})
+
+			assert.Error(t, err)
+			assert.Nil(t, reminder)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	t.Run("successful creation with nagging", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{
+			UserID:             userID,
+			Title:              "Insulin",
+			Type:               entities.ReminderTypeDaily,
+			NagIntervalMinutes: intPtr(15),
+			NagMaxRepeats:      intPtr(3),
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, reminder.Nags())
+		assert.Equal(t, 15, *reminder.NagIntervalMinutes)
+		assert.Equal(t, 3, *reminder.NagMaxRepeats)
+	})
+
+	t.Run("update with zero interval turns nagging off", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		reminderID := uuid.New()
+		existing := &entities.Reminder{ID: reminderID, Type: entities.ReminderTypeDaily, NagIntervalMinutes: intPtr(15), NagMaxRepeats: intPtr(3)}
+
+		mockRepo.EXPECT().GetByID(ctx, reminderID).Return(existing, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, reminderID, UpdateReminderInput{NagIntervalMinutes: intPtr(0)})
+
+		assert.NoError(t, err)
+		assert.False(t, reminder.Nags())
+	})
+}
+
+func TestReminderUsecase_AsNeeded(t *testing.T) {
+	ctx := context.Background()
+
+	intPtr := func(v int) *int { return &v }
+
+	t.Run("created without a schedule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		userID := uuid.New()
+
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Create(ctx, CreateReminderInput{
+			UserID:             userID,
+			Title:              "Ibuprofen",
+			Type:               entities.ReminderTypeAsNeeded,
+			MinIntervalMinutes: intPtr(240),
+			MaxDosesPerDay:     intPtr(0),
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, reminder.IsAsNeeded())
+		assert.Nil(t, reminder.NextSendAt)
+		assert.Empty(t, reminder.TimesOfDay)
+		assert.Equal(t, 240, *reminder.MinIntervalMinutes)
+		assert.Nil(t, reminder.MaxDosesPerDay)
+	})
+
+	errorCases := []struct {
+		name        string
+		minInterval *int
+		maxPerDay   *int
+		message     string
+	}{
+		{"negative interval", intPtr(-60), nil, "min interval must be between"},
+		{"interval over a day", intPtr(25 * 60), nil, "min interval must be between"},
+		{"too many doses per day", nil, intPtr(MaxDosesPerDayLimit + 1), "max doses per day must be between"},
+	}
+
+	for _, tt := range errorCases {
+		t.Run("error when "+tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usecase := NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl))
+
+			reminder, err := usecase.Create(ctx, CreateReminderInput{
+				UserID:             uuid.New(),
+				Title:              "Ibuprofen",
+				Type:               entities.ReminderTypeAsNeeded,
+				MinIntervalMinutes: tt.minInterval,
+				MaxDosesPerDay:     tt.maxPerDay,
+			})
+
+			assert.Error(t, err)
+			assert.Nil(t, reminder)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	t.Run("switching to a schedule drops the limits", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mockUserRepo)
+
+		reminderID := uuid.New()
+		userID := uuid.New()
+		existing := &entities.Reminder{
+			ID:                 reminderID,
+			UserID:             userID,
+			Type:               entities.ReminderTypeAsNeeded,
+			IsActive:           true,
+			MinIntervalMinutes: intPtr(240),
+			MaxDosesPerDay:     intPtr(3),
+		}
+		newType := entities.ReminderTypeSpecific
+
+		mockRepo.EXPECT().GetByID(ctx, reminderID).Return(existing, nil)
+		mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, reminderID, UpdateReminderInput{
+			Type:       &newType,
+			TimesOfDay: []string{"09:00"},
+		})
+
+		assert.NoError(t, err)
+		assert.False(t, reminder.HasDoseLimits())
+		assert.NotNil(t, reminder.NextSendAt)
+	})
+
+	t.Run("switching to as needed stops sending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewReminderUsecase(mockRepo, mocks.NewMockUserRepository(ctrl))
+
+		reminderID := uuid.New()
+		next := time.Now().Add(time.Hour)
+		existing := &entities.Reminder{
+			ID:         reminderID,
+			Type:       entities.ReminderTypeSpecific,
+			IsActive:   true,
+			TimesOfDay: entities.TimesOfDay{"09:00"},
+			NextSendAt: &next,
+		}
+		newType := entities.ReminderTypeAsNeeded
+
+		mockRepo.EXPECT().GetByID(ctx, reminderID).Return(existing, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		reminder, err := usecase.Update(ctx, reminderID, UpdateReminderInput{
+			Type:           &newType,
+			MaxDosesPerDay: intPtr(4),
+		})
+
+		assert.NoError(t, err)
+		assert.Nil(t, reminder.NextSendAt)
+		assert.Empty(t, reminder.TimesOfDay)
+		assert.Equal(t, 4, *reminder.MaxDosesPerDay)
+	})
+}
+
+func TestReminderUsecase_DosesPerDay(t *testing.T) {
+	usecase := &reminderUsecase{}
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	interval := 8
+	endsAt := now.Add(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		reminder *entities.Reminder
+		expected float64
+	}{
+		{"specific times", &entities.Reminder{Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{"08:00", "20:00"}}, 2},
+		{"every 8 hours", &entities.Reminder{Type: entities.ReminderTypeCustom, IntervalHours: &interval}, 3},
+		{"three weekdays", &entities.Reminder{Type: entities.ReminderTypeWeekly, Weekdays: entities.NewWeekdays(time.Monday, time.Wednesday, time.Friday), TimesOfDay: entities.TimesOfDay{"09:00"}}, 3.0 / 7},
+		{"course ends within window", &entities.Reminder{Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{"09:00"}, EndsAt: &endsAt}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, usecase.dosesPerDay(tt.reminder, now), 0.01)
+		})
+	}
+
+	t.Run("finished course", func(t *testing.T) {
+		past := now.Add(-time.Hour)
+		reminder := &entities.Reminder{Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{"09:00"}, EndsAt: &past}
+
+		assert.Zero(t, usecase.dosesPerDay(reminder, now))
+	})
+}
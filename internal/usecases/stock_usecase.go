@@ -0,0 +1,221 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository"
+)
+
+// MaxRefillThresholdDays caps how far ahead a refill warning may be asked
+// for.
+const MaxRefillThresholdDays = 90
+
+type StockUsecase interface {
+	Get(ctx context.Context, reminderID uuid.UUID) (*entities.Stock, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Stock, error)
+	Set(ctx context.Context, input SetStockInput) (*entities.Stock, error)
+	TopUp(ctx context.Context, reminderID uuid.UUID, amount float64) (*entities.Stock, error)
+	Remove(ctx context.Context, reminderID uuid.UUID) error
+	DaysLeft(ctx context.Context, reminder *entities.Reminder, stock *entities.Stock) (float64, bool, error)
+	GetLowStock(ctx context.Context) ([]*LowStock, error)
+	ClaimWarning(ctx context.Context, stockID uuid.UUID) (bool, error)
+}
+
+type SetStockInput struct {
+	ReminderID          uuid.UUID
+	UserID              uuid.UUID
+	OnHand              float64
+	PerDose             float64
+	RefillThresholdDays int
+}
+
+// LowStock is a tracked medication projected to run out within its refill
+// threshold.
+type LowStock struct {
+	Stock    *entities.Stock
+	Reminder *entities.Reminder
+	DaysLeft float64
+}
+
+type stockUsecase struct {
+	repo            repository.StockRepository
+	reminderUsecase ReminderUsecase
+}
+
+func NewStockUsecase(repo repository.StockRepository, reminderUsecase ReminderUsecase) StockUsecase {
+	return &stockUsecase{
+		repo:            repo,
+		reminderUsecase: reminderUsecase,
+	}
+}
+
+func (u *stockUsecase) Get(ctx context.Context, reminderID uuid.UUID) (*entities.Stock, error) {
+	stock, err := u.repo.GetByReminderID(ctx, reminderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+	return stock, nil
+}
+
+func (u *stockUsecase) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Stock, error) {
+	stocks, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocks: %w", err)
+	}
+	return stocks, nil
+}
+
+// Set starts tracking the reminder's stock or replaces the tracked values.
+func (u *stockUsecase) Set(ctx context.Context, input SetStockInput) (*entities.Stock, error) {
+	if input.OnHand < 0 {
+		return nil, fmt.Errorf("on hand amount must not be negative")
+	}
+	if input.PerDose <= 0 {
+		return nil, fmt.Errorf("amount per dose must be greater than 0")
+	}
+	if input.RefillThresholdDays < 0 || input.RefillThresholdDays > MaxRefillThresholdDays {
+		return nil, fmt.Errorf("refill threshold must be between 0 and %d days", MaxRefillThresholdDays)
+	}
+
+	reminder, err := u.reminderUsecase.GetByID(ctx, input.ReminderID)
+	if err != nil {
+		return nil, err
+	}
+	if reminder == nil || reminder.UserID != input.UserID {
+		return nil, fmt.Errorf("reminder not found")
+	}
+
+	stock, err := u.repo.GetByReminderID(ctx, input.ReminderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+
+	if stock == nil {
+		stock = &entities.Stock{
+			ReminderID:          input.ReminderID,
+			UserID:              input.UserID,
+			OnHand:              input.OnHand,
+			PerDose:             input.PerDose,
+			RefillThresholdDays: input.RefillThresholdDays,
+		}
+		if err := u.repo.Create(ctx, stock); err != nil {
+			return nil, fmt.Errorf("failed to create stock: %w", err)
+		}
+		return stock, nil
+	}
+
+	stock.OnHand = input.OnHand
+	stock.PerDose = input.PerDose
+	stock.RefillThresholdDays = input.RefillThresholdDays
+	stock.LowWarnedAt = nil
+	if err := u.repo.Update(ctx, stock); err != nil {
+		return nil, fmt.Errorf("failed to update stock: %w", err)
+	}
+	return stock, nil
+}
+
+// TopUp adds a refill to the stock and re-arms the low stock warning.
+func (u *stockUsecase) TopUp(ctx context.Context, reminderID uuid.UUID, amount float64) (*entities.Stock, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	stock, err := u.repo.GetByReminderID(ctx, reminderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+	if stock == nil {
+		return nil, fmt.Errorf("stock is not tracked for this reminder")
+	}
+
+	stock.OnHand += amount
+	stock.LowWarnedAt = nil
+	if err := u.repo.Update(ctx, stock); err != nil {
+		return nil, fmt.Errorf("failed to update stock: %w", err)
+	}
+	return stock, nil
+}
+
+func (u *stockUsecase) Remove(ctx context.Context, reminderID uuid.UUID) error {
+	stock, err := u.repo.GetByReminderID(ctx, reminderID)
+	if err != nil {
+		return fmt.Errorf("failed to get stock: %w", err)
+	}
+	if stock == nil {
+		return fmt.Errorf("stock is not tracked for this reminder")
+	}
+	if err := u.repo.Delete(ctx, stock.ID); err != nil {
+		return fmt.Errorf("failed to delete stock: %w", err)
+	}
+	return nil
+}
+
+// DaysLeft projects how many days the stock lasts on the reminder's
+// schedule. The second result is false when the schedule has no upcoming
+// doses, so the stock never runs out.
+func (u *stockUsecase) DaysLeft(ctx context.Context, reminder *entities.Reminder, stock *entities.Stock) (float64, bool, error) {
+	perDay, err := u.reminderUsecase.DosesPerDay(ctx, reminder)
+	if err != nil {
+		return 0, false, err
+	}
+	if perDay <= 0 {
+		return 0, false, nil
+	}
+	return float64(stock.DosesLeft()) / perDay, true, nil
+}
+
+// GetLowStock returns the stocks that have fallen below their refill
+// threshold and have not been warned about yet. A stock that would outlast
+// the reminder's course is never reported.
+func (u *stockUsecase) GetLowStock(ctx context.Context) ([]*LowStock, error) {
+	stocks, err := u.repo.GetUnwarned(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stocks: %w", err)
+	}
+
+	now := time.Now()
+	var low []*LowStock
+	for _, stock := range stocks {
+		if stock.RefillThresholdDays <= 0 {
+			continue
+		}
+
+		reminder, err := u.reminderUsecase.GetByID(ctx, stock.ReminderID)
+		if err != nil {
+			return nil, err
+		}
+		if reminder == nil {
+			continue
+		}
+
+		daysLeft, runsOut, err := u.DaysLeft(ctx, reminder, stock)
+		if err != nil {
+			return nil, err
+		}
+		if !runsOut || daysLeft >= float64(stock.RefillThresholdDays) {
+			continue
+		}
+		if reminder.EndsAt != nil && !now.Add(time.Duration(daysLeft*float64(24*time.Hour))).Before(*reminder.EndsAt) {
+			continue
+		}
+
+		low = append(low, &LowStock{Stock: stock, Reminder: reminder, DaysLeft: daysLeft})
+	}
+
+	return low, nil
+}
+
+// ClaimWarning marks the stock as warned about before the warning is sent.
+// It reports false when it has been warned about already.
+func (u *stockUsecase) ClaimWarning(ctx context.Context, stockID uuid.UUID) (bool, error) {
+	claimed, err := u.repo.MarkWarned(ctx, stockID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to mark stock warned: %w", err)
+	}
+	return claimed, nil
+}
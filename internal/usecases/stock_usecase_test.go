@@ -0,0 +1,163 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/entities"
+	"github.com/Helltale/take-your-pills-on-time/internal/repository/mocks"
+)
+
+func TestStockUsecase_Set(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	reminderID := uuid.New()
+
+	t.Run("starts tracking", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockStockRepository(ctrl)
+		mockReminderRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewStockUsecase(mockRepo, NewReminderUsecase(mockReminderRepo, mocks.NewMockUserRepository(ctrl)))
+
+		mockReminderRepo.EXPECT().GetByID(ctx, reminderID).Return(&entities.Reminder{ID: reminderID, UserID: userID}, nil)
+		mockRepo.EXPECT().GetByReminderID(ctx, reminderID).Return(nil, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		stock, err := usecase.Set(ctx, SetStockInput{ReminderID: reminderID, UserID: userID, OnHand: 30, PerDose: 0.5, RefillThresholdDays: 7})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 30.0, stock.OnHand)
+		assert.Equal(t, 60, stock.DosesLeft())
+	})
+
+	t.Run("replaces values and re-arms warning", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockStockRepository(ctrl)
+		mockReminderRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewStockUsecase(mockRepo, NewReminderUsecase(mockReminderRepo, mocks.NewMockUserRepository(ctrl)))
+
+		warnedAt := time.Now()
+		mockReminderRepo.EXPECT().GetByID(ctx, reminderID).Return(&entities.Reminder{ID: reminderID, UserID: userID}, nil)
+		mockRepo.EXPECT().GetByReminderID(ctx, reminderID).Return(&entities.Stock{ReminderID: reminderID, OnHand: 2, PerDose: 1, LowWarnedAt: &warnedAt}, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		stock, err := usecase.Set(ctx, SetStockInput{ReminderID: reminderID, UserID: userID, OnHand: 60, PerDose: 1, RefillThresholdDays: 5})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 60.0, stock.OnHand)
+		assert.Equal(t, 5, stock.RefillThresholdDays)
+		assert.Nil(t, stock.LowWarnedAt)
+	})
+
+	t.Run("reminder of another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockReminderRepo := mocks.NewMockReminderRepository(ctrl)
+		usecase := NewStockUsecase(mocks.NewMockStockRepository(ctrl), NewReminderUsecase(mockReminderRepo, mocks.NewMockUserRepository(ctrl)))
+
+		mockReminderRepo.EXPECT().GetByID(ctx, reminderID).Return(&entities.Reminder{ID: reminderID, UserID: uuid.New()}, nil)
+
+		_, err := usecase.Set(ctx, SetStockInput{ReminderID: reminderID, UserID: userID, OnHand: 10, PerDose: 1})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "reminder not found")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		usecase := NewStockUsecase(mocks.NewMockStockRepository(ctrl), NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl)))
+
+		for _, input := range []SetStockInput{
+			{OnHand: -1, PerDose: 1},
+			{OnHand: 10, PerDose: 0},
+			{OnHand: 10, PerDose: 1, RefillThresholdDays: MaxRefillThresholdDays + 1},
+		} {
+			_, err := usecase.Set(ctx, input)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestStockUsecase_TopUp(t *testing.T) {
+	ctx := context.Background()
+	reminderID := uuid.New()
+
+	t.Run("adds to stock", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockStockRepository(ctrl)
+		usecase := NewStockUsecase(mockRepo, NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl)))
+
+		warnedAt := time.Now()
+		mockRepo.EXPECT().GetByReminderID(ctx, reminderID).Return(&entities.Stock{ReminderID: reminderID, OnHand: 3, PerDose: 1, LowWarnedAt: &warnedAt}, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+
+		stock, err := usecase.TopUp(ctx, reminderID, 30)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 33.0, stock.OnHand)
+		assert.Nil(t, stock.LowWarnedAt)
+	})
+
+	t.Run("untracked reminder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockStockRepository(ctrl)
+		usecase := NewStockUsecase(mockRepo, NewReminderUsecase(mocks.NewMockReminderRepository(ctrl), mocks.NewMockUserRepository(ctrl)))
+
+		mockRepo.EXPECT().GetByReminderID(ctx, reminderID).Return(nil, nil)
+
+		_, err := usecase.TopUp(ctx, reminderID, 30)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "stock is not tracked")
+	})
+}
+
+func TestStockUsecase_GetLowStock(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockStockRepository(ctrl)
+	mockReminderRepo := mocks.NewMockReminderRepository(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	usecase := NewStockUsecase(mockRepo, NewReminderUsecase(mockReminderRepo, mockUserRepo))
+
+	twiceDaily := &entities.Reminder{ID: uuid.New(), UserID: userID, Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{"08:00", "20:00"}}
+	plenty := &entities.Reminder{ID: uuid.New(), UserID: userID, Type: entities.ReminderTypeSpecific, TimesOfDay: entities.TimesOfDay{"08:00"}}
+
+	low := &entities.Stock{ID: uuid.New(), ReminderID: twiceDaily.ID, OnHand: 6, PerDose: 1, RefillThresholdDays: 7}
+	enough := &entities.Stock{ID: uuid.New(), ReminderID: plenty.ID, OnHand: 60, PerDose: 1, RefillThresholdDays: 7}
+	noThreshold := &entities.Stock{ID: uuid.New(), ReminderID: uuid.New(), OnHand: 0, PerDose: 1}
+
+	mockRepo.EXPECT().GetUnwarned(ctx).Return([]*entities.Stock{low, enough, noThreshold}, nil)
+	mockReminderRepo.EXPECT().GetByID(ctx, twiceDaily.ID).Return(twiceDaily, nil)
+	mockReminderRepo.EXPECT().GetByID(ctx, plenty.ID).Return(plenty, nil)
+	mockUserRepo.EXPECT().GetByID(ctx, userID).Return(&entities.User{ID: userID}, nil).Times(2)
+
+	result, err := usecase.GetLowStock(ctx)
+
+	assert.NoError(t, err)
+	if assert.Len(t, result, 1) {
+		assert.Equal(t, low, result[0].Stock)
+		assert.Equal(t, twiceDaily, result[0].Reminder)
+		assert.InDelta(t, 3, result[0].DaysLeft, 0.1)
+	}
+}
@@ -6,12 +6,26 @@ type Usecases struct {
 	User              UserUsecase
 	Reminder          ReminderUsecase
 	ReminderExecution ReminderExecutionUsecase
+	Caregiver         CaregiverUsecase
+	Stock             StockUsecase
+	Conversation      ConversationUsecase
+	Digest            DigestUsecase
+	Delivery          DeliveryUsecase
 }
 
 func NewUsecases(repo *repository.Repository) *Usecases {
+	reminder := NewReminderUsecase(repo.Reminder, repo.User)
+	execution := NewReminderExecutionUsecase(repo.ReminderExecution, repo.Stock)
+	stock := NewStockUsecase(repo.Stock, reminder)
+
 	return &Usecases{
 		User:              NewUserUsecase(repo.User),
-		Reminder:          NewReminderUsecase(repo.Reminder),
-		ReminderExecution: NewReminderExecutionUsecase(repo.ReminderExecution),
+		Reminder:          reminder,
+		ReminderExecution: execution,
+		Caregiver:         NewCaregiverUsecase(repo.Caregiver, repo.User),
+		Stock:             stock,
+		Conversation:      NewConversationUsecase(repo.Conversation),
+		Digest:            NewDigestUsecase(repo.User, reminder, execution, stock),
+		Delivery:          NewDeliveryUsecase(repo, repo.ReminderExecution, reminder),
 	}
 }
@@ -3,8 +3,12 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/ringsaturn/tzf"
 
 	"github.com/Helltale/take-your-pills-on-time/internal/entities"
 	"github.com/Helltale/take-your-pills-on-time/internal/repository"
@@ -16,6 +20,8 @@ type UserUsecase interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
 	Deactivate(ctx context.Context, telegramID int64) error
 	Activate(ctx context.Context, telegramID int64) error
+	SetTimezone(ctx context.Context, telegramID int64, timezone string) (*entities.User, error)
+	SetTimezoneByCoordinates(ctx context.Context, telegramID int64, latitude, longitude float64) (*entities.User, error)
 }
 
 type userUsecase struct {
@@ -101,3 +107,61 @@ func (u *userUsecase) Activate(ctx context.Context, telegramID int64) error {
 	}
 	return nil
 }
+
+func (u *userUsecase) SetTimezone(ctx context.Context, telegramID int64, timezone string) (*entities.User, error) {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" || strings.EqualFold(timezone, "local") {
+		return nil, fmt.Errorf("timezone is required")
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", timezone)
+	}
+
+	user, err := u.repo.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	name := loc.String()
+	user.Timezone = &name
+
+	if err := u.repo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// SetTimezoneByCoordinates sets the IANA zone whose boundaries contain the
+// location, with its daylight saving rules.
+func (u *userUsecase) SetTimezoneByCoordinates(ctx context.Context, telegramID int64, latitude, longitude float64) (*entities.User, error) {
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("invalid coordinates")
+	}
+	timezone, err := timezoneAt(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+	return u.SetTimezone(ctx, telegramID, timezone)
+}
+
+// timezoneFinder looks zones up in the timezone-boundary-builder polygons.
+// They take a while to load, so this happens on first use.
+var timezoneFinder = sync.OnceValues(tzf.NewDefaultFinder)
+
+func timezoneAt(latitude, longitude float64) (string, error) {
+	finder, err := timezoneFinder()
+	if err != nil {
+		return "", fmt.Errorf("failed to load timezone boundaries: %w", err)
+	}
+	timezone := finder.GetTimezoneName(longitude, latitude)
+	if timezone == "" {
+		return "", fmt.Errorf("no timezone found at %.4f, %.4f", latitude, longitude)
+	}
+	return timezone, nil
+}
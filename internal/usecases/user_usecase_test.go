@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/Helltale/take-your-pills-on-time/internal/entities"
@@ -212,3 +214,123 @@ func TestUserUsecase_Activate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestUserUsecase_SetTimezone(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewUserUsecase(mockRepo)
+
+		telegramID := int64(12345)
+		existingUser := &entities.User{ID: uuid.New(), TelegramID: telegramID, FirstName: "Test"}
+
+		mockRepo.EXPECT().GetByTelegramID(ctx, telegramID).Return(existingUser, nil)
+		mockRepo.EXPECT().Update(ctx, existingUser).Return(nil)
+
+		user, err := usecase.SetTimezone(ctx, telegramID, " Asia/Novosibirsk ")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Novosibirsk", *user.Timezone)
+		assert.Equal(t, "Asia/Novosibirsk", user.Location().String())
+	})
+
+	t.Run("error when timezone is unknown", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewUserUsecase(mockRepo)
+
+		user, err := usecase.SetTimezone(ctx, 12345, "Mars/Olympus")
+
+		assert.Error(t, err)
+		assert.Nil(t, user)
+		assert.Contains(t, err.Error(), "unknown timezone")
+	})
+
+	t.Run("error when timezone is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewUserUsecase(mockRepo)
+
+		user, err := usecase.SetTimezone(ctx, 12345, "")
+
+		assert.Error(t, err)
+		assert.Nil(t, user)
+	})
+}
+
+func TestUserUsecase_SetTimezoneByCoordinates(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                string
+		latitude, longitude float64
+		want                string
+	}{
+		{"Moscow", 55.75, 37.62, "Europe/Moscow"},
+		{"Paris", 48.86, 2.35, "Europe/Paris"},
+		{"Madrid", 40.42, -3.70, "Europe/Madrid"},
+		{"Delhi", 28.61, 77.21, "Asia/Kolkata"},
+		{"New York", 40.71, -74.01, "America/New_York"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockUserRepository(ctrl)
+			usecase := NewUserUsecase(mockRepo)
+
+			telegramID := int64(12345)
+			existingUser := &entities.User{ID: uuid.New(), TelegramID: telegramID, FirstName: "Test"}
+
+			mockRepo.EXPECT().GetByTelegramID(ctx, telegramID).Return(existingUser, nil)
+			mockRepo.EXPECT().Update(ctx, existingUser).Return(nil)
+
+			user, err := usecase.SetTimezoneByCoordinates(ctx, telegramID, tt.latitude, tt.longitude)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, *user.Timezone)
+		})
+	}
+
+	t.Run("keeps daylight saving", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewUserUsecase(mockRepo)
+
+		existingUser := &entities.User{ID: uuid.New(), TelegramID: 12345, FirstName: "Test"}
+		mockRepo.EXPECT().GetByTelegramID(ctx, int64(12345)).Return(existingUser, nil)
+		mockRepo.EXPECT().Update(ctx, existingUser).Return(nil)
+
+		user, err := usecase.SetTimezoneByCoordinates(ctx, 12345, 48.86, 2.35)
+		require.NoError(t, err)
+
+		_, winter := time.Date(2026, 1, 15, 12, 0, 0, 0, user.Location()).Zone()
+		_, summer := time.Date(2026, 7, 15, 12, 0, 0, 0, user.Location()).Zone()
+		assert.Equal(t, 3600, winter)
+		assert.Equal(t, 2*3600, summer)
+	})
+
+	t.Run("error when coordinates are invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepository(ctrl)
+		usecase := NewUserUsecase(mockRepo)
+
+		user, err := usecase.SetTimezoneByCoordinates(ctx, 12345, 91, 0)
+
+		assert.Error(t, err)
+		assert.Nil(t, user)
+	})
+}
@@ -0,0 +1,148 @@
+// Package webhook receives Telegram updates over HTTP, as an alternative to
+// long polling. TLS is expected to be terminated by a reverse proxy.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/config"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// allowedUpdates are the update types the bot handles; Telegram does not
+// send the others.
+var allowedUpdates = []string{"message", "callback_query"}
+
+type Server struct {
+	bot     *tgbotapi.BotAPI
+	cfg     config.WebhookConfig
+	logger  *zap.Logger
+	updates chan tgbotapi.Update
+	done    chan struct{}
+	server  *http.Server
+}
+
+func NewServer(bot *tgbotapi.BotAPI, cfg config.WebhookConfig, logger *zap.Logger) *Server {
+	s := &Server{
+		bot:     bot,
+		cfg:     cfg,
+		logger:  logger,
+		updates: make(chan tgbotapi.Update, bot.Buffer),
+		done:    make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path(), s)
+	s.server = &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// Updates returns the channel received updates are delivered to.
+func (s *Server) Updates() tgbotapi.UpdatesChannel {
+	return s.updates
+}
+
+// Start listens on the configured address and then registers the webhook
+// with Telegram, so no update arrives before the server can take it.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.cfg.ListenAddr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("webhook server failed", zap.Error(err))
+		}
+	}()
+
+	if err := s.register(); err != nil {
+		s.server.Close()
+		return err
+	}
+
+	s.logger.Info("Webhook registered",
+		zap.String("listen_addr", s.cfg.ListenAddr),
+		zap.String("path", s.cfg.Path()),
+	)
+	return nil
+}
+
+// register calls setWebhook directly: the library's WebhookConfig has no
+// secret_token.
+func (s *Server) register() error {
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("url", s.cfg.URL)
+	params.AddNonEmpty("secret_token", s.cfg.SecretToken)
+	if err := params.AddInterface("allowed_updates", allowedUpdates); err != nil {
+		return fmt.Errorf("failed to encode allowed updates: %w", err)
+	}
+
+	if _, err := s.bot.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
+
+// Shutdown removes the webhook so that Telegram keeps new updates until the
+// bot is back and waits for requests in progress. Requests still waiting
+// for room in Updates are refused, Telegram delivers them again later.
+func (s *Server) Shutdown(ctx context.Context) error {
+	close(s.done)
+
+	var errs []error
+	if _, err := s.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete webhook: %w", err))
+	}
+	if err := s.server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to stop webhook server: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.Header.Get(secretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.SecretToken)) != 1 {
+		s.logger.Warn("rejected webhook request with invalid secret token", zap.String("remote_addr", r.RemoteAddr))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Answering only once the update is queued makes Telegram retry it if
+	// the bot is too busy or shutting down.
+	select {
+	case s.updates <- update:
+		w.WriteHeader(http.StatusOK)
+	case <-s.done:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case <-r.Context().Done():
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
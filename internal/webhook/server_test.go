@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/Helltale/take-your-pills-on-time/internal/config"
+)
+
+func newTestServer() *Server {
+	bot := &tgbotapi.BotAPI{Buffer: 1}
+	return NewServer(bot, config.WebhookConfig{
+		Enabled:     true,
+		URL:         "https://example.com/telegram/webhook",
+		ListenAddr:  ":0",
+		SecretToken: "s3cret-token",
+	}, zap.NewNop())
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	const body = `{"update_id": 42, "message": {"message_id": 1, "chat": {"id": 7}, "text": "/start"}}`
+
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		body     string
+		expected int
+	}{
+		{"valid update", http.MethodPost, "s3cret-token", body, http.StatusOK},
+		{"missing secret token", http.MethodPost, "", body, http.StatusUnauthorized},
+		{"wrong secret token", http.MethodPost, "s3cret-tokem", body, http.StatusUnauthorized},
+		{"malformed body", http.MethodPost, "s3cret-token", "{", http.StatusBadRequest},
+		{"not a post", http.MethodGet, "s3cret-token", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+
+			req := httptest.NewRequest(tt.method, "/telegram/webhook", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set(secretTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+
+			s.server.Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusOK {
+				update := <-s.Updates()
+				assert.Equal(t, 42, update.UpdateID)
+				assert.Equal(t, "/start", update.Message.Text)
+			} else {
+				assert.Empty(t, s.Updates())
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP_OtherPath(t *testing.T) {
+	s := newTestServer()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	req.Header.Set(secretTokenHeader, "s3cret-token")
+	rec := httptest.NewRecorder()
+
+	s.server.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}